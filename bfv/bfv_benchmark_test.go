@@ -214,6 +214,12 @@ func benchEvaluator(testctx *testContext, b *testing.B) {
 		}
 	})
 
+	b.Run(testString("Evaluator/SquareMethod", testctx.params), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			evaluator.Square(ciphertext1, receiver)
+		}
+	})
+
 	b.Run(testString("Evaluator/Relin", testctx.params), func(b *testing.B) {
 
 		if testctx.params.PCount() == 0 {
@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
 	"runtime"
 	"testing"
 
@@ -67,7 +70,10 @@ func TestBFV(t *testing.T) {
 
 		for _, testSet := range []func(testctx *testContext, t *testing.T){
 			testParameters,
+			testModuliForDepth,
 			testEncoder,
+			testEncryptor,
+			testDecryptor,
 			testEvaluator,
 			testEvaluatorKeySwitch,
 			testEvaluatorRotate,
@@ -119,6 +125,65 @@ func testParameters(testctx *testContext, t *testing.T) {
 		}
 	})
 
+	t.Run("Parameters/FilterParamsBySecurity", func(t *testing.T) {
+		params := FilterParamsBySecurity(128)
+		require.Len(t, params, len(DefaultParams)+len(DefaultPostQuantumParams))
+		for _, p := range params {
+			require.Equal(t, 128, p.SecurityLevel())
+		}
+		require.Empty(t, FilterParamsBySecurity(192))
+	})
+
+	t.Run(testString("Parameters/SupportsBatching", testctx.params), func(t *testing.T) {
+		params := testctx.params
+		require.True(t, params.SupportsBatching())
+		require.False(t, Parameters{}.SupportsBatching())
+
+		N := params.N()
+		require.True(t, supportsBatching(N, 65537))
+		require.True(t, supportsBatching(N, 0x3ee0001))
+		require.False(t, supportsBatching(N, 65539))                           // prime, but not 1 mod 2N
+		require.False(t, supportsBatching(N, (2*uint64(N)+1)*(2*uint64(N)+1))) // 1 mod 2N, but not prime
+		require.False(t, supportsBatching(N, 1))
+		require.False(t, supportsBatching(N, 2))
+	})
+
+	t.Run(testString("Parameters/Delta", testctx.params), func(t *testing.T) {
+		params := testctx.params
+		Q, T := params.RingQ().ModulusBigint, new(big.Int).SetUint64(params.T())
+
+		// Delta*T <= Q < (Delta+1)*T
+		deltaT := new(big.Int).Mul(params.Delta(), T)
+		require.True(t, deltaT.Cmp(Q) <= 0)
+		require.True(t, deltaT.Add(deltaT, T).Cmp(Q) > 0)
+
+		for i, qi := range params.RingQ().Modulus {
+			want := new(big.Int).Mod(params.Delta(), new(big.Int).SetUint64(qi)).Uint64()
+			require.Equal(t, want, ring.InvMForm(params.DeltaModQi(i), qi, params.RingQ().MredParams[i]))
+		}
+
+		require.Panics(t, func() { params.DeltaModQi(params.QCount()) })
+
+		var paramsUnmarshalled Parameters
+		data, err := params.MarshalBinary()
+		require.NoError(t, err)
+		require.NoError(t, paramsUnmarshalled.UnmarshalBinary(data))
+		require.Equal(t, params.Delta(), paramsUnmarshalled.Delta())
+	})
+
+	t.Run(testString("Parameters/DecryptionFailureProb", testctx.params), func(t *testing.T) {
+		require.Less(t, testctx.params.DecryptionFailureProb(0), math.Exp2(-40))
+		prev := 0.0
+		for depth := 0; depth < 32; depth++ {
+			prob := testctx.params.DecryptionFailureProb(depth)
+			require.GreaterOrEqual(t, prob, prev)
+			require.LessOrEqual(t, prob, 1.0)
+			prev = prob
+		}
+		require.Equal(t, 1.0, prev)
+		require.Panics(t, func() { testctx.params.DecryptionFailureProb(-1) })
+	})
+
 	t.Run(testString("Parameters/CopyNew", testctx.params), func(t *testing.T) {
 		params1, params2 := testctx.params.CopyNew(), testctx.params.CopyNew()
 		assert.True(t, params1.Equals(testctx.params) && params2.Equals(testctx.params))
@@ -126,6 +191,96 @@ func testParameters(testctx *testContext, t *testing.T) {
 		assert.False(t, params1.Equals(testctx.params))
 		assert.True(t, params2.Equals(testctx.params))
 	})
+
+	t.Run(testString("Parameters/CompatiblePrefix", testctx.params), func(t *testing.T) {
+		prefix, ok := testctx.params.CompatiblePrefix(testctx.params)
+		assert.True(t, ok)
+		assert.Equal(t, testctx.params.QCount(), prefix)
+
+		if testctx.params.QCount() > 1 {
+			paramsLit := ParametersLiteral{
+				LogN: testctx.params.LogN(),
+				Q:    testctx.params.Q()[:testctx.params.QCount()-1],
+				P:    testctx.params.P(),
+				T:    testctx.params.T(),
+			}
+			params, err := NewParametersFromLiteral(paramsLit)
+			require.NoError(t, err)
+			prefix, ok = testctx.params.CompatiblePrefix(params)
+			assert.True(t, ok)
+			assert.Equal(t, testctx.params.QCount()-1, prefix)
+		}
+
+		params, err := NewParameters(testctx.params.Parameters, 786433)
+		require.NoError(t, err)
+		prefix, ok = testctx.params.CompatiblePrefix(params)
+		assert.False(t, ok)
+		assert.Equal(t, testctx.params.QCount(), prefix)
+	})
+
+	t.Run(testString("Parameters/SlotCount", testctx.params), func(t *testing.T) {
+		assert.Equal(t, bits.Len64(testctx.params.T()-1), testctx.params.PlaintextBits())
+		assert.Equal(t, testctx.params.N(), testctx.params.SlotCount())
+
+		N := testctx.params.N()
+		for _, tc := range []struct {
+			t         uint64
+			slotCount int
+		}{
+			{2, 1},
+			{256, 1},
+			{3, 2},
+			{uint64(2*N - 1), N / 2},
+			{uint64(N + 1), N / 2},
+			{uint64(2*N + 1), N},
+		} {
+			assert.Equal(t, tc.slotCount, slotCount(N, tc.t), "T=%d", tc.t)
+		}
+	})
+}
+
+func testModuliForDepth(testctx *testContext, t *testing.T) {
+
+	t.Run(testString("Parameters/ModuliForDepth", testctx.params), func(t *testing.T) {
+
+		logN := testctx.params.LogN()
+
+		_, _, err := ModuliForDepth(testctx.params.N(), testctx.params.T(), 64)
+		require.Error(t, err)
+
+		_, _, err = ModuliForDepth(testctx.params.N()+1, testctx.params.T(), 1)
+		require.Error(t, err)
+
+		depth := 1
+		if logN > 12 {
+			depth = logN - 11
+		}
+
+		Q, P, err := ModuliForDepth(testctx.params.N(), testctx.params.T(), depth)
+		require.NoError(t, err)
+
+		params, err := NewParametersFromLiteral(ParametersLiteral{LogN: logN, Q: Q, P: P, T: testctx.params.T()})
+		require.NoError(t, err)
+
+		kgen := NewKeyGenerator(params)
+		sk := kgen.GenSecretKey()
+		encoder := NewEncoder(params)
+		encryptor := NewEncryptor(params, sk)
+		decryptor := NewDecryptor(params, sk)
+		evaluator := NewEvaluator(params, rlwe.EvaluationKey{Rlk: kgen.GenRelinearizationKey(sk, 1)})
+
+		values := testctx.uSampler.ReadNew()
+		plaintext := NewPlaintext(params)
+		encoder.EncodeUint(values.Coeffs[0], plaintext)
+		ciphertext := encryptor.EncryptNew(plaintext)
+
+		for i := 0; i < depth; i++ {
+			evaluator.Relinearize(evaluator.MulNew(ciphertext, ciphertext), ciphertext)
+			testctx.ringT.MulCoeffs(values, values, values)
+		}
+
+		require.True(t, utils.EqualSliceUint64(values.Coeffs[0], encoder.DecodeUintNew(decryptor.DecryptNew(ciphertext))))
+	})
 }
 
 func newTestVectorsRingQ(testctx *testContext, encryptor Encryptor, t *testing.T) (coeffs *ring.Poly, plaintext *Plaintext, ciphertext *Ciphertext) {
@@ -187,6 +342,21 @@ func testEncoder(testctx *testContext, t *testing.T) {
 		verifyTestVectors(testctx, nil, values, plaintext, t)
 	})
 
+	t.Run(testString("Encoder/ScaleUp", testctx.params), func(t *testing.T) {
+		_, ptRt := newTestVectorsRingT(testctx, t)
+		pt := NewPlaintext(testctx.params)
+		testctx.encoder.ScaleUp(ptRt, pt)
+
+		// ScaleUp multiplies the coefficients by Delta = floor(Q/T)
+		bigCoeffs := make([]*big.Int, testctx.params.N())
+		for i, m := range ptRt.Value.Coeffs[0] {
+			bigCoeffs[i] = new(big.Int).Mul(new(big.Int).SetUint64(m), testctx.params.Delta())
+		}
+		want := testctx.ringQ.NewPoly()
+		testctx.ringQ.SetCoefficientsBigint(bigCoeffs, want)
+		require.True(t, testctx.ringQ.Equal(want, pt.Value))
+	})
+
 	t.Run(testString("Encoder/Encode&Decode/RingT/Int", testctx.params), func(t *testing.T) {
 
 		T := testctx.params.T()
@@ -238,6 +408,230 @@ func testEncoder(testctx *testContext, t *testing.T) {
 		values, plaintext := newTestVectorsMul(testctx, t)
 		verifyTestVectors(testctx, nil, values, plaintext, t)
 	})
+
+	t.Run(testString("Encoder/EncodeSigned&DecodeSigned", testctx.params), func(t *testing.T) {
+
+		T := testctx.params.T()
+		max, min := int64(T>>1), -int64((T-1)>>1)
+
+		coeffs := testctx.uSampler.ReadNew()
+		values := make([]int64, testctx.params.N())
+		for i, c := range coeffs.Coeffs[0] {
+			values[i] = int64(c%T) + min
+		}
+		values[0], values[1], values[2], values[3] = max, min, 0, -1
+
+		plaintext := NewPlaintext(testctx.params)
+		testctx.encoder.EncodeSigned(values, plaintext)
+		require.Equal(t, values, testctx.encoder.DecodeSigned(plaintext))
+
+		ciphertext := testctx.encryptorPk.EncryptNew(plaintext)
+		require.Equal(t, values, testctx.encoder.DecodeSigned(testctx.decryptor.DecryptNew(ciphertext)))
+
+		// out of range values panic without modifying the plaintext
+		for _, v := range []int64{max + 1, min - 1} {
+			require.Panics(t, func() { testctx.encoder.EncodeSigned([]int64{0, v}, plaintext) })
+			require.Equal(t, values, testctx.encoder.DecodeSigned(plaintext))
+		}
+	})
+
+	t.Run(testString("Encoder/EncodeFixedPoint&DecodeFixedPoint", testctx.params), func(t *testing.T) {
+
+		T := testctx.params.T()
+		fracBits := 4
+		bound := math.Ldexp(float64((T-1)>>1), -fracBits)
+
+		coeffs := testctx.uSampler.ReadNew()
+		values := make([]float64, testctx.params.N())
+		for i, c := range coeffs.Coeffs[0] {
+			values[i] = (float64(c%T)/float64(T) - 0.5) * bound
+		}
+		values[0], values[1], values[2] = bound, -bound, 0
+
+		plaintext := NewPlaintext(testctx.params)
+		testctx.encoder.EncodeFixedPoint(values, fracBits, plaintext)
+
+		ciphertext := testctx.encryptorPk.EncryptNew(plaintext)
+		have := testctx.encoder.DecodeFixedPoint(testctx.decryptor.DecryptNew(ciphertext), fracBits)
+
+		for i := range values {
+			require.InDelta(t, values[i], have[i], math.Ldexp(0.5, -fracBits))
+		}
+
+		// values that would wrap around modulo T panic without modifying the plaintext
+		for _, v := range []float64{2 * bound, -2 * bound, math.NaN(), math.Inf(1)} {
+			require.Panics(t, func() { testctx.encoder.EncodeFixedPoint([]float64{0, v}, fracBits, plaintext) })
+		}
+		require.Panics(t, func() { testctx.encoder.EncodeFixedPoint(values, -1, plaintext) })
+		require.Equal(t, have, testctx.encoder.DecodeFixedPoint(plaintext, fracBits))
+	})
+
+	t.Run(testString("Encoder/ReduceModT", testctx.params), func(t *testing.T) {
+
+		T := testctx.params.T()
+		coeffs := testctx.uSampler.ReadNew()
+
+		// out of range inputs are reduced modulo T by the encoding
+		valuesUint := make([]uint64, testctx.params.N())
+		valuesInt := make([]int64, testctx.params.N())
+		for i, k := range coeffs.Coeffs[0] {
+			valuesUint[i] = k + T*uint64(i%7+1)
+			valuesInt[i] = int64(k) - int64(T)*int64(i%7+1)
+		}
+		valuesUint[0], valuesInt[0] = math.MaxUint64, math.MinInt64
+
+		plaintext := NewPlaintext(testctx.params)
+
+		// -2^63 mod T
+		coeffs.Coeffs[0][0] = (T - (uint64(1)<<63)%T) % T
+		testctx.encoder.EncodeInt(valuesInt, plaintext)
+		verifyTestVectors(testctx, testctx.decryptor, coeffs, testctx.encryptorPk.EncryptNew(plaintext), t)
+
+		coeffs.Coeffs[0][0] = math.MaxUint64 % T
+		testctx.encoder.EncodeUint(valuesUint, plaintext)
+		verifyTestVectors(testctx, testctx.decryptor, coeffs, testctx.encryptorPk.EncryptNew(plaintext), t)
+
+		// the plaintexts returned by the encoding are already reduced
+		want := plaintext.Value.CopyNew()
+		testctx.encoder.ReduceModT(plaintext)
+		require.True(t, testctx.ringQ.Equal(want, plaintext.Value))
+
+		// floor(Q/T)*(T+m) is reduced to floor(Q/T)*m
+		ptRt := NewPlaintextRingT(testctx.params)
+		testctx.encoder.EncodeUintRingT(coeffs.Coeffs[0], ptRt)
+		bigCoeffs := make([]*big.Int, testctx.params.N())
+		for i, m := range ptRt.Value.Coeffs[0] {
+			bigCoeffs[i] = new(big.Int).SetUint64(m + T)
+			bigCoeffs[i].Mul(bigCoeffs[i], testctx.params.Delta())
+		}
+		testctx.ringQ.SetCoefficientsBigint(bigCoeffs, plaintext.Value)
+		require.False(t, testctx.ringQ.Equal(want, plaintext.Value))
+
+		testctx.encoder.ReduceModT(plaintext)
+		require.True(t, testctx.ringQ.Equal(want, plaintext.Value))
+	})
+
+	t.Run(testString("Scheme/BGV", testctx.params), func(t *testing.T) {
+
+		params := testctx.params
+		pl := ParametersLiteral{LogN: params.LogN(), Q: params.Q(), P: params.P(), H: params.HammingWeight(), Sigma: params.Sigma(), T: params.T(), Scheme: SchemeBGV}
+
+		paramsBGV, err := NewParametersFromLiteral(pl)
+		require.NoError(t, err)
+		require.Equal(t, SchemeBGV, paramsBGV.Scheme())
+		require.Equal(t, SchemeBFV, params.Scheme())
+		require.False(t, params.Equals(paramsBGV))
+
+		kgen := NewKeyGenerator(paramsBGV)
+		sk, pk := kgen.GenKeyPair()
+		encoder := NewEncoder(paramsBGV)
+		decryptor := NewDecryptor(paramsBGV, sk)
+
+		values := testctx.uSampler.ReadNew()
+		plaintext := NewPlaintext(paramsBGV)
+		encoder.EncodeUint(values.Coeffs[0], plaintext)
+
+		// the message is embedded in R_q without scaling
+		ptRt := NewPlaintextRingT(paramsBGV)
+		encoder.EncodeUintRingT(values.Coeffs[0], ptRt)
+		for i := range plaintext.Value.Coeffs {
+			require.Equal(t, ptRt.Value.Coeffs[0], plaintext.Value.Coeffs[i])
+		}
+		require.Equal(t, values.Coeffs[0], encoder.DecodeUintNew(plaintext))
+
+		for _, key := range []interface{}{sk, pk} {
+			ciphertext := NewEncryptor(paramsBGV, key).EncryptNew(plaintext)
+			require.Equal(t, values.Coeffs[0], encoder.DecodeUintNew(decryptor.DecryptNew(ciphertext)))
+		}
+
+		data, err := paramsBGV.MarshalBinary()
+		require.NoError(t, err)
+		var paramsRec Parameters
+		require.NoError(t, paramsRec.UnmarshalBinary(data))
+		require.True(t, paramsBGV.Equals(paramsRec))
+
+		data, err = json.Marshal(paramsBGV)
+		require.NoError(t, err)
+		paramsRec = Parameters{}
+		require.NoError(t, json.Unmarshal(data, &paramsRec))
+		require.Equal(t, SchemeBGV, paramsRec.Scheme())
+
+		require.Panics(t, func() { NewEvaluator(paramsBGV, rlwe.EvaluationKey{}) })
+		require.Panics(t, func() {
+			NewEncryptor(paramsBGV, sk).EncryptFromCRPNew(plaintext, paramsBGV.RingQ().NewPoly())
+		})
+
+		pl.Scheme = Scheme(2)
+		_, err = NewParametersFromLiteral(pl)
+		require.Error(t, err)
+
+		// t must be smaller than all the moduli of Q
+		pl.Scheme = SchemeBGV
+		pl.Q = append([]uint64{params.Q()[0]}, params.T())
+		_, err = NewParametersFromLiteral(pl)
+		require.Error(t, err)
+	})
+}
+
+func testEncryptor(testctx *testContext, t *testing.T) {
+
+	t.Run(testString("Encryptor/EncryptSlice", testctx.params), func(t *testing.T) {
+
+		values := make([]*ring.Poly, 3)
+		plaintexts := make([]*Plaintext, 3)
+		ciphertexts := make([]*Ciphertext, 3)
+		for i := range plaintexts {
+			values[i], plaintexts[i], _ = newTestVectorsRingQ(testctx, nil, t)
+			ciphertexts[i] = NewCiphertext(testctx.params, 1)
+		}
+
+		require.NoError(t, testctx.encryptorPk.EncryptSlice(plaintexts, ciphertexts))
+
+		for i := range ciphertexts {
+			verifyTestVectors(testctx, testctx.decryptor, values[i], ciphertexts[i], t)
+		}
+
+		// invalid inputs must return an error before any ciphertext is modified
+		fresh := []*Ciphertext{NewCiphertext(testctx.params, 1), NewCiphertext(testctx.params, 1), NewCiphertext(testctx.params, 1)}
+
+		invalid := map[string][]*Ciphertext{
+			"length":    fresh[:2],
+			"nil":       {fresh[0], fresh[1], nil},
+			"degree":    {fresh[0], fresh[1], NewCiphertext(testctx.params, 2)},
+			"duplicate": {fresh[0], fresh[1], fresh[0]},
+		}
+
+		if testctx.params.MaxLevel() > 0 {
+			invalid["level"] = []*Ciphertext{fresh[0], fresh[1], {Ciphertext: rlwe.NewCiphertext(testctx.params.Parameters, 1, testctx.params.MaxLevel()-1)}}
+		}
+
+		for name, cts := range invalid {
+			require.Error(t, testctx.encryptorSk.EncryptSlice(plaintexts, cts), name)
+			for _, ct := range fresh {
+				for _, pol := range ct.Value {
+					require.True(t, testctx.ringQ.Equal(pol, testctx.ringQ.NewPoly()), name)
+				}
+			}
+		}
+	})
+}
+
+func testDecryptor(testctx *testContext, t *testing.T) {
+
+	t.Run(testString("Decryptor/DecryptChecked", testctx.params), func(t *testing.T) {
+
+		values, _, ciphertext := newTestVectorsRingQ(testctx, testctx.encryptorPk, t)
+
+		plaintext := NewPlaintext(testctx.params)
+		require.NoError(t, testctx.decryptor.DecryptChecked(ciphertext, plaintext))
+		require.Equal(t, values.Coeffs[0], testctx.encoder.DecodeUintNew(plaintext))
+
+		// a uniform noise overflows the decryption bound
+		prng, _ := utils.NewPRNG()
+		noise := ring.NewUniformSampler(prng, testctx.ringQ).ReadNew()
+		testctx.ringQ.Add(ciphertext.Value[0], noise, ciphertext.Value[0])
+		require.Error(t, testctx.decryptor.DecryptChecked(ciphertext, plaintext))
+	})
 }
 
 func testEvaluator(testctx *testContext, t *testing.T) {
@@ -319,6 +713,111 @@ func testEvaluator(testctx *testContext, t *testing.T) {
 		verifyTestVectors(testctx, testctx.decryptor, values2, ciphertext2, t)
 	})
 
+	t.Run(testString("Evaluator/AddPlain", testctx.params), func(t *testing.T) {
+
+		values1, _, ciphertext1 := newTestVectorsRingQ(testctx, testctx.encryptorPk, t)
+		values2, plaintext2, _ := newTestVectorsRingQ(testctx, nil, t)
+		ciphertextOut := NewCiphertext(testctx.params, 1)
+
+		testctx.evaluator.AddPlain(ciphertext1, plaintext2, ciphertextOut)
+		testctx.ringT.Add(values1, values2, values2)
+
+		verifyTestVectors(testctx, testctx.decryptor, values2, ciphertextOut, t)
+
+		// Plaintext in the NTT domain
+		plaintextNTT := NewPlaintext(testctx.params)
+		testctx.ringQ.NTT(plaintext2.Value, plaintextNTT.Value)
+		plaintextNTT.Value.IsNTT = true
+
+		testctx.evaluator.AddPlain(ciphertext1, plaintextNTT, ciphertext1)
+
+		verifyTestVectors(testctx, testctx.decryptor, values2, ciphertext1, t)
+	})
+
+	t.Run(testString("Evaluator/ConstantPlaintext", testctx.params), func(t *testing.T) {
+
+		values, plaintext, ciphertext := newTestVectorsRingQ(testctx, testctx.encryptorPk, t)
+		require.False(t, plaintext.IsConstant())
+
+		c := values.Coeffs[0][0]
+		constant := make([]uint64, testctx.params.N())
+		for i := range constant {
+			constant[i] = c
+		}
+
+		ptConst := NewPlaintext(testctx.params)
+		testctx.encoder.EncodeUint(constant, ptConst)
+		require.True(t, ptConst.IsConstant())
+
+		ptConstRingT := NewPlaintextRingT(testctx.params)
+		testctx.encoder.EncodeUintRingT(constant, ptConstRingT)
+		require.True(t, ptConstRingT.IsConstant())
+
+		ptConstNTT := NewPlaintext(testctx.params)
+		testctx.ringQ.NTT(ptConst.Value, ptConstNTT.Value)
+		ptConstNTT.Value.IsNTT = true
+		require.True(t, ptConstNTT.IsConstant())
+
+		// AddPlain: the scalar path must match the general polynomial addition
+		ctWant := ciphertext.CopyNew()
+		testctx.ringQ.Add(ctWant.Value[0], ptConst.Value, ctWant.Value[0])
+
+		for _, pt := range []*Plaintext{ptConst, ptConstNTT} {
+			ctOut := NewCiphertext(testctx.params, 1)
+			testctx.evaluator.AddPlain(ciphertext, pt, ctOut)
+			for i := range ctOut.Value {
+				require.True(t, testctx.ringQ.Equal(ctWant.Value[i], ctOut.Value[i]))
+			}
+		}
+
+		valuesWant := values.CopyNew()
+		testctx.ringT.AddScalar(valuesWant, c, valuesWant)
+		verifyTestVectors(testctx, testctx.decryptor, valuesWant, ctWant, t)
+
+		// Mul by a PlaintextRingT: the scalar path must match the general polynomial multiplication
+		ctOut := NewCiphertext(testctx.params, 1)
+		testctx.evaluator.Mul(ciphertext, ptConstRingT, ctOut)
+		ctWant = NewCiphertext(testctx.params, 1)
+		testctx.evaluator.(*evaluator).mulPlaintextRingT(ciphertext, ptConstRingT, ctWant)
+		for i := range ctOut.Value {
+			require.True(t, testctx.ringQ.Equal(ctWant.Value[i], ctOut.Value[i]))
+		}
+
+		// Mul by a Plaintext: the scalar path must decrypt to the same values as the general tensoring
+		testctx.evaluator.Mul(ciphertext, ptConst, ctOut)
+		ctWant = NewCiphertext(testctx.params, 1)
+		testctx.evaluator.(*evaluator).tensorAndRescale(ciphertext.Ciphertext, ptConst.El(), ctWant.Ciphertext)
+
+		testctx.ringT.MulScalar(values, c, valuesWant)
+		verifyTestVectors(testctx, testctx.decryptor, valuesWant, ctOut, t)
+		verifyTestVectors(testctx, testctx.decryptor, valuesWant, ctWant, t)
+	})
+
+	t.Run(testString("Evaluator/ToNTT/FromNTT", testctx.params), func(t *testing.T) {
+
+		values, _, ciphertext := newTestVectorsRingQ(testctx, testctx.encryptorPk, t)
+		ciphertextNTT := NewCiphertext(testctx.params, 1)
+
+		testctx.evaluator.ToNTT(ciphertext, ciphertextNTT)
+
+		for i := range ciphertextNTT.Value {
+			require.True(t, ciphertextNTT.Value[i].IsNTT)
+			pol := testctx.ringQ.NewPoly()
+			testctx.ringQ.NTT(ciphertext.Value[i], pol)
+			require.True(t, testctx.ringQ.Equal(pol, ciphertextNTT.Value[i]))
+		}
+
+		// Idempotent on a ciphertext already in the NTT domain
+		testctx.evaluator.ToNTT(ciphertextNTT, ciphertextNTT)
+		testctx.evaluator.FromNTT(ciphertextNTT, ciphertextNTT)
+
+		for i := range ciphertextNTT.Value {
+			require.False(t, ciphertextNTT.Value[i].IsNTT)
+		}
+
+		verifyTestVectors(testctx, testctx.decryptor, values, ciphertextNTT, t)
+	})
+
 	t.Run(testString("Evaluator/Sub/op1=Ciphertext/op2=Ciphertext", testctx.params), func(t *testing.T) {
 
 		values1, _, ciphertext1 := newTestVectorsRingQ(testctx, testctx.encryptorPk, t)
@@ -457,6 +956,46 @@ func testEvaluator(testctx *testContext, t *testing.T) {
 		verifyTestVectors(testctx, testctx.decryptor, values1, ciphertext1, t)
 	})
 
+	t.Run(testString("Evaluator/AddScalar", testctx.params), func(t *testing.T) {
+
+		values1, _, ciphertext1 := newTestVectorsRingQ(testctx, testctx.encryptorPk, t)
+
+		testctx.evaluator.AddScalar(ciphertext1, 37, ciphertext1)
+		testctx.ringT.AddScalar(values1, 37, values1)
+
+		verifyTestVectors(testctx, testctx.decryptor, values1, ciphertext1, t)
+	})
+
+	t.Run(testString("Evaluator/AddScalarNew", testctx.params), func(t *testing.T) {
+
+		values1, _, ciphertext1 := newTestVectorsRingQ(testctx, testctx.encryptorPk, t)
+
+		ciphertext1 = testctx.evaluator.AddScalarNew(ciphertext1, testctx.params.T()-1)
+		testctx.ringT.AddScalar(values1, testctx.params.T()-1, values1)
+
+		verifyTestVectors(testctx, testctx.decryptor, values1, ciphertext1, t)
+	})
+
+	t.Run(testString("Evaluator/AddScalar/NTT", testctx.params), func(t *testing.T) {
+
+		values1, _, ciphertext1 := newTestVectorsRingQ(testctx, testctx.encryptorPk, t)
+
+		for i := range ciphertext1.Value {
+			testctx.ringQ.NTT(ciphertext1.Value[i], ciphertext1.Value[i])
+			ciphertext1.Value[i].IsNTT = true
+		}
+
+		testctx.evaluator.AddScalar(ciphertext1, 37, ciphertext1)
+		testctx.ringT.AddScalar(values1, 37, values1)
+
+		for i := range ciphertext1.Value {
+			testctx.ringQ.InvNTT(ciphertext1.Value[i], ciphertext1.Value[i])
+			ciphertext1.Value[i].IsNTT = false
+		}
+
+		verifyTestVectors(testctx, testctx.decryptor, values1, ciphertext1, t)
+	})
+
 	t.Run(testString("Evaluator/Mul/op1=Ciphertext/op2=Ciphertext", testctx.params), func(t *testing.T) {
 
 		values1, _, ciphertext1 := newTestVectorsRingQ(testctx, testctx.encryptorPk, t)
@@ -500,6 +1039,22 @@ func testEvaluator(testctx *testContext, t *testing.T) {
 		verifyTestVectors(testctx, testctx.decryptor, values1, receiver2, t)
 	})
 
+	t.Run(testString("Evaluator/Square", testctx.params), func(t *testing.T) {
+
+		values1, _, ciphertext1 := newTestVectorsRingQ(testctx, testctx.encryptorPk, t)
+
+		receiver := testctx.evaluator.SquareNew(ciphertext1)
+		require.Equal(t, 2, receiver.Degree())
+		testctx.ringT.MulCoeffs(values1, values1, values1)
+
+		verifyTestVectors(testctx, testctx.decryptor, values1, receiver, t)
+
+		testctx.evaluator.Square(ciphertext1, receiver)
+		verifyTestVectors(testctx, testctx.decryptor, values1, receiver, t)
+
+		require.Panics(t, func() { testctx.evaluator.Square(receiver, ciphertext1) })
+	})
+
 	t.Run(testString("Evaluator/Mul/op1=Ciphertext/op2=Plaintext", testctx.params), func(t *testing.T) {
 
 		values1, _, ciphertext1 := newTestVectorsRingQ(testctx, testctx.encryptorPk, t)
@@ -534,6 +1089,65 @@ func testEvaluator(testctx *testContext, t *testing.T) {
 		verifyTestVectors(testctx, testctx.decryptor, values1, ciphertext1, t)
 	})
 
+	t.Run(testString("Evaluator/MaskSlots", testctx.params), func(t *testing.T) {
+
+		values, _, ciphertext := newTestVectorsRingQ(testctx, testctx.encryptorPk, t)
+
+		slotIndices := []int{0, 7, testctx.params.N() >> 1, testctx.params.N() - 1, 7}
+		receiver := NewCiphertext(testctx.params, 1)
+
+		for i := 0; i < 2; i++ { // the second call re-uses the cached mask
+			testctx.evaluator.MaskSlots(ciphertext, slotIndices, receiver)
+
+			valuesWant := testctx.ringT.NewPoly()
+			for _, idx := range slotIndices {
+				valuesWant.Coeffs[0][idx] = values.Coeffs[0][idx]
+			}
+
+			verifyTestVectors(testctx, testctx.decryptor, valuesWant, receiver, t)
+		}
+
+		require.Panics(t, func() { testctx.evaluator.MaskSlots(ciphertext, []int{testctx.params.N()}, receiver) })
+
+		// the cache is bounded and evicts the least recently used masks
+		eval := testctx.evaluator.ShallowCopy().(*evaluator)
+		for i := 0; i < 2*maskCacheSize; i++ {
+			eval.MaskSlots(ciphertext, []int{i}, receiver)
+			require.LessOrEqual(t, len(eval.masks), maskCacheSize)
+			require.Equal(t, len(eval.masks), len(eval.maskKeys))
+
+			valuesWant := testctx.ringT.NewPoly()
+			valuesWant.Coeffs[0][i] = values.Coeffs[0][i]
+			verifyTestVectors(testctx, testctx.decryptor, valuesWant, receiver, t)
+		}
+		_, ok := eval.masks[string([]byte{0, 0, 0, 2*maskCacheSize - 1})]
+		require.True(t, ok)
+		_, ok = eval.masks[string([]byte{0, 0, 0, 0})]
+		require.False(t, ok)
+	})
+
+	t.Run(testString("Evaluator/ModSwitchToT", testctx.params), func(t *testing.T) {
+
+		values, _, ciphertext := newTestVectorsRingQ(testctx, testctx.encryptorSk, t)
+
+		// ct = c0 + c1*s
+		ct := NewCiphertext(testctx.params, 0)
+		testctx.ringQ.NTT(ciphertext.Value[1], ct.Value[0])
+		testctx.ringQ.MulCoeffsMontgomery(ct.Value[0], testctx.sk.Value.Q, ct.Value[0])
+		testctx.ringQ.InvNTT(ct.Value[0], ct.Value[0])
+		testctx.ringQ.Add(ct.Value[0], ciphertext.Value[0], ct.Value[0])
+
+		plaintext := NewPlaintextRingT(testctx.params)
+		testctx.evaluator.ModSwitchToT(ct, plaintext.Value)
+		verifyTestVectors(testctx, nil, values, plaintext, t)
+
+		testctx.ringQ.NTT(ct.Value[0], ct.Value[0])
+		ct.Value[0].IsNTT = true
+		plaintext = NewPlaintextRingT(testctx.params)
+		testctx.evaluator.ModSwitchToT(ct, plaintext.Value)
+		verifyTestVectors(testctx, nil, values, plaintext, t)
+	})
+
 	t.Run(testString("Evaluator/Mul/Relinearize", testctx.params), func(t *testing.T) {
 
 		if testctx.params.PCount() == 0 {
@@ -553,6 +1167,81 @@ func testEvaluator(testctx *testContext, t *testing.T) {
 		testctx.evaluator.Relinearize(receiver, receiver)
 		verifyTestVectors(testctx, testctx.decryptor, values1, receiver, t)
 	})
+
+	t.Run(testString("Evaluator/NoiseBudgetTracker", testctx.params), func(t *testing.T) {
+
+		if testctx.params.PCount() == 0 {
+			t.Skip("#Pi is empty")
+		}
+
+		params := testctx.params
+
+		// log2 of the infinity norm of Dec(ct) - Encode(values)
+		logNoise := func(ct *Ciphertext, values *ring.Poly) float64 {
+			pt := testctx.decryptor.DecryptNew(ct)
+			want := NewPlaintext(params)
+			testctx.encoder.EncodeUint(values.Coeffs[0], want)
+			testctx.ringQ.Sub(pt.Value, want.Value, pt.Value)
+
+			coeffs := make([]*big.Int, params.N())
+			for i := range coeffs {
+				coeffs[i] = new(big.Int)
+			}
+			testctx.ringQ.PolyToBigintCenteredLvl(pt.Level(), pt.Value, 1, coeffs)
+
+			max := new(big.Int)
+			for _, c := range coeffs {
+				if c.Abs(c).Cmp(max) > 0 {
+					max.Set(c)
+				}
+			}
+			f, _ := new(big.Float).SetInt(max).Float64()
+			return math.Log2(f)
+		}
+
+		values1, plaintext1, ciphertext1 := newTestVectorsRingQ(testctx, testctx.encryptorPk, t)
+		values2, _, ciphertext2 := newTestVectorsRingQ(testctx, testctx.encryptorPk, t)
+		for _, ct := range []*Ciphertext{ciphertext1, ciphertext2} {
+			ct.NoiseTracker = rlwe.NewNoiseBudgetTracker(params.Parameters, params.T(), ct.Level())
+		}
+
+		fresh := ciphertext1.NoiseTracker.LogNoise()
+		require.LessOrEqual(t, logNoise(ciphertext1, values1), fresh)
+
+		receiver := NewCiphertext(params, 2)
+		testctx.evaluator.Mul(ciphertext1, ciphertext2, receiver)
+		testctx.evaluator.Relinearize(receiver, receiver)
+		testctx.evaluator.Add(receiver, ciphertext2, receiver)
+		testctx.ringT.MulCoeffs(values1, values2, values1)
+		testctx.ringT.Add(values1, values2, values1)
+
+		require.NotNil(t, receiver.NoiseTracker)
+		require.Greater(t, receiver.NoiseTracker.LogNoise(), fresh)
+		require.LessOrEqual(t, logNoise(receiver, values1), receiver.NoiseTracker.LogNoise())
+
+		var logQ float64
+		for _, qi := range params.Q() {
+			logQ += math.Log2(float64(qi))
+		}
+		require.InDelta(t, logQ-1-math.Log2(float64(params.T()))-receiver.NoiseTracker.LogNoise(), receiver.NoiseTracker.PredictedBits(), 1e-9)
+
+		// the inputs are not modified and plaintexts are noiseless
+		require.Equal(t, fresh, ciphertext1.NoiseTracker.LogNoise())
+		noise := receiver.NoiseTracker.LogNoise()
+		testctx.evaluator.Add(receiver, plaintext1, receiver)
+		require.Equal(t, noise, receiver.NoiseTracker.LogNoise())
+
+		// the output of an operation on untracked ciphertexts is untracked
+		_, _, ciphertext3 := newTestVectorsRingQ(testctx, testctx.encryptorPk, t)
+		testctx.evaluator.Neg(ciphertext3, receiver)
+		require.Nil(t, receiver.NoiseTracker)
+
+		// copies keep the tracker and encryptions drop the stale one of their receiver
+		require.Equal(t, fresh, ciphertext1.CopyNew().NoiseTracker.LogNoise())
+		receiver = ciphertext1.CopyNew()
+		testctx.encryptorPk.Encrypt(plaintext1, receiver)
+		require.Nil(t, receiver.NoiseTracker)
+	})
 }
 
 func testEvaluatorKeySwitch(testctx *testContext, t *testing.T) {
@@ -616,6 +1305,60 @@ func testEvaluatorRotate(testctx *testContext, t *testing.T) {
 		}
 	})
 
+	t.Run(testString("Evaluator/InnerSumLog", testctx.params), func(t *testing.T) {
+
+		rowSize := testctx.params.N() >> 1
+		T := testctx.params.T()
+
+		for _, tc := range []struct{ batch, n int }{{1, 8}, {3, 5}} {
+
+			rotKey := testctx.kgen.GenRotationKeysForRotations(testctx.params.RotationsForInnerSumLog(tc.batch, tc.n), false, testctx.sk)
+			evaluator := testctx.evaluator.WithKey(rlwe.EvaluationKey{Rlk: testctx.rlk, Rtks: rotKey})
+
+			values, _, ciphertext := newTestVectorsRingQ(testctx, testctx.encryptorPk, t)
+
+			receiver := NewCiphertext(testctx.params, 1)
+			evaluator.InnerSumLog(ciphertext, tc.batch, tc.n, receiver)
+
+			// every slot holds the sum of the n slots at stride batch of its row, starting from itself
+			valuesWant := testctx.ringT.NewPoly()
+			for row := 0; row < 2; row++ {
+				for i := 0; i < rowSize; i++ {
+					for l := 0; l < tc.n; l++ {
+						valuesWant.Coeffs[0][row*rowSize+i] += values.Coeffs[0][row*rowSize+(i+l*tc.batch)%rowSize]
+					}
+					valuesWant.Coeffs[0][row*rowSize+i] %= T
+				}
+			}
+
+			verifyTestVectors(testctx, testctx.decryptor, valuesWant, receiver, t)
+		}
+	})
+
+	t.Run(testString("Evaluator/Pack", testctx.params), func(t *testing.T) {
+
+		nbCiphertexts, n := 3, 4
+		rowSize := testctx.params.N() >> 1
+
+		rotKey := testctx.kgen.GenRotationKeys(testctx.params.GaloisElementsForPack(nbCiphertexts, n), testctx.sk)
+		evaluator := testctx.evaluator.WithKey(rlwe.EvaluationKey{Rlk: testctx.rlk, Rtks: rotKey})
+
+		valuesWant := testctx.ringT.NewPoly()
+		ciphertexts := make([]*Ciphertext, nbCiphertexts)
+		for i := range ciphertexts {
+			var values *ring.Poly
+			values, _, ciphertexts[i] = newTestVectorsRingQ(testctx, testctx.encryptorPk, t)
+			for j := 0; j < n; j++ {
+				valuesWant.Coeffs[0][i*n+j] = values.Coeffs[0][j]
+				valuesWant.Coeffs[0][rowSize+i*n+j] = values.Coeffs[0][rowSize+j]
+			}
+		}
+
+		verifyTestVectors(testctx, testctx.decryptor, valuesWant, evaluator.PackNew(ciphertexts, n), t)
+
+		require.Panics(t, func() { evaluator.PackNew(ciphertexts, rowSize) })
+	})
+
 	t.Run(testString("Evaluator/RotateColumnsNew", testctx.params), func(t *testing.T) {
 
 		values, _, ciphertext := newTestVectorsRingQ(testctx, testctx.encryptorPk, t)
@@ -700,6 +1443,10 @@ func testMarshaller(testctx *testContext, t *testing.T) {
 		assert.Equal(t, 6.6, paramsWithCustomSecrets.Sigma())
 		assert.Equal(t, 192, paramsWithCustomSecrets.HammingWeight())
 
+		// checks that malformed JSON is rejected
+		var paramsMalformed Parameters
+		assert.NotNil(t, json.Unmarshal([]byte(fmt.Sprintf(`{"LogN":%d,"LogQ":[50,50],"LogP":[60],"H":"dense","T":65537}`, testctx.params.LogN())), &paramsMalformed))
+
 	})
 
 	t.Run(testString("Marshaller/Ciphertext", testctx.params), func(t *testing.T) {
@@ -7,6 +7,23 @@ import (
 	"github.com/tuneinsight/lattigo/v3/ring"
 )
 
+// scaleUpDelta takes a Poly pIn in R_t and writes Delta * pIn on the levels of the Poly pOut in R_q, where
+// Delta = floor(Q/T) is the precomputed scaling factor of params (see Parameters.DeltaModQi).
+// The first level is written last, so pIn and pOut can share their first level.
+func scaleUpDelta(params Parameters, pIn, pOut *ring.Poly) {
+	ringQ := params.RingQ()
+	for i := len(pOut.Coeffs) - 1; i >= 0; i-- {
+		qi := ringQ.Modulus[i]
+		bredParams := ringQ.BredParams[i]
+		mredParams := ringQ.MredParams[i]
+		delta := params.DeltaModQi(i)
+		p0tmp, p1tmp := pIn.Coeffs[0], pOut.Coeffs[i]
+		for j := range p1tmp {
+			p1tmp[j] = ring.MRed(ring.BRedAdd(p0tmp[j], qi, bredParams), delta, qi, mredParams)
+		}
+	}
+}
+
 // ScaleUpVec takes a Poly pIn in ringT, scales its coefficients up by (Q/T) mod Q, and writes the result in a
 // Poly pOut in ringQ.
 func ScaleUpVec(ringQ, ringT *ring.Ring, rescaleParams, tmp []uint64, pIn, pOut *ring.Poly) {
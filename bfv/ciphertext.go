@@ -8,21 +8,31 @@ import (
 // Ciphertext is a *ring.Poly array representing a polynomial of degree > 0 with coefficients in R_Q.
 type Ciphertext struct {
 	*rlwe.Ciphertext
+
+	// NoiseTracker is an optional symbolic estimate of the noise of the ciphertext, see rlwe.NoiseBudgetTracker.
+	// It is updated by the operations of the Evaluator, and reset to nil by the Encryptor and by the protocols
+	// of the dbfv package, which do not track the noise.
+	NoiseTracker *rlwe.NoiseBudgetTracker
 }
 
-// NewCiphertext creates a new ciphertext parameterized by degree, level and scale.
+// NewCiphertext creates a new ciphertext parameterized by degree, at the maximum level and in the coefficient
+// domain expected by the BFV Encryptor and Evaluator.
 func NewCiphertext(params Parameters, degree int) (ciphertext *Ciphertext) {
-	return &Ciphertext{rlwe.NewCiphertext(params.Parameters, degree, params.MaxLevel())}
+	return &Ciphertext{Ciphertext: params.Parameters.NewCiphertext(degree, params.MaxLevel())}
 }
 
 // NewCiphertextRandom generates a new uniformly distributed ciphertext of degree, level and scale.
 func NewCiphertextRandom(prng utils.PRNG, params Parameters, degree int) (ciphertext *Ciphertext) {
-	return &Ciphertext{rlwe.NewCiphertextRandom(prng, params.Parameters, degree, params.MaxLevel())}
+	return &Ciphertext{Ciphertext: rlwe.NewCiphertextRandom(prng, params.Parameters, degree, params.MaxLevel())}
 }
 
-// CopyNew creates a deep copy of the receiver ciphertext and returns it.
+// CopyNew creates a deep copy of the receiver ciphertext, including its NoiseTracker, and returns it.
 func (ct *Ciphertext) CopyNew() *Ciphertext {
-	return &Ciphertext{ct.Ciphertext.CopyNew()}
+	ctCopy := &Ciphertext{Ciphertext: ct.Ciphertext.CopyNew()}
+	if ct.NoiseTracker != nil {
+		ctCopy.NoiseTracker = ct.NoiseTracker.CopyNew()
+	}
+	return ctCopy
 }
 
 // MarshalBinary encodes a Ciphertext in a byte slice.
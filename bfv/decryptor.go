@@ -1,13 +1,19 @@
 package bfv
 
 import (
+	"fmt"
+	"math/big"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
 )
 
 // Decryptor is an interface wrapping a rlwe.Decryptor.
 type Decryptor interface {
 	DecryptNew(ciphertext *Ciphertext) (plaintext *Plaintext)
 	Decrypt(ciphertext *Ciphertext, plaintext *Plaintext)
+	DecryptChecked(ciphertext *Ciphertext, plaintext *Plaintext) (err error)
 	ShallowCopy() Decryptor
 	WithKey(sk *rlwe.SecretKey) Decryptor
 }
@@ -34,6 +40,67 @@ func (dec *decryptor) DecryptNew(ct *Ciphertext) (ptOut *Plaintext) {
 	return pt
 }
 
+// DecryptChecked decrypts the ciphertext, writes the result in ptOut and returns an error if the decryption
+// likely failed because the noise of the ciphertext overflowed the decryption bound Q/(2T).
+//
+// For each coefficient x of the decrypted plaintext, it measures the distance of x*T/Q to the nearest
+// integer, i.e. the fraction of the noise budget consumed by the noise (for the BGV scheme, the distance of
+// x/Q to zero). This distance is close to zero for a correct decryption, while an overflowed noise spreads
+// it uniformly in [0, 1/2]. An error is returned if more than N/8 coefficients are closer to the rounding
+// boundary 1/2 than to zero, in which case ptOut must not be trusted.
+func (dec *decryptor) DecryptChecked(ct *Ciphertext, ptOut *Plaintext) (err error) {
+
+	dec.Decrypt(ct, ptOut)
+
+	ringQ := dec.params.RingQ()
+	level := utils.MinInt(ct.Level(), ptOut.Level())
+
+	Q := ring.NewUint(1)
+	for _, qi := range ringQ.Modulus[:level+1] {
+		Q.Mul(Q, ring.NewUint(qi))
+	}
+	QHalf := new(big.Int).Rsh(Q, 1)
+
+	coeffs := make([]*big.Int, ringQ.N)
+	for i := range coeffs {
+		coeffs[i] = new(big.Int)
+	}
+
+	value := ptOut.Value
+	if value.IsNTT {
+		value = ringQ.NewPolyLvl(level)
+		ringQ.InvNTTLvl(level, ptOut.Value, value)
+	}
+
+	ringQ.PolyToBigintCenteredLvl(level, value, 1, coeffs)
+
+	T := ring.NewUint(dec.params.T())
+
+	var suspicious int
+	for _, c := range coeffs {
+
+		// r = x*T mod Q (or x for BGV), centered in [-Q/2, Q/2]
+		if dec.params.Scheme() == SchemeBFV {
+			c.Mul(c, T)
+			c.Mod(c, Q)
+			if c.Cmp(QHalf) > 0 {
+				c.Sub(c, Q)
+			}
+		}
+
+		// |r|/Q > 1/4
+		if c.Abs(c).Lsh(c, 2).Cmp(Q) > 0 {
+			suspicious++
+		}
+	}
+
+	if suspicious > ringQ.N>>3 {
+		return fmt.Errorf("likely decryption failure: %d out of %d coefficients are close to the rounding boundary", suspicious, ringQ.N)
+	}
+
+	return nil
+}
+
 // ShallowCopy creates a shallow copy of Decryptor in which all the read-only data-structures are
 // shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
 // Decryptor can be used concurrently.
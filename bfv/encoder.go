@@ -3,6 +3,8 @@ package bfv
 
 import (
 	"fmt"
+	"math"
+	"math/big"
 
 	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/utils"
@@ -41,9 +43,12 @@ type Encoder interface {
 	EncodeInt(coeffs []int64, pt *Plaintext)
 	EncodeIntRingT(coeffs []int64, pt *PlaintextRingT)
 	EncodeIntMul(coeffs []int64, pt *PlaintextMul)
+	EncodeSigned(values []int64, pt *Plaintext)
+	EncodeFixedPoint(values []float64, fracBits int, pt *Plaintext)
 
 	ScaleUp(*PlaintextRingT, *Plaintext)
 	ScaleDown(pt *Plaintext, ptRt *PlaintextRingT)
+	ReduceModT(pt *Plaintext)
 	RingTToMul(ptRt *PlaintextRingT, ptmul *PlaintextMul)
 	MulToRingT(pt *PlaintextMul, ptRt *PlaintextRingT)
 
@@ -52,6 +57,8 @@ type Encoder interface {
 	DecodeInt(pt interface{}, coeffs []int64)
 	DecodeUintNew(pt interface{}) (coeffs []uint64)
 	DecodeIntNew(pt interface{}) (coeffs []int64)
+	DecodeSigned(pt interface{}) (values []int64)
+	DecodeFixedPoint(pt interface{}, fracBits int) (values []float64)
 
 	ShallowCopy() Encoder
 }
@@ -63,8 +70,6 @@ type encoder struct {
 	indexMatrix []uint64
 	scaler      ring.Scaler
 
-	tInvModQ []uint64
-
 	tmpPoly *ring.Poly
 	tmpPtRt *PlaintextRingT
 }
@@ -99,16 +104,10 @@ func NewEncoder(params Parameters) Encoder {
 		pos &= (m - 1)
 	}
 
-	rescaleParams := make([]uint64, len(ringQ.Modulus))
-	for i, qi := range ringQ.Modulus {
-		rescaleParams[i] = ring.MForm(ring.ModExp(params.T(), qi-2, qi), qi, ringQ.BredParams[i])
-	}
-
 	return &encoder{
 		params:      params,
 		indexMatrix: indexMatrix,
 		scaler:      ring.NewRNSScaler(ringQ, ringT),
-		tInvModQ:    rescaleParams,
 		tmpPoly:     ringT.NewPoly(),
 		tmpPtRt:     NewPlaintextRingT(params),
 	}
@@ -125,7 +124,7 @@ func (ecd *encoder) EncodeUint(coeffs []uint64, p *Plaintext) {
 	ecd.ScaleUp(ptRt, p)
 }
 
-// EncodeUintRingT encodes a slice of uint64 into a Plaintext in R_t
+// EncodeUintRingT encodes a slice of uint64 into a Plaintext in R_t. The values are reduced modulo T.
 func (ecd *encoder) EncodeUintRingT(coeffs []uint64, p *PlaintextRingT) {
 	if len(coeffs) > len(ecd.indexMatrix) {
 		panic("invalid input to encode: number of coefficients must be smaller or equal to the ring degree")
@@ -135,8 +134,11 @@ func (ecd *encoder) EncodeUintRingT(coeffs []uint64, p *PlaintextRingT) {
 		panic("invalid plaintext to receive encoding: number of coefficients does not match the ring degree")
 	}
 
+	T := ecd.params.T()
+	bredParams := ecd.params.RingT().BredParams[0]
+
 	for i := 0; i < len(coeffs); i++ {
-		p.Value.Coeffs[0][ecd.indexMatrix[i]] = coeffs[i]
+		p.Value.Coeffs[0][ecd.indexMatrix[i]] = ring.BRedAdd(coeffs[i], T, bredParams)
 	}
 
 	for i := len(coeffs); i < len(ecd.indexMatrix); i++ {
@@ -159,7 +161,7 @@ func (ecd *encoder) EncodeUintMul(coeffs []uint64, p *PlaintextMul) {
 }
 
 // EncodeIntRingT encodes an int64 slice of size at most N on a plaintext. It also encodes the sign of the given integer (as its inverse modulo the plaintext modulus).
-// The sign will correctly decode as long as the absolute value of the coefficient does not exceed half of the plaintext modulus.
+// The values are reduced modulo T and the sign will correctly decode as long as the absolute value of the coefficient does not exceed half of the plaintext modulus.
 func (ecd *encoder) EncodeIntRingT(coeffs []int64, p *PlaintextRingT) {
 
 	if len(coeffs) > len(ecd.indexMatrix) {
@@ -170,12 +172,20 @@ func (ecd *encoder) EncodeIntRingT(coeffs []int64, p *PlaintextRingT) {
 		panic("invalid plaintext to receive encoding: number of coefficients does not match the ring degree")
 	}
 
+	T := ecd.params.T()
+	bredParams := ecd.params.RingT().BredParams[0]
+
 	for i := 0; i < len(coeffs); i++ {
 
 		if coeffs[i] < 0 {
-			p.Value.Coeffs[0][ecd.indexMatrix[i]] = uint64(int64(ecd.params.T()) + coeffs[i])
+			// uint64(-c) is the absolute value of c, including for c = math.MinInt64
+			if r := ring.BRedAdd(uint64(-coeffs[i]), T, bredParams); r != 0 {
+				p.Value.Coeffs[0][ecd.indexMatrix[i]] = T - r
+			} else {
+				p.Value.Coeffs[0][ecd.indexMatrix[i]] = 0
+			}
 		} else {
-			p.Value.Coeffs[0][ecd.indexMatrix[i]] = uint64(coeffs[i])
+			p.Value.Coeffs[0][ecd.indexMatrix[i]] = ring.BRedAdd(uint64(coeffs[i]), T, bredParams)
 		}
 	}
 
@@ -197,6 +207,50 @@ func (ecd *encoder) EncodeInt(coeffs []int64, p *Plaintext) {
 	ecd.ScaleUp(ptRt, p)
 }
 
+// EncodeSigned encodes an int64 slice of size at most N on a plaintext. Each value must lie in the centered
+// range (-T/2, T/2], that is [-(T-1)/2, (T-1)/2] for odd T and [-T/2+1, T/2] for even T, in which case it
+// is recovered exactly by DecodeSigned. The method panics before modifying the plaintext if a value is
+// out of range.
+func (ecd *encoder) EncodeSigned(values []int64, p *Plaintext) {
+
+	T := ecd.params.T()
+	hi, lo := int64(T>>1), -int64((T-1)>>1)
+
+	for i, v := range values {
+		if v < lo || v > hi {
+			panic(fmt.Errorf("cannot EncodeSigned: values[%d]=%d is not in [%d, %d]", i, v, lo, hi))
+		}
+	}
+
+	ecd.EncodeInt(values, p)
+}
+
+// EncodeFixedPoint encodes a float64 slice of size at most N on a plaintext, as fixed-point rationals with
+// fracBits fractional bits: each value is multiplied by 2^fracBits, rounded to the nearest integer and encoded
+// with EncodeSigned. The method panics before modifying the plaintext if fracBits is negative or if a scaled
+// value is not in the centered range (-T/2, T/2], in which case it would wrap around modulo T.
+func (ecd *encoder) EncodeFixedPoint(values []float64, fracBits int, p *Plaintext) {
+
+	if fracBits < 0 {
+		panic(fmt.Errorf("cannot EncodeFixedPoint: fracBits=%d is negative", fracBits))
+	}
+
+	T := ecd.params.T()
+	hi, lo := float64(T>>1), -float64((T-1)>>1)
+
+	scaled := make([]int64, len(values))
+	for i, v := range values {
+		// also rejects NaN and infinities
+		if r := math.Round(math.Ldexp(v, fracBits)); r >= lo && r <= hi {
+			scaled[i] = int64(r)
+		} else {
+			panic(fmt.Errorf("cannot EncodeFixedPoint: values[%d]=%v scaled by 2^%d is not in [%v, %v]", i, v, fracBits, lo, hi))
+		}
+	}
+
+	ecd.EncodeInt(scaled, p)
+}
+
 // EncodeIntMul encodes an int64 slice of size at most N on a PlaintextRingT (R_t) optimized for ciphertext-plaintext multiplication.
 func (ecd *encoder) EncodeIntMul(coeffs []int64, p *PlaintextMul) {
 	ptRt := &PlaintextRingT{p.Plaintext}
@@ -208,16 +262,55 @@ func (ecd *encoder) EncodeIntMul(coeffs []int64, p *PlaintextMul) {
 	ecd.RingTToMul(ptRt, p)
 }
 
-// ScaleUp transforms a PlaintextRingT (R_t) into a Plaintext (R_q) by scaling up the coefficient by Q/t.
+// ScaleUp transforms a PlaintextRingT (R_t) into a Plaintext (R_q) by multiplying its coefficients by
+// Delta = floor(Q/t) (see Parameters.Delta).
+// For the BGV scheme, the coefficients are lifted to R_q without scaling.
 func (ecd *encoder) ScaleUp(ptRt *PlaintextRingT, pt *Plaintext) {
-	ScaleUpVec(ecd.params.RingQ(), ecd.params.RingT(), ecd.tInvModQ, ecd.tmpPoly.Coeffs[0], ptRt.Value, pt.Value)
+	if ecd.params.Scheme() == SchemeBGV {
+		for i := range pt.Value.Coeffs {
+			copy(pt.Value.Coeffs[i], ptRt.Value.Coeffs[0])
+		}
+		return
+	}
+	scaleUpDelta(ecd.params, ptRt.Value, pt.Value)
 }
 
 // ScaleDown transforms a Plaintext (R_q) into a PlaintextRingT (R_t) by scaling down the coefficient by t/Q and rounding.
+// For the BGV scheme, the coefficients are instead centered modulo Q and reduced modulo t.
 func (ecd *encoder) ScaleDown(pt *Plaintext, ptRt *PlaintextRingT) {
+	if ecd.params.Scheme() == SchemeBGV {
+		ecd.reduceModT(pt, ptRt)
+		return
+	}
 	ecd.scaler.DivByQOverTRounded(pt.Value, ptRt.Value)
 }
 
+// ReduceModT reduces the message embedded in pt modulo T, by scaling it down to R_t and back up to R_q.
+// A plaintext whose message has coefficients outside of [0, T), for example a BFV plaintext set to
+// floor(Q/T)*(T+k), is then replaced by the canonical plaintext of the reduced message, floor(Q/T)*k for the
+// BFV scheme and k for the BGV scheme. The encoding methods reduce their inputs modulo T, hence the plaintexts
+// they return are left unchanged.
+func (ecd *encoder) ReduceModT(pt *Plaintext) {
+	ecd.ScaleDown(pt, ecd.tmpPtRt)
+	ecd.ScaleUp(ecd.tmpPtRt, pt)
+}
+
+// reduceModT reduces the coefficients of pt, centered modulo Q, modulo t and writes them on ptRt.
+// A BGV plaintext m + t*e is hence decoded as m as long as its coefficients are smaller than Q/2.
+func (ecd *encoder) reduceModT(pt *Plaintext, ptRt *PlaintextRingT) {
+
+	coeffs := make([]*big.Int, ecd.params.N())
+	for i := range coeffs {
+		coeffs[i] = new(big.Int)
+	}
+	ecd.params.RingQ().PolyToBigintCenteredLvl(pt.Level(), pt.Value, 1, coeffs)
+
+	T := new(big.Int).SetUint64(ecd.params.T())
+	for i, c := range coeffs {
+		ptRt.Value.Coeffs[0][i] = c.Mod(c, T).Uint64()
+	}
+}
+
 // RingTToMul transforms a PlaintextRingT into a PlaintextMul by operating the NTT transform
 // of R_q and putting the coefficients in Montgomery form.
 func (ecd *encoder) RingTToMul(ptRt *PlaintextRingT, ptMul *PlaintextMul) {
@@ -307,6 +400,45 @@ func (ecd *encoder) DecodeIntNew(p interface{}) (coeffs []int64) {
 	return
 }
 
+// DecodeSigned decodes any plaintext type and returns its coefficients in a new []int64, centered in the
+// range (-T/2, T/2]: a residue v is decoded as v if 2v <= T and as v-T otherwise. The boundary T/2 of an
+// even T is decoded as T/2. It panics if p is not PlaintextRingT, Plaintext or PlaintextMul.
+func (ecd *encoder) DecodeSigned(p interface{}) (values []int64) {
+
+	residues := ecd.DecodeUintNew(p)
+
+	T := ecd.params.T()
+	values = make([]int64, len(residues))
+	for i, v := range residues {
+		if v <= T>>1 {
+			values[i] = int64(v)
+		} else {
+			values[i] = int64(v) - int64(T)
+		}
+	}
+
+	return
+}
+
+// DecodeFixedPoint decodes any plaintext type and returns its coefficients in a new []float64, as fixed-point
+// rationals with fracBits fractional bits: each coefficient is decoded with DecodeSigned and divided by 2^fracBits.
+// It is the inverse of EncodeFixedPoint up to the rounding of the encoding.
+func (ecd *encoder) DecodeFixedPoint(p interface{}, fracBits int) (values []float64) {
+
+	if fracBits < 0 {
+		panic(fmt.Errorf("cannot DecodeFixedPoint: fracBits=%d is negative", fracBits))
+	}
+
+	signed := ecd.DecodeSigned(p)
+
+	values = make([]float64, len(signed))
+	for i, v := range signed {
+		values[i] = math.Ldexp(float64(v), -fracBits)
+	}
+
+	return
+}
+
 // ShallowCopy creates a shallow copy of Encoder in which all the read-only data-structures are
 // shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
 // Encoder can be used concurrently.
@@ -315,7 +447,6 @@ func (ecd *encoder) ShallowCopy() Encoder {
 		params:      ecd.params,
 		indexMatrix: ecd.indexMatrix,
 		scaler:      ring.NewRNSScaler(ecd.params.RingQ(), ecd.params.RingT()),
-		tInvModQ:    ecd.tInvModQ,
 		tmpPoly:     ecd.params.RingT().NewPoly(),
 		tmpPtRt:     NewPlaintextRingT(ecd.params),
 	}
@@ -1,14 +1,18 @@
 package bfv
 
 import (
+	"fmt"
+
 	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
 )
 
 // Encryptor an encryption interface for the BFV scheme.
 type Encryptor interface {
 	Encrypt(plaintext *Plaintext, ciphertext *Ciphertext)
 	EncryptNew(plaintext *Plaintext) *Ciphertext
+	EncryptSlice(plaintexts []*Plaintext, ctOut []*Ciphertext) (err error)
 	EncryptFromCRP(plaintext *Plaintext, crp *ring.Poly, ctOut *Ciphertext)
 	EncryptFromCRPNew(plaintext *Plaintext, crp *ring.Poly) *Ciphertext
 	ShallowCopy() Encryptor
@@ -18,31 +22,118 @@ type Encryptor interface {
 type encryptor struct {
 	rlwe.Encryptor
 	params Parameters
+	zero   *ring.Poly // read-only zero plaintext used by the BGV scheme
 }
 
 // NewEncryptor instantiates a new Encryptor for the BFV scheme. The key argument can
 // be *rlwe.PublicKey, *rlwe.SecretKey or nil.
+//
+// If the parameters instantiate the BGV scheme, the RLWE encryption is shared with BFV: a plaintext m
+// is encrypted as t*Enc(0) + m, which decrypts to m + t*e.
 func NewEncryptor(params Parameters, key interface{}) Encryptor {
-	return &encryptor{rlwe.NewEncryptor(params.Parameters, key), params}
+	var zero *ring.Poly
+	if params.Scheme() == SchemeBGV {
+		zero = params.RingQ().NewPoly()
+	}
+	return &encryptor{rlwe.NewEncryptor(params.Parameters, key), params, zero}
 }
 
 // Encrypt encrypts the input plaintext and write the result on ctOut.
 func (enc *encryptor) Encrypt(plaintext *Plaintext, ctOut *Ciphertext) {
+	ctOut.NoiseTracker = nil
+	if enc.params.Scheme() == SchemeBGV {
+		enc.encryptBGV(plaintext, ctOut)
+		return
+	}
 	enc.Encryptor.Encrypt(&rlwe.Plaintext{Value: plaintext.Value}, &rlwe.Ciphertext{Value: ctOut.Value})
 }
 
+// encryptBGV encrypts the input plaintext m as t*Enc(0) + m and writes the result on ctOut.
+func (enc *encryptor) encryptBGV(plaintext *Plaintext, ctOut *Ciphertext) {
+
+	level := utils.MinInt(plaintext.Level(), ctOut.Level())
+
+	ct := &rlwe.Ciphertext{Value: ctOut.Value}
+	enc.Encryptor.Encrypt(&rlwe.Plaintext{Value: &ring.Poly{Coeffs: enc.zero.Coeffs[:level+1]}}, ct)
+
+	ringQ := enc.params.RingQ()
+	for i := range ct.Value {
+		ringQ.MulScalarLvl(level, ct.Value[i], enc.params.T(), ct.Value[i])
+	}
+	ringQ.AddLvl(level, ct.Value[0], plaintext.Value, ct.Value[0])
+}
+
 // EncryptNew encrypts the input plaintext returns the result as a newly allocated ciphertext.
 func (enc *encryptor) EncryptNew(plaintext *Plaintext) *Ciphertext {
 	ct := NewCiphertext(enc.params, 1)
-	enc.Encryptor.Encrypt(plaintext.Plaintext, ct.Ciphertext)
+	enc.Encrypt(plaintext, ct)
 	return ct
 }
 
+// EncryptSlice encrypts plaintexts[i] on ctOut[i] for each i.
+// All the inputs are validated before any encryption takes place: if the slices do not have the same
+// length, if an element is nil, if a ciphertext is not of degree 1, if a plaintext and its ciphertext
+// do not have the same level or if the same ciphertext appears twice in ctOut, an error is returned
+// and none of the ciphertexts are modified.
+// Panics raised by the underlying ring operations are not recovered: in that case the ciphertexts
+// preceding the failing one will have been written and the following ones left unchanged.
+func (enc *encryptor) EncryptSlice(plaintexts []*Plaintext, ctOut []*Ciphertext) (err error) {
+
+	if len(plaintexts) != len(ctOut) {
+		return fmt.Errorf("cannot EncryptSlice: len(plaintexts)=%d != len(ctOut)=%d", len(plaintexts), len(ctOut))
+	}
+
+	N := enc.params.N()
+	seen := make(map[*Ciphertext]int, len(ctOut))
+
+	for i := range plaintexts {
+
+		pt, ct := plaintexts[i], ctOut[i]
+
+		if pt == nil || pt.Plaintext == nil || pt.Value == nil {
+			return fmt.Errorf("cannot EncryptSlice: plaintexts[%d] is nil", i)
+		}
+
+		if ct == nil || ct.Ciphertext == nil {
+			return fmt.Errorf("cannot EncryptSlice: ctOut[%d] is nil", i)
+		}
+
+		if ct.Degree() != 1 {
+			return fmt.Errorf("cannot EncryptSlice: ctOut[%d] must be of degree 1 but is of degree %d", i, ct.Degree())
+		}
+
+		if pt.Level() != ct.Level() {
+			return fmt.Errorf("cannot EncryptSlice: plaintexts[%d] level %d != ctOut[%d] level %d", i, pt.Level(), i, ct.Level())
+		}
+
+		if pt.Value.Degree() != N || ct.Value[0].Degree() != N || ct.Value[1].Degree() != N {
+			return fmt.Errorf("cannot EncryptSlice: ring degree of plaintexts[%d] or ctOut[%d] does not match params ring degree", i, i)
+		}
+
+		if j, ok := seen[ct]; ok {
+			return fmt.Errorf("cannot EncryptSlice: ctOut[%d] and ctOut[%d] are the same ciphertext", j, i)
+		}
+
+		seen[ct] = i
+	}
+
+	for i := range plaintexts {
+		enc.Encrypt(plaintexts[i], ctOut[i])
+	}
+
+	return nil
+}
+
 // EncryptFromCRP encrypts the input plaintext and writes the result in ctOut.
 // This method of encryption only works if the encryptor has been instantiated with
 // a secret key.
 // The passed crp is always treated as being in the NTT domain.
+// It panics for the BGV scheme, whose ciphertexts cannot have the crp as their mask.
 func (enc *encryptor) EncryptFromCRP(plaintext *Plaintext, crp *ring.Poly, ctOut *Ciphertext) {
+	if enc.params.Scheme() == SchemeBGV {
+		panic("cannot EncryptFromCRP: not supported for the BGV scheme")
+	}
+	ctOut.NoiseTracker = nil
 	enc.Encryptor.EncryptFromCRP(&rlwe.Plaintext{Value: plaintext.Value}, crp, &rlwe.Ciphertext{Value: ctOut.Value})
 }
 
@@ -52,7 +143,7 @@ func (enc *encryptor) EncryptFromCRP(plaintext *Plaintext, crp *ring.Poly, ctOut
 // The passed crp is always treated as being in the NTT domain.
 func (enc *encryptor) EncryptFromCRPNew(plaintext *Plaintext, crp *ring.Poly) *Ciphertext {
 	ct := NewCiphertext(enc.params, 1)
-	enc.Encryptor.EncryptFromCRP(&rlwe.Plaintext{Value: plaintext.Value}, crp, ct.Ciphertext)
+	enc.EncryptFromCRP(plaintext, crp, ct)
 	return ct
 }
 
@@ -60,7 +151,7 @@ func (enc *encryptor) EncryptFromCRPNew(plaintext *Plaintext, crp *ring.Poly) *C
 // shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
 // Encryptors can be used concurrently.
 func (enc *encryptor) ShallowCopy() Encryptor {
-	return &encryptor{enc.Encryptor.ShallowCopy(), enc.params}
+	return &encryptor{enc.Encryptor.ShallowCopy(), enc.params, enc.zero}
 }
 
 // WithKey creates a shallow copy of this encryptor with a new key in which all the read-only data-structures are
@@ -68,5 +159,5 @@ func (enc *encryptor) ShallowCopy() Encryptor {
 // Encryptors can be used concurrently.
 // Key can be *rlwe.PublicKey or *rlwe.SecretKey.
 func (enc *encryptor) WithKey(key interface{}) Encryptor {
-	return &encryptor{enc.Encryptor.WithKey(key), enc.params}
+	return &encryptor{enc.Encryptor.WithKey(key), enc.params, enc.zero}
 }
@@ -3,6 +3,7 @@ package bfv
 import (
 	"fmt"
 	"math/big"
+	"sort"
 
 	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/rlwe"
@@ -19,6 +20,9 @@ type Operand interface {
 type Evaluator interface {
 	Add(op0, op1 Operand, ctOut *Ciphertext)
 	AddNew(op0, op1 Operand) (ctOut *Ciphertext)
+	AddPlain(ct *Ciphertext, pt *Plaintext, ctOut *Ciphertext)
+	ToNTT(ct0 *Ciphertext, ctOut *Ciphertext)
+	FromNTT(ct0 *Ciphertext, ctOut *Ciphertext)
 	AddNoMod(op0, op1 Operand, ctOut *Ciphertext)
 	AddNoModNew(op0, op1 Operand) (ctOut *Ciphertext)
 	Sub(op0, op1 Operand, ctOut *Ciphertext)
@@ -31,8 +35,12 @@ type Evaluator interface {
 	ReduceNew(op Operand) (ctOut *Ciphertext)
 	MulScalar(op Operand, scalar uint64, ctOut *Ciphertext)
 	MulScalarNew(op Operand, scalar uint64) (ctOut *Ciphertext)
+	AddScalar(op Operand, scalar uint64, ctOut *Ciphertext)
+	AddScalarNew(op Operand, scalar uint64) (ctOut *Ciphertext)
 	Mul(op0 *Ciphertext, op1 Operand, ctOut *Ciphertext)
 	MulNew(op0 *Ciphertext, op1 Operand) (ctOut *Ciphertext)
+	Square(ct0 *Ciphertext, ctOut *Ciphertext)
+	SquareNew(ct0 *Ciphertext) (ctOut *Ciphertext)
 	Relinearize(ct0 *Ciphertext, ctOut *Ciphertext)
 	RelinearizeNew(ct0 *Ciphertext) (ctOut *Ciphertext)
 	SwitchKeys(ct0 *Ciphertext, switchKey *rlwe.SwitchingKey, ctOut *Ciphertext)
@@ -42,6 +50,11 @@ type Evaluator interface {
 	RotateRows(ct0 *Ciphertext, ctOut *Ciphertext)
 	RotateRowsNew(ct0 *Ciphertext) (ctOut *Ciphertext)
 	InnerSum(ct0 *Ciphertext, ctOut *Ciphertext)
+	InnerSumLog(ct0 *Ciphertext, batchSize, n int, ctOut *Ciphertext)
+	MaskSlots(ct0 *Ciphertext, slotIndices []int, ctOut *Ciphertext)
+	Pack(cts []*Ciphertext, n int, ctOut *Ciphertext)
+	PackNew(cts []*Ciphertext, n int) (ctOut *Ciphertext)
+	ModSwitchToT(ct0 *Ciphertext, pOut *ring.Poly)
 	ShallowCopy() Evaluator
 	WithKey(rlwe.EvaluationKey) Evaluator
 }
@@ -65,9 +78,8 @@ type evaluatorBase struct {
 	ringP    *ring.Ring
 	ringQMul *ring.Ring
 
-	t        uint64
-	tInvModQ []uint64
-	pHalf    *big.Int
+	t     uint64
+	pHalf *big.Int
 }
 
 func newEvaluatorPrecomp(params Parameters) *evaluatorBase {
@@ -90,8 +102,17 @@ type evaluatorBuffers struct {
 	poolQ    [][]*ring.Poly
 	poolQmul [][]*ring.Poly
 	tmpPt    *Plaintext
+	scaler   ring.Scaler
+
+	// encoder and masks are lazily allocated by MaskSlots.
+	encoder  Encoder
+	masks    map[string]*PlaintextMul
+	maskKeys []string // keys of masks, from the least to the most recently used
 }
 
+// maskCacheSize is the maximum number of masks cached by MaskSlots.
+const maskCacheSize = 8
+
 func newEvaluatorBuffer(eval *evaluatorBase) *evaluatorBuffers {
 	evb := new(evaluatorBuffers)
 	evb.poolQ = make([][]*ring.Poly, 4)
@@ -106,6 +127,7 @@ func newEvaluatorBuffer(eval *evaluatorBase) *evaluatorBuffers {
 	}
 
 	evb.tmpPt = NewPlaintext(eval.params)
+	evb.scaler = ring.NewRNSScaler(eval.ringQ, eval.params.RingT())
 
 	return evb
 }
@@ -113,16 +135,15 @@ func newEvaluatorBuffer(eval *evaluatorBase) *evaluatorBuffers {
 // NewEvaluator creates a new Evaluator, that can be used to do homomorphic
 // operations on ciphertexts and/or plaintexts. It stores a small pool of polynomials
 // and ciphertexts that will be used for intermediate values.
+// It panics if the parameters do not instantiate the BFV scheme.
 func NewEvaluator(params Parameters, evaluationKey rlwe.EvaluationKey) Evaluator {
+	if params.Scheme() != SchemeBFV {
+		panic(fmt.Errorf("cannot NewEvaluator: the Evaluator does not support the %s scheme", params.Scheme()))
+	}
 	ev := new(evaluator)
 	ev.evaluatorBase = newEvaluatorPrecomp(params)
 	ev.evaluatorBuffers = newEvaluatorBuffer(ev.evaluatorBase)
 
-	ev.tInvModQ = make([]uint64, len(params.RingQ().Modulus))
-	for i, qi := range params.RingQ().Modulus {
-		ev.tInvModQ[i] = ring.MForm(ring.ModExp(params.T(), qi-2, qi), qi, params.RingQ().BredParams[i])
-	}
-
 	ev.basisExtenderQ1toQ2 = ring.NewBasisExtender(ev.ringQ, ev.ringQMul)
 	if params.PCount() != 0 {
 		ev.KeySwitcher = rlwe.NewKeySwitcher(params.Parameters)
@@ -152,6 +173,7 @@ func NewEvaluators(params Parameters, evaluationKey rlwe.EvaluationKey, n int) [
 func (eval *evaluator) Add(op0, op1 Operand, ctOut *Ciphertext) {
 	el0, el1, elOut := eval.getElemAndCheckBinary(op0, op1, ctOut, utils.MaxInt(op0.Degree(), op1.Degree()), true)
 	eval.evaluateInPlaceBinary(el0, el1, elOut, eval.ringQ.Add)
+	trackNoiseBinary(op0, op1, ctOut, (*rlwe.NoiseBudgetTracker).Add)
 }
 
 // AddNew adds op0 to op1 and creates a new element ctOut to store the result.
@@ -161,10 +183,108 @@ func (eval *evaluator) AddNew(op0, op1 Operand) (ctOut *Ciphertext) {
 	return
 }
 
+// AddPlain adds the plaintext pt, which is already scaled by Delta, to the ciphertext ct and returns the result in ctOut.
+// The addition is carried out at the minimum level between ct and pt, without lifting pt to a ciphertext, and pt
+// is switched to the NTT domain of ct if their domains differ.
+// If pt is a constant, only its constant coefficient is added.
+func (eval *evaluator) AddPlain(ct *Ciphertext, pt *Plaintext, ctOut *Ciphertext) {
+
+	if ct == nil || pt == nil || ctOut == nil {
+		panic("cannot AddPlain: operands cannot be nil")
+	}
+
+	if ctOut.Degree() < ct.Degree() {
+		panic("cannot AddPlain: receiver operand degree is too small")
+	}
+
+	level := utils.MinInt(utils.MinInt(ct.Level(), pt.Level()), ctOut.Level())
+
+	if ctOut != ct {
+		for i := range ct.Value {
+			ring.CopyValuesLvl(level, ct.Value[i], ctOut.Value[i])
+			ctOut.Value[i].IsNTT = ct.Value[i].IsNTT
+		}
+	}
+
+	// A constant polynomial has the same coefficients in and out of the NTT domain, hence
+	// it is added as a scalar without switching its domain.
+	if pt.IsConstant() {
+		for i := 0; i < level+1; i++ {
+			qi := eval.ringQ.Modulus[i]
+			if ctOut.Value[0].IsNTT {
+				ring.AddScalarVec(ctOut.Value[0].Coeffs[i], ctOut.Value[0].Coeffs[i], pt.Value.Coeffs[i][0], qi)
+			} else {
+				ctOut.Value[0].Coeffs[i][0] = ring.CRed(ctOut.Value[0].Coeffs[i][0]+pt.Value.Coeffs[i][0], qi)
+			}
+		}
+		trackNoiseUnary(ct, ctOut, nil)
+		return
+	}
+
+	ptValue := pt.Value
+	if ptValue.IsNTT != ct.Value[0].IsNTT {
+		ptValue = eval.poolQ[0][0]
+		if ct.Value[0].IsNTT {
+			eval.ringQ.NTTLvl(level, pt.Value, ptValue)
+		} else {
+			eval.ringQ.InvNTTLvl(level, pt.Value, ptValue)
+		}
+	}
+
+	eval.ringQ.AddLvl(level, ctOut.Value[0], ptValue, ctOut.Value[0])
+	trackNoiseUnary(ct, ctOut, nil)
+}
+
+// ToNTT switches all the components of ct0 to the NTT domain and returns the result in ctOut, at the
+// minimum level between ct0 and ctOut. Components of ct0 already in the NTT domain are copied as they are.
+// The other operations of the Evaluator expect ciphertexts outside of the NTT domain, hence ToNTT
+// is meant for interoperability with external tools working in the NTT domain.
+func (eval *evaluator) ToNTT(ct0 *Ciphertext, ctOut *Ciphertext) {
+	eval.switchNTTDomain(ct0, ctOut, true)
+}
+
+// FromNTT switches all the components of ct0 out of the NTT domain and returns the result in ctOut, at the
+// minimum level between ct0 and ctOut. Components of ct0 already outside of the NTT domain are copied as they are.
+func (eval *evaluator) FromNTT(ct0 *Ciphertext, ctOut *Ciphertext) {
+	eval.switchNTTDomain(ct0, ctOut, false)
+}
+
+// switchNTTDomain is a method common to ToNTT and FromNTT. It brings every component of ct0 in the domain
+// given by isNTT and sets the IsNTT flags of ctOut accordingly.
+func (eval *evaluator) switchNTTDomain(ct0 *Ciphertext, ctOut *Ciphertext, isNTT bool) {
+
+	if ct0 == nil || ctOut == nil {
+		panic("cannot switch NTT domain: operands cannot be nil")
+	}
+
+	if ctOut.Degree() < ct0.Degree() {
+		panic("cannot switch NTT domain: receiver operand degree is too small")
+	}
+
+	level := utils.MinInt(ct0.Level(), ctOut.Level())
+
+	for i := range ct0.Value {
+		switch {
+		case ct0.Value[i].IsNTT == isNTT:
+			if ct0 != ctOut {
+				ring.CopyValuesLvl(level, ct0.Value[i], ctOut.Value[i])
+			}
+		case isNTT:
+			eval.ringQ.NTTLvl(level, ct0.Value[i], ctOut.Value[i])
+		default:
+			eval.ringQ.InvNTTLvl(level, ct0.Value[i], ctOut.Value[i])
+		}
+		ctOut.Value[i].IsNTT = isNTT
+	}
+
+	trackNoiseUnary(ct0, ctOut, nil)
+}
+
 // AddNoMod adds op0 to op1 without modular reduction, and returns the result in cOut.
 func (eval *evaluator) AddNoMod(op0, op1 Operand, ctOut *Ciphertext) {
 	el0, el1, elOut := eval.getElemAndCheckBinary(op0, op1, ctOut, utils.MaxInt(op0.Degree(), op1.Degree()), true)
 	eval.evaluateInPlaceBinary(el0, el1, elOut, eval.ringQ.AddNoMod)
+	trackNoiseBinary(op0, op1, ctOut, (*rlwe.NoiseBudgetTracker).Add)
 }
 
 // AddNoModNew adds op0 to op1 without modular reduction and creates a new element ctOut to store the result.
@@ -184,6 +304,8 @@ func (eval *evaluator) Sub(op0, op1 Operand, ctOut *Ciphertext) {
 			eval.ringQ.Neg(ctOut.Value[i], ctOut.Value[i])
 		}
 	}
+
+	trackNoiseBinary(op0, op1, ctOut, (*rlwe.NoiseBudgetTracker).Add)
 }
 
 // SubNew subtracts op1 from op0 and creates a new element ctOut to store the result.
@@ -204,6 +326,8 @@ func (eval *evaluator) SubNoMod(op0, op1 Operand, ctOut *Ciphertext) {
 			eval.ringQ.Neg(ctOut.Value[i], ctOut.Value[i])
 		}
 	}
+
+	trackNoiseBinary(op0, op1, ctOut, (*rlwe.NoiseBudgetTracker).Add)
 }
 
 // SubNoModNew subtracts op1 from op0 without modular reduction and creates a new element ctOut to store the result.
@@ -217,6 +341,7 @@ func (eval *evaluator) SubNoModNew(op0, op1 Operand) (ctOut *Ciphertext) {
 func (eval *evaluator) Neg(op Operand, ctOut *Ciphertext) {
 	el0, elOut := eval.getElemAndCheckUnary(op, ctOut, op.Degree())
 	evaluateInPlaceUnary(el0, elOut, eval.ringQ.Neg)
+	trackNoiseUnary(op, ctOut, nil)
 }
 
 // NegNew negates op and creates a new element to store the result.
@@ -230,6 +355,7 @@ func (eval *evaluator) NegNew(op Operand) (ctOut *Ciphertext) {
 func (eval *evaluator) Reduce(op Operand, ctOut *Ciphertext) {
 	el0, elOut := eval.getElemAndCheckUnary(op, ctOut, op.Degree())
 	evaluateInPlaceUnary(el0, elOut, eval.ringQ.Reduce)
+	trackNoiseUnary(op, ctOut, nil)
 }
 
 // ReduceNew applies a modular reduction to op and creates a new element ctOut to store the result.
@@ -244,6 +370,7 @@ func (eval *evaluator) MulScalar(op Operand, scalar uint64, ctOut *Ciphertext) {
 	el0, elOut := eval.getElemAndCheckUnary(op, ctOut, op.Degree())
 	fun := func(el, elOut *ring.Poly) { eval.ringQ.MulScalar(el, scalar, elOut) }
 	evaluateInPlaceUnary(el0, elOut, fun)
+	trackNoiseUnary(op, ctOut, func(nt *rlwe.NoiseBudgetTracker) { nt.MulScalar(scalar) })
 }
 
 // MulScalarNew multiplies op by a uint64 scalar and creates a new element ctOut to store the result.
@@ -253,6 +380,41 @@ func (eval *evaluator) MulScalarNew(op Operand, scalar uint64) (ctOut *Ciphertex
 	return
 }
 
+// AddScalar adds the constant plaintext scalar (mod t) to op and returns the result in ctOut.
+// The scalar is scaled up by Q/t and added to the degree zero element of op. If this element is
+// in the NTT domain, the scaled scalar is added to all its coefficients, else only to the constant one.
+func (eval *evaluator) AddScalar(op Operand, scalar uint64, ctOut *Ciphertext) {
+	el0, elOut := eval.getElemAndCheckUnary(op, ctOut, op.Degree())
+
+	if el0 != elOut {
+		for i := range el0.Value {
+			elOut.Value[i].Copy(el0.Value[i])
+		}
+	}
+
+	// delta = floor(Q/t) * (scalar mod t)
+	delta := new(big.Int).Mul(eval.params.Delta(), new(big.Int).SetUint64(scalar%eval.t))
+
+	if elOut.Value[0].IsNTT {
+		eval.ringQ.AddScalarBigint(elOut.Value[0], delta, elOut.Value[0])
+	} else {
+		tmp := new(big.Int)
+		for i := 0; i < elOut.Level()+1; i++ {
+			qi := eval.ringQ.Modulus[i]
+			elOut.Value[0].Coeffs[i][0] = ring.CRed(elOut.Value[0].Coeffs[i][0]+tmp.Mod(delta, ring.NewUint(qi)).Uint64(), qi)
+		}
+	}
+
+	trackNoiseUnary(op, ctOut, nil)
+}
+
+// AddScalarNew adds the constant plaintext scalar (mod t) to op and creates a new element ctOut to store the result.
+func (eval *evaluator) AddScalarNew(op Operand, scalar uint64) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(eval.params, op.Degree())
+	eval.AddScalar(op, scalar, ctOut)
+	return
+}
+
 // tensorAndRescale computes (ct0 x ct1) * (t/Q) and stores the result in ctOut.
 func (eval *evaluator) tensorAndRescale(ct0, ct1, ctOut *rlwe.Ciphertext) {
 
@@ -437,19 +599,47 @@ func (eval *evaluator) quantize(ctOut *rlwe.Ciphertext) {
 }
 
 // Mul multiplies op0 by op1 and returns the result in ctOut.
+// If op1 is a constant Plaintext or PlaintextRingT, the multiplication is carried out with MulScalar.
 func (eval *evaluator) Mul(op0 *Ciphertext, op1 Operand, ctOut *Ciphertext) {
 	el0, el1, elOut := eval.getElemAndCheckBinary(op0, op1, ctOut, op0.Degree()+op1.Degree(), false)
 	switch op1 := op1.(type) {
 	case *PlaintextMul:
 		eval.mulPlaintextMul(op0, op1, ctOut)
 	case *PlaintextRingT:
+		if op1.IsConstant() {
+			eval.MulScalar(op0, op1.Value.Coeffs[0][0], ctOut)
+			return
+		}
 		eval.mulPlaintextRingT(op0, op1, ctOut)
-	case *Plaintext, *Ciphertext:
+	case *Plaintext:
+		if op1.IsConstant() {
+			eval.MulScalar(op0, eval.constantPlaintextToScalar(op1), ctOut)
+			return
+		}
+		eval.tensorAndRescale(el0, el1, elOut)
+	case *Ciphertext:
 		eval.tensorAndRescale(el0, el1, elOut)
 	default:
 		panic(fmt.Errorf("invalid operand type for Mul: %T", op1))
 	}
 
+	trackNoiseBinary(op0, op1, ctOut, (*rlwe.NoiseBudgetTracker).Mul)
+}
+
+// constantPlaintextToScalar returns the constant round(t * pt / Q) mod t encoded by the constant plaintext pt.
+func (eval *evaluator) constantPlaintextToScalar(pt *Plaintext) uint64 {
+	coeffs := []*big.Int{new(big.Int)}
+	eval.ringQ.PolyToBigintLvl(pt.Level(), pt.Value, eval.ringQ.N, coeffs)
+
+	Q := ring.NewUint(1)
+	for _, qi := range eval.ringQ.Modulus[:pt.Level()+1] {
+		Q.Mul(Q, ring.NewUint(qi))
+	}
+
+	coeffs[0].Mul(coeffs[0], ring.NewUint(eval.t))
+	ring.DivRound(coeffs[0], Q, coeffs[0])
+
+	return coeffs[0].Mod(coeffs[0], ring.NewUint(eval.t)).Uint64()
 }
 
 func (eval *evaluator) mulPlaintextMul(ct0 *Ciphertext, ptRt *PlaintextMul, ctOut *Ciphertext) {
@@ -503,6 +693,22 @@ func (eval *evaluator) MulNew(op0 *Ciphertext, op1 Operand) (ctOut *Ciphertext)
 	return
 }
 
+// Square computes ct0 * ct0 and returns the result in ctOut, whose degree must be at least 2*ct0.Degree().
+// It is equivalent to Mul(ct0, ct0, ctOut), which already uses the symmetry of the product in the tensoring.
+// The output is not relinearized.
+func (eval *evaluator) Square(ct0 *Ciphertext, ctOut *Ciphertext) {
+	el0, _, elOut := eval.getElemAndCheckBinary(ct0, ct0, ctOut, 2*ct0.Degree(), false)
+	eval.tensorAndRescale(el0, el0, elOut)
+	trackNoiseBinary(ct0, ct0, ctOut, (*rlwe.NoiseBudgetTracker).Mul)
+}
+
+// SquareNew computes ct0 * ct0 and creates a new element ctOut of degree 2*ct0.Degree() to store the result.
+func (eval *evaluator) SquareNew(ct0 *Ciphertext) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(eval.params, 2*ct0.Degree())
+	eval.Square(ct0, ctOut)
+	return
+}
+
 // relinearize is a method common to Relinearize and RelinearizeNew. It switches ct0 to the NTT domain, applies the keyswitch, and returns the result out of the NTT domain.
 func (eval *evaluator) relinearize(ct0 *Ciphertext, ctOut *Ciphertext) {
 
@@ -541,9 +747,16 @@ func (eval *evaluator) Relinearize(ct0 *Ciphertext, ctOut *Ciphertext) {
 	if ct0.Degree() < 2 {
 		if ct0 != ctOut {
 			ctOut.Copy(ct0.El())
+			trackNoiseUnary(ct0, ctOut, nil)
 		}
 	} else {
+		degree := ct0.Degree()
 		eval.relinearize(ct0, ctOut)
+		trackNoiseUnary(ct0, ctOut, func(nt *rlwe.NoiseBudgetTracker) {
+			for ; degree > 1; degree-- {
+				nt.KeySwitch()
+			}
+		})
 	}
 }
 
@@ -573,6 +786,7 @@ func (eval *evaluator) SwitchKeys(ct0 *Ciphertext, switchKey *rlwe.SwitchingKey,
 
 	eval.ringQ.Add(ct0.Value[0], eval.Pool[1].Q, ctOut.Value[0])
 	ring.CopyValues(eval.Pool[2].Q, ctOut.Value[1])
+	trackNoiseUnary(ct0, ctOut, (*rlwe.NoiseBudgetTracker).KeySwitch)
 }
 
 // SwitchKeysNew applies the key-switching procedure to the ciphertext ct0 and creates a new ciphertext to store the result. It requires as an additional input a valid switching-key:
@@ -598,6 +812,7 @@ func (eval *evaluator) RotateColumns(ct0 *Ciphertext, k int, ctOut *Ciphertext)
 	if k == 0 {
 
 		ctOut.Copy(ct0.El())
+		trackNoiseUnary(ct0, ctOut, nil)
 
 	} else {
 
@@ -606,6 +821,7 @@ func (eval *evaluator) RotateColumns(ct0 *Ciphertext, k int, ctOut *Ciphertext)
 		if swk, inSet := eval.rtks.GetRotationKey(galElL); inSet {
 
 			eval.permute(ct0, galElL, swk, ctOut)
+			trackNoiseUnary(ct0, ctOut, (*rlwe.NoiseBudgetTracker).KeySwitch)
 
 		} else {
 			panic(fmt.Errorf("evaluator has no rotation key for rotation by %d", k))
@@ -634,6 +850,8 @@ func (eval *evaluator) RotateRows(ct0 *Ciphertext, ctOut *Ciphertext) {
 	} else {
 		panic("evaluator has no rotation key for row rotation")
 	}
+
+	trackNoiseUnary(ct0, ctOut, (*rlwe.NoiseBudgetTracker).KeySwitch)
 }
 
 // RotateRowsNew rotates the rows of ct0 and returns the result a new Ciphertext.
@@ -654,6 +872,7 @@ func (eval *evaluator) InnerSum(ct0 *Ciphertext, ctOut *Ciphertext) {
 	cTmp := NewCiphertext(eval.params, 1)
 
 	ctOut.Copy(ct0.El())
+	trackNoiseUnary(ct0, ctOut, nil)
 
 	for i := 1; i < int(eval.ringQ.N>>1); i <<= 1 {
 		eval.RotateColumns(ctOut, i, cTmp)
@@ -664,6 +883,192 @@ func (eval *evaluator) InnerSum(ct0 *Ciphertext, ctOut *Ciphertext) {
 	eval.Add(ctOut, cTmp, ctOut)
 }
 
+// InnerSumLog applies an inner sum on the rows of ct0 with log2(n) + HW(n) rotations.
+// The operation assumes that each row of ct0 encrypts N/(2*batchSize) sub-vectors of size batchSize which it adds
+// together (in parallel) by groups of n. It outputs in ctOut a ciphertext for which the "leftmost" sub-vector of
+// each group is equal to the sum of the group.
+// The required rotation keys can be generated with Parameters.RotationsForInnerSumLog(batchSize, n).
+func (eval *evaluator) InnerSumLog(ct0 *Ciphertext, batchSize, n int, ctOut *Ciphertext) {
+
+	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot InnerSumLog: input and output must be of degree 1")
+	}
+
+	if n < 1 {
+		panic("cannot InnerSumLog: n must be positive")
+	}
+
+	// acc = sum_{l < 2^i} Rotate(ct0, l*batchSize) at the i-th iteration
+	acc := ct0.CopyNew()
+	tmp := NewCiphertext(eval.params, 1)
+	sum := NewCiphertext(eval.params, 1)
+
+	first := true
+
+	// Binary reading of the input n
+	for i, j := 0, n; j > 0; i, j = i+1, j>>1 {
+
+		// If the binary reading scans a 1, adds the sum of the 2^i sub-vectors
+		// starting at the offset given by the higher bits of n
+		if j&1 == 1 {
+
+			k := (n - (n & ((2 << i) - 1))) * batchSize
+
+			eval.RotateColumns(acc, k, tmp)
+
+			if first {
+				sum.Copy(tmp.El())
+				trackNoiseUnary(tmp, sum, nil)
+				first = false
+			} else {
+				eval.Add(sum, tmp, sum)
+			}
+		}
+
+		if j>>1 > 0 {
+			eval.RotateColumns(acc, (1<<i)*batchSize, tmp)
+			eval.Add(acc, tmp, acc)
+		}
+	}
+
+	ctOut.Copy(sum.El())
+	trackNoiseUnary(sum, ctOut, nil)
+}
+
+// MaskSlots multiplies ct0 by the plaintext vector that is one on the slots given by slotIndices and zero elsewhere,
+// and returns the result in ctOut, such that only the selected slots are preserved.
+// The encoded masks are cached by the evaluator, keyed by their set of indices, and are re-used in subsequent calls.
+// The cache holds at most maskCacheSize masks and evicts the least recently used one. A mask applied repeatedly
+// alongside many others can instead be encoded once with Encoder.EncodeUintMul and applied with Mul.
+// The method panics if an index is not in [0, N).
+func (eval *evaluator) MaskSlots(ct0 *Ciphertext, slotIndices []int, ctOut *Ciphertext) {
+
+	indices := make([]int, len(slotIndices))
+	copy(indices, slotIndices)
+	sort.Ints(indices)
+
+	key := make([]byte, 0, 4*len(indices))
+	for i, idx := range indices {
+
+		if idx < 0 || idx >= eval.params.N() {
+			panic(fmt.Errorf("cannot MaskSlots: slot index %d is not in [0, %d)", idx, eval.params.N()))
+		}
+
+		if i == 0 || idx != indices[i-1] {
+			key = append(key, byte(idx>>24), byte(idx>>16), byte(idx>>8), byte(idx))
+		}
+	}
+
+	if eval.masks == nil {
+		eval.masks = make(map[string]*PlaintextMul)
+		eval.encoder = NewEncoder(eval.params)
+	}
+
+	mask, ok := eval.masks[string(key)]
+	if ok {
+		// moves the key to the most recently used position
+		for i, k := range eval.maskKeys {
+			if k == string(key) {
+				eval.maskKeys = append(eval.maskKeys[:i], eval.maskKeys[i+1:]...)
+				break
+			}
+		}
+	} else {
+		if len(eval.maskKeys) == maskCacheSize {
+			// evicts the least recently used mask and re-uses its buffer
+			mask = eval.masks[eval.maskKeys[0]]
+			delete(eval.masks, eval.maskKeys[0])
+			eval.maskKeys = eval.maskKeys[1:]
+		} else {
+			mask = NewPlaintextMul(eval.params)
+		}
+
+		values := make([]uint64, eval.params.N())
+		for _, idx := range indices {
+			values[idx] = 1
+		}
+		eval.encoder.EncodeUintMul(values, mask)
+		eval.masks[string(key)] = mask
+	}
+	eval.maskKeys = append(eval.maskKeys, string(key))
+
+	eval.Mul(ct0, mask, ctOut)
+}
+
+// Pack packs the ciphertexts cts, each carrying n useful slots in the first n columns of each row, into ctOut,
+// such that the first n columns of cts[i] are found in the columns [i*n, (i+1)*n) of ctOut. The other slots of
+// the inputs are masked out. The ciphertexts are combined with a rotate-and-add tree of depth ceil(log2(len(cts))),
+// which requires the rotation keys for the Galois elements returned by Parameters.GaloisElementsForPack.
+// The method panics if len(cts)*n is greater than N/2.
+func (eval *evaluator) Pack(cts []*Ciphertext, n int, ctOut *Ciphertext) {
+
+	if len(cts) == 0 || n < 1 {
+		panic("cannot Pack: cts must not be empty and n must be positive")
+	}
+
+	rowSize := eval.params.N() >> 1
+
+	if len(cts)*n > rowSize {
+		panic(fmt.Errorf("cannot Pack: len(cts)*n=%d is greater than the number of columns %d", len(cts)*n, rowSize))
+	}
+
+	slotIndices := make([]int, 2*n)
+	for i := 0; i < n; i++ {
+		slotIndices[i] = i
+		slotIndices[i+n] = i + rowSize
+	}
+
+	nodes := make([]*Ciphertext, len(cts))
+	for i := range cts {
+		nodes[i] = NewCiphertext(eval.params, 1)
+		eval.MaskSlots(cts[i], slotIndices, nodes[i])
+	}
+
+	tmp := NewCiphertext(eval.params, 1)
+	for stride := n; len(nodes) > 1; stride <<= 1 {
+		for i := 0; i < len(nodes)>>1; i++ {
+			eval.RotateColumns(nodes[2*i+1], -stride, tmp)
+			eval.Add(nodes[2*i], tmp, nodes[2*i])
+			nodes[i] = nodes[2*i]
+		}
+
+		if len(nodes)&1 == 1 {
+			nodes[len(nodes)>>1] = nodes[len(nodes)-1]
+			nodes = nodes[:len(nodes)>>1+1]
+		} else {
+			nodes = nodes[:len(nodes)>>1]
+		}
+	}
+
+	ctOut.Copy(nodes[0].El())
+	trackNoiseUnary(nodes[0], ctOut, nil)
+}
+
+// PackNew applies Pack and returns the result in a new Ciphertext.
+func (eval *evaluator) PackNew(cts []*Ciphertext, n int) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(eval.params, 1)
+	eval.Pack(cts, n, ctOut)
+	return
+}
+
+// ModSwitchToT computes round(T/Q * ct0) mod T and returns the result on pOut, a polynomial of the plaintext ring in
+// the coefficient domain. The input ct0 must be of degree zero, for example the inner product <(c0, c1), (1, s)> computed
+// during the decryption, in which case pOut is the encoded plaintext and decryption only remains to be decoded.
+// If ct0 is in the NTT domain, it is first brought back to the coefficient domain without modifying ct0.
+func (eval *evaluator) ModSwitchToT(ct0 *Ciphertext, pOut *ring.Poly) {
+
+	if ct0.Degree() != 0 {
+		panic("cannot ModSwitchToT: input must be of degree zero")
+	}
+
+	if ct0.Value[0].IsNTT {
+		eval.ringQ.InvNTT(ct0.Value[0], eval.poolQ[0][0])
+		eval.scaler.DivByQOverTRounded(eval.poolQ[0][0], pOut)
+	} else {
+		eval.scaler.DivByQOverTRounded(ct0.Value[0], pOut)
+	}
+}
+
 // ShallowCopy creates a shallow copy of this evaluator in which the read-only data-structures are
 // shared with the receiver.
 func (eval *evaluator) ShallowCopy() Evaluator {
@@ -705,7 +1110,7 @@ func (eval *evaluator) getRingQElem(op Operand) *rlwe.Ciphertext {
 	case *Ciphertext, *Plaintext:
 		return o.El()
 	case *PlaintextRingT:
-		ScaleUpVec(eval.params.RingQ(), eval.params.RingT(), eval.tInvModQ, eval.Pool[0].Q.Coeffs[0], o.Value, eval.tmpPt.Value)
+		scaleUpDelta(eval.params, o.Value, eval.tmpPt.Value)
 		return eval.tmpPt.El()
 	default:
 		panic(fmt.Errorf("invalid operand type for operation: %T", o))
@@ -749,6 +1154,50 @@ func (eval *evaluator) getElemAndCheckUnary(op0, opOut Operand, opOutMinDegree i
 	return
 }
 
+// noiseTracker returns the noise tracker of op, or nil if op is not a Ciphertext.
+func noiseTracker(op Operand) *rlwe.NoiseBudgetTracker {
+	if ct, ok := op.(*Ciphertext); ok {
+		return ct.NoiseTracker
+	}
+	return nil
+}
+
+// trackNoiseBinary sets the noise tracker of ctOut to a copy of the noise tracker of op0 updated with the one of
+// op1 by update. Operands without noise tracker, e.g. plaintexts, are considered noiseless and ctOut is left
+// without noise tracker if neither operand has one.
+func trackNoiseBinary(op0, op1 Operand, ctOut *Ciphertext, update func(nt, other *rlwe.NoiseBudgetTracker)) {
+
+	nt0, nt1 := noiseTracker(op0), noiseTracker(op1)
+	if nt0 == nil {
+		nt0, nt1 = nt1, nt0
+	}
+
+	if nt0 == nil {
+		ctOut.NoiseTracker = nil
+		return
+	}
+
+	nt := nt0.CopyNew()
+	update(nt, nt1)
+	ctOut.NoiseTracker = nt
+}
+
+// trackNoiseUnary sets the noise tracker of ctOut to a copy of the noise tracker of op updated by update, which
+// can be nil if the operation does not change the noise.
+func trackNoiseUnary(op Operand, ctOut *Ciphertext, update func(nt *rlwe.NoiseBudgetTracker)) {
+
+	if noiseTracker(op) == nil {
+		ctOut.NoiseTracker = nil
+		return
+	}
+
+	nt := noiseTracker(op).CopyNew()
+	if update != nil {
+		update(nt)
+	}
+	ctOut.NoiseTracker = nt
+}
+
 // evaluateInPlaceBinary applies the provided function in place on el0 and el1 and returns the result in elOut.
 func (eval *evaluator) evaluateInPlaceBinary(el0, el1, elOut *rlwe.Ciphertext, evaluate func(*ring.Poly, *ring.Poly, *ring.Poly)) {
 
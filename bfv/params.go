@@ -5,27 +5,32 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"math/big"
+	"math/bits"
 
 	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
 )
 
 var (
 	// PN12QP109 is a set of default parameters with logN=12 and logQP=109
 	PN12QP109 = ParametersLiteral{
-		LogN:  12,
-		T:     65537,
-		Q:     []uint64{0x7ffffec001, 0x8000016001}, // 39 + 39 bits
-		P:     []uint64{0x40002001},                 // 30 bits
-		Sigma: rlwe.DefaultSigma,
+		LogN:          12,
+		T:             65537,
+		Q:             []uint64{0x7ffffec001, 0x8000016001}, // 39 + 39 bits
+		P:             []uint64{0x40002001},                 // 30 bits
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
 	}
 	// PN13QP218 is a set of default parameters with logN=13 and logQP=218
 	PN13QP218 = ParametersLiteral{
-		LogN:  13,
-		T:     65537,
-		Q:     []uint64{0x3fffffffef8001, 0x4000000011c001, 0x40000000120001}, // 54 + 54 + 54 bits
-		P:     []uint64{0x7ffffffffb4001},                                     // 55 bits
-		Sigma: rlwe.DefaultSigma,
+		LogN:          13,
+		T:             65537,
+		Q:             []uint64{0x3fffffffef8001, 0x4000000011c001, 0x40000000120001}, // 54 + 54 + 54 bits
+		P:             []uint64{0x7ffffffffb4001},                                     // 55 bits
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
 	}
 
 	// PN14QP438 is a set of default parameters with logN=14 and logQP=438
@@ -34,8 +39,9 @@ var (
 		T:    65537,
 		Q: []uint64{0x100000000060001, 0x80000000068001, 0x80000000080001,
 			0x3fffffffef8001, 0x40000000120001, 0x3fffffffeb8001}, // 56 + 55 + 55 + 54 + 54 + 54 bits
-		P:     []uint64{0x80000000130001, 0x7fffffffe90001}, // 55 + 55 bits
-		Sigma: rlwe.DefaultSigma,
+		P:             []uint64{0x80000000130001, 0x7fffffffe90001}, // 55 + 55 bits
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
 	}
 
 	// PN15QP880 is a set of default parameters with logN=15 and logQP=880
@@ -46,35 +52,39 @@ var (
 			0x400000000270001, 0x400000000350001, 0x400000000360001, // 58 + 58 + 58 bits
 			0x3ffffffffc10001, 0x3ffffffffbe0001, 0x3ffffffffbd0001, // 58 + 58 + 58 bits
 			0x4000000004d0001, 0x400000000570001, 0x400000000660001}, // 58 + 58 + 58 bits
-		P:     []uint64{0xffffffffffc0001, 0x10000000001d0001, 0x10000000006e0001}, // 60 + 60 + 60 bits
-		Sigma: rlwe.DefaultSigma,
+		P:             []uint64{0xffffffffffc0001, 0x10000000001d0001, 0x10000000006e0001}, // 60 + 60 + 60 bits
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
 	}
 
 	// PN12QP101pq is a set of default (post quantum) parameters with logN=12 and logQP=101
 	PN12QP101pq = ParametersLiteral{ // LogQP = 101.00005709794536
-		LogN:  12,
-		T:     65537,
-		Q:     []uint64{0x800004001, 0x800008001}, // 2*35
-		P:     []uint64{0x80014001},               // 1*31
-		Sigma: rlwe.DefaultSigma,
+		LogN:          12,
+		T:             65537,
+		Q:             []uint64{0x800004001, 0x800008001}, // 2*35
+		P:             []uint64{0x80014001},               // 1*31
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
 	}
 
 	// PN13QP202pq is a set of default (post quantum) parameters with logN=13 and logQP=202
 	PN13QP202pq = ParametersLiteral{ // LogQP = 201.99999999994753
-		LogN:  13,
-		T:     65537,
-		Q:     []uint64{0x7fffffffe0001, 0x7fffffffcc001, 0x3ffffffffc001}, // 2*51 + 50
-		P:     []uint64{0x4000000024001},                                   // 50
-		Sigma: rlwe.DefaultSigma,
+		LogN:          13,
+		T:             65537,
+		Q:             []uint64{0x7fffffffe0001, 0x7fffffffcc001, 0x3ffffffffc001}, // 2*51 + 50
+		P:             []uint64{0x4000000024001},                                   // 50
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
 	}
 
 	// PN14QP411pq is a set of default (post quantum) parameters with logN=14 and logQP=411
 	PN14QP411pq = ParametersLiteral{ // LogQP = 410.9999999999886
-		LogN:  14,
-		T:     65537,
-		Q:     []uint64{0x7fffffffff18001, 0x8000000000f8001, 0x7ffffffffeb8001, 0x800000000158001, 0x7ffffffffe70001}, // 5*59
-		P:     []uint64{0x7ffffffffe10001, 0x400000000068001},                                                          // 59+58
-		Sigma: rlwe.DefaultSigma,
+		LogN:          14,
+		T:             65537,
+		Q:             []uint64{0x7fffffffff18001, 0x8000000000f8001, 0x7ffffffffeb8001, 0x800000000158001, 0x7ffffffffe70001}, // 5*59
+		P:             []uint64{0x7ffffffffe10001, 0x400000000068001},                                                          // 59+58
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
 	}
 
 	// PN15QP827pq is a set of default (post quantum) parameters with logN=15 and logQP=827
@@ -84,8 +94,9 @@ var (
 		Q: []uint64{0x7ffffffffe70001, 0x7ffffffffe10001, 0x7ffffffffcc0001, 0x7ffffffffba0001, 0x8000000004a0001,
 			0x7ffffffffb00001, 0x800000000890001, 0x8000000009d0001, 0x7ffffffff630001, 0x800000000a70001,
 			0x7ffffffff510001}, // 11*59
-		P:     []uint64{0x800000000b80001, 0x800000000bb0001, 0xffffffffffc0001}, // 2*59+60
-		Sigma: rlwe.DefaultSigma,
+		P:             []uint64{0x800000000b80001, 0x800000000bb0001, 0xffffffffffc0001}, // 2*59+60
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
 	}
 )
 
@@ -95,6 +106,94 @@ var DefaultParams = []ParametersLiteral{PN12QP109, PN13QP218, PN14QP438, PN15QP8
 // DefaultPostQuantumParams is a set of default BFV parameters ensuring 128 bit security in the post-quantum setting.
 var DefaultPostQuantumParams = []ParametersLiteral{PN12QP101pq, PN13QP202pq, PN14QP411pq, PN15QP827pq}
 
+// FilterParamsBySecurity returns the parameters of DefaultParams and DefaultPostQuantumParams
+// whose SecurityLevel label is equal to level.
+func FilterParamsBySecurity(level int) (params []Parameters) {
+	for _, pl := range append(append([]ParametersLiteral{}, DefaultParams...), DefaultPostQuantumParams...) {
+		if pl.SecurityLevel == level {
+			p, err := NewParametersFromLiteral(pl)
+			if err != nil {
+				panic(err)
+			}
+			params = append(params, p)
+		}
+	}
+	return
+}
+
+// maxLogQP is the maximum bit-size of the modulus QP ensuring 128 bit security in the classic
+// setting for a ternary secret, indexed by the ring degree LogN (see HomomorphicEncryption.org).
+var maxLogQP = map[int]int{10: 27, 11: 54, 12: 109, 13: 218, 14: 438, 15: 881, 16: 1761}
+
+// ModuliForDepth returns a moduli chain Q and a special modulus P enabling the evaluation of
+// circuits of multiplicative depth depth, for a ring of degree n and plaintext modulus t.
+// The size of Q is estimated from a heuristic noise growth of about log2(t) + logN bits per
+// level, in addition to the noise of a fresh encryption. It returns a non-nil error if the
+// resulting modulus QP does not ensure 128 bit security for the given ring degree.
+func ModuliForDepth(n int, t uint64, depth int) (q, p []uint64, err error) {
+
+	if n <= 0 || n&(n-1) != 0 {
+		return nil, nil, fmt.Errorf("cannot ModuliForDepth: n=%d is not a power of two", n)
+	}
+
+	logN := bits.Len64(uint64(n)) - 1
+
+	maxBits, ok := maxLogQP[logN]
+	if !ok {
+		return nil, nil, fmt.Errorf("cannot ModuliForDepth: no security estimate for n=%d", n)
+	}
+
+	if depth < 0 {
+		return nil, nil, fmt.Errorf("cannot ModuliForDepth: depth=%d cannot be negative", depth)
+	}
+
+	logT := bits.Len64(t)
+
+	// log2(t) for the scaling factor, logN + 5 for the fresh noise and one bit of
+	// decryption margin, plus the noise added by each multiplication.
+	logQ := logT + logN + 5 + 1 + depth*(logT+logN+4)
+
+	count := (logQ + rlwe.MaxModuliSize - 1) / rlwe.MaxModuliSize
+	logQi := utils.MaxInt((logQ+count-1)/count, logT+1)
+
+	if logQi*(count+1) > maxBits {
+		return nil, nil, fmt.Errorf("cannot ModuliForDepth: depth=%d requires logQP=%d > %d for n=%d", depth, logQi*(count+1), maxBits, n)
+	}
+
+	logQis := make([]int, count)
+	for i := range logQis {
+		logQis[i] = logQi
+	}
+
+	return rlwe.GenModuli(logN, logQis, []int{logQi})
+}
+
+// Scheme identifies the homomorphic encryption scheme instantiated by a set of Parameters.
+// The BFV and BGV schemes share the same moduli chain and plaintext modulus and only differ
+// in the way messages are embedded in the ciphertext space.
+type Scheme int
+
+const (
+	// SchemeBFV is the scale-invariant BFV scheme, in which the message is scaled by floor(Q/T)
+	// and the ciphertexts decrypt to floor(Q/T)*m + e. It is the default scheme.
+	SchemeBFV Scheme = iota
+	// SchemeBGV is the BGV scheme, in which the message is not scaled and the ciphertexts
+	// decrypt to m + T*e.
+	SchemeBGV
+)
+
+// String returns the name of the scheme.
+func (s Scheme) String() string {
+	switch s {
+	case SchemeBFV:
+		return "BFV"
+	case SchemeBGV:
+		return "BGV"
+	default:
+		return fmt.Sprintf("Scheme(%d)", int(s))
+	}
+}
+
 // ParametersLiteral is a literal representation of BFV parameters.  It has public
 // fields and is used to express unchecked user-defined parameters literally into
 // Go programs. The NewParametersFromLiteral function is used to generate the actual
@@ -107,16 +206,21 @@ var DefaultPostQuantumParams = []ParametersLiteral{PN12QP101pq, PN13QP202pq, PN1
 //
 // Optionally, users may specify the error variance (Sigma) and secrets' density (H). If left
 // unset, standard default values for these field are substituted at parameter creation (see
-// NewParametersFromLiteral).
+// NewParametersFromLiteral). The Scheme field selects between BFV (default) and BGV.
 type ParametersLiteral struct {
-	LogN  int // Log Ring degree (power of 2)
-	Q     []uint64
-	P     []uint64
-	LogQ  []int `json:",omitempty"`
-	LogP  []int `json:",omitempty"`
-	H     int
-	Sigma float64 // Gaussian sampling standard deviation
-	T     uint64  // Plaintext modulus
+	LogN               int // Log Ring degree (power of 2)
+	Q                  []uint64
+	P                  []uint64
+	LogQ               []int `json:",omitempty"`
+	LogP               []int `json:",omitempty"`
+	H                  int
+	Sigma              float64                // Gaussian sampling standard deviation
+	ErrorDistribution  rlwe.ErrorDistribution `json:",omitempty"`
+	GaussianTailFactor float64                `json:",omitempty"` // Tail-cut of the error distribution, in multiples of Sigma
+	T                  uint64                 // Plaintext modulus
+	SecurityLevel      int                    `json:",omitempty"` // Bit-security label (128, 192 or 256), not checked
+	Scheme             Scheme                 `json:",omitempty"` // SchemeBFV (default) or SchemeBGV
+	MinModuliGap       float64                `json:",omitempty"` // Minimum relative gap between consecutive Qi, checked if set
 }
 
 // Parameters represents a parameter set for the BFV cryptosystem. Its fields are private and
@@ -125,6 +229,9 @@ type Parameters struct {
 	rlwe.Parameters
 	ringQMul *ring.Ring
 	ringT    *ring.Ring
+	delta    *big.Int
+	deltaQi  []uint64
+	scheme   Scheme
 }
 
 // NewParameters instantiate a set of BFV parameters from the generic RLWE parameters and the BFV-specific ones.
@@ -148,7 +255,39 @@ func NewParameters(rlweParams rlwe.Parameters, t uint64) (p Parameters, err erro
 		return Parameters{}, err
 	}
 
-	return Parameters{rlweParams, ringQMul, ringT}, nil
+	delta, deltaQi := newDelta(rlweParams.RingQ(), t)
+
+	return Parameters{rlweParams, ringQMul, ringT, delta, deltaQi, SchemeBFV}, nil
+}
+
+// checkScheme returns an error if scheme is not a valid Scheme or if it cannot be instantiated with
+// the plaintext modulus t and the moduli q. BGV embeds the plaintexts of R_t in R_q without scaling,
+// hence it requires t to be smaller than every modulus of q.
+func checkScheme(scheme Scheme, t uint64, q []uint64) error {
+	switch scheme {
+	case SchemeBFV:
+		return nil
+	case SchemeBGV:
+		for i, qi := range q {
+			if t >= qi {
+				return fmt.Errorf("cannot use scheme BGV: t=%d is not smaller than Q[%d]=%d", t, i, qi)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid scheme: %s", scheme)
+	}
+}
+
+// newDelta returns floor(Q/t) and its residues modulo each Qi in the Montgomery form.
+func newDelta(ringQ *ring.Ring, t uint64) (delta *big.Int, deltaQi []uint64) {
+	delta = new(big.Int).Quo(ringQ.ModulusBigint, new(big.Int).SetUint64(t))
+	deltaQi = make([]uint64, len(ringQ.Modulus))
+	tmp := new(big.Int)
+	for i, qi := range ringQ.Modulus {
+		deltaQi[i] = ring.MForm(tmp.Mod(delta, new(big.Int).SetUint64(qi)).Uint64(), qi, ringQ.BredParams[i])
+	}
+	return
 }
 
 // NewParametersFromLiteral instantiate a set of BFV parameters from a ParametersLiteral specification.
@@ -156,11 +295,22 @@ func NewParameters(rlweParams rlwe.Parameters, t uint64) (p Parameters, err erro
 //
 // See `rlwe.NewParametersFromLiteral` for default values of the optional fields.
 func NewParametersFromLiteral(pl ParametersLiteral) (Parameters, error) {
-	rlweParams, err := rlwe.NewParametersFromLiteral(rlwe.ParametersLiteral{LogN: pl.LogN, Q: pl.Q, P: pl.P, LogQ: pl.LogQ, LogP: pl.LogP, H: pl.H, Sigma: pl.Sigma})
+	rlweParams, err := rlwe.NewParametersFromLiteral(rlwe.ParametersLiteral{LogN: pl.LogN, Q: pl.Q, P: pl.P, LogQ: pl.LogQ, LogP: pl.LogP, H: pl.H, Sigma: pl.Sigma, ErrorDistribution: pl.ErrorDistribution, GaussianTailFactor: pl.GaussianTailFactor, SecurityLevel: pl.SecurityLevel, MinModuliGap: pl.MinModuliGap})
+	if err != nil {
+		return Parameters{}, err
+	}
+
+	p, err := NewParameters(rlweParams, pl.T)
 	if err != nil {
 		return Parameters{}, err
 	}
-	return NewParameters(rlweParams, pl.T)
+
+	if err = checkScheme(pl.Scheme, p.T(), p.Q()); err != nil {
+		return Parameters{}, err
+	}
+	p.scheme = pl.Scheme
+
+	return p, nil
 }
 
 // RingQMul returns a pointer to the ring of the extended basis for multiplication
@@ -168,6 +318,11 @@ func (p Parameters) RingQMul() *ring.Ring {
 	return p.ringQMul
 }
 
+// Scheme returns the scheme instantiated by the parameters, SchemeBFV or SchemeBGV.
+func (p Parameters) Scheme() Scheme {
+	return p.scheme
+}
+
 // T returns the plaintext coefficient modulus t
 func (p Parameters) T() uint64 {
 	return p.ringT.Modulus[0]
@@ -178,13 +333,231 @@ func (p Parameters) RingT() *ring.Ring {
 	return p.ringT
 }
 
+// SupportsBatching returns true if the plaintext modulus T enables the SIMD batching of N values per
+// plaintext, that is, if T is a prime congruent to 1 modulo 2N. Since the plaintext ring requires an
+// NTT-friendly modulus, parameters created with NewParameters always support batching and only the zero
+// value Parameters{} does not. The check is performed on T and does not rely on the ring T.
+func (p Parameters) SupportsBatching() bool {
+	if p.ringT == nil {
+		return false
+	}
+	return supportsBatching(p.N(), p.T())
+}
+
+// supportsBatching returns true if t is a prime congruent to 1 modulo 2N.
+func supportsBatching(N int, t uint64) bool {
+	return t > 1 && (t-1)%uint64(2*N) == 0 && ring.IsPrime(t)
+}
+
+// Delta returns the scaling factor floor(Q/T) between the plaintext space and the ciphertext space.
+// The returned value is precomputed and shared, and must not be modified.
+func (p Parameters) Delta() *big.Int {
+	return p.delta
+}
+
+// DeltaModQi returns floor(Q/T) mod Q[i] in the Montgomery form. It panics if i is not a valid index of Q.
+func (p Parameters) DeltaModQi(i int) uint64 {
+	if i < 0 || i >= len(p.deltaQi) {
+		panic(fmt.Errorf("cannot DeltaModQi: i=%d is not in [0, %d]", i, len(p.deltaQi)-1))
+	}
+	return p.deltaQi[i]
+}
+
+// PlaintextBits returns the number of bits of an integer that can be stored in a
+// single plaintext coefficient or slot, i.e. the bit-length of T-1.
+func (p Parameters) PlaintextBits() int {
+	return bits.Len64(p.T() - 1)
+}
+
+// SlotCount returns the number of independent plaintext slots of Z_T[X]/(X^N+1).
+//
+// For an odd prime T, X^N+1 splits modulo T into N/d irreducible factors of degree d, where d
+// is the multiplicative order of T modulo 2N, and the plaintext ring is isomorphic to N/d copies
+// of GF(T^d). Since the plaintext ring must support the NTT for batching, T = 1 mod 2N, d = 1
+// and SlotCount returns N.
+func (p Parameters) SlotCount() int {
+	return slotCount(p.N(), p.T())
+}
+
+// slotCount returns the number of irreducible factors of X^N+1 modulo t, for N a power of two.
+// For even t, X^N+1 = (X+1)^N mod 2 does not split into coprime factors and 1 is returned.
+func slotCount(N int, t uint64) int {
+
+	if t&1 == 0 {
+		return 1
+	}
+
+	// The order of (Z/2NZ)* is N, a power of two, hence the order d of t is the
+	// smallest power of two such that t^d = 1 mod 2N.
+	mask := uint64(2*N - 1)
+	x := t & mask
+	d := 1
+	for x != 1 {
+		x = (x * x) & mask
+		d <<= 1
+	}
+
+	return N / d
+}
+
+// DecryptionFailureProb returns a heuristic estimate of the probability that the decryption of a
+// ciphertext fails after mulDepth successive multiplications, each followed by a relinearization,
+// starting from fresh public-key encryptions, i.e. the probability that one of the N coefficients
+// of its noise exceeds Q/(2T).
+//
+// The estimate tracks the variance V of the coefficients of the invariant noise (T/Q)*<ct, sk> - m,
+// assuming independent coefficients, plaintexts uniform in Z_T and a secret of Hamming weight h:
+//
+//   - fresh encryption: V = (T/Q)^2 * Sigma^2 * (1 + 2h)
+//   - multiplication: V' = 2N * T^2/12 * V + N * V^2 + (T/Q)^2 * (1 + h + h^2)/12
+//   - relinearization: V' = V + (T/Q)^2 * (N * Sigma^2 * sum_j D_j^2/12 / P^2 + (1 + h)/12)
+//
+// where the D_j are the moduli of the digits of the RNS decomposition used by the key-switching.
+// The probability is then bounded by N * erfc(1/(2*sqrt(2V))), capped at 1.
+func (p Parameters) DecryptionFailureProb(mulDepth int) float64 {
+
+	if mulDepth < 0 {
+		panic("cannot DecryptionFailureProb: mulDepth must be non-negative")
+	}
+
+	// The variances are computed with big.Float as (T/Q)^2 does not fit a float64 for large Q.
+	const prec = 128
+	newFloat := func(x float64) *big.Float { return new(big.Float).SetPrec(prec).SetFloat64(x) }
+
+	N, h, sigma2 := newFloat(float64(p.N())), newFloat(float64(p.HammingWeight())), newFloat(p.Sigma()*p.Sigma())
+	t := newFloat(float64(p.T()))
+	twelve := newFloat(12)
+
+	// (T/Q)^2
+	tOverQ2 := new(big.Float).SetPrec(prec).Quo(t, new(big.Float).SetPrec(prec).SetInt(p.RingQ().ModulusBigint))
+	tOverQ2.Mul(tOverQ2, tOverQ2)
+
+	// fresh encryption
+	v := newFloat(1)
+	v.Add(v, new(big.Float).Mul(newFloat(2), h))
+	v.Mul(v, sigma2)
+	v.Mul(v, tOverQ2)
+
+	// rounding error of the tensoring: (T/Q)^2 * (1 + h + h^2)/12
+	vRound := new(big.Float).SetPrec(prec).Mul(h, h)
+	vRound.Add(vRound, h)
+	vRound.Add(vRound, newFloat(1))
+	vRound.Quo(vRound, twelve)
+	vRound.Mul(vRound, tOverQ2)
+
+	// 2N * T^2/12, the growth factor of the noise multiplied by the plaintexts
+	growth := new(big.Float).SetPrec(prec).Mul(t, t)
+	growth.Mul(growth, N)
+	growth.Mul(growth, newFloat(2))
+	growth.Quo(growth, twelve)
+
+	vRelin := p.relinearizationNoiseVariance(prec, N, h, sigma2)
+	vRelin.Mul(vRelin, tOverQ2)
+
+	for i := 0; i < mulDepth; i++ {
+		v2 := new(big.Float).SetPrec(prec).Mul(v, v)
+		v2.Mul(v2, N)
+		v.Mul(v, growth)
+		v.Add(v, v2)
+		v.Add(v, vRound)
+		v.Add(v, vRelin)
+	}
+
+	// P[|e| >= 1/2] = erfc(1/(2*sqrt(2V))) for each coefficient, with a union bound over the N coefficients.
+	std, _ := new(big.Float).Sqrt(v.Mul(v, newFloat(2))).Float64()
+	if std == 0 {
+		return 0
+	}
+
+	return math.Min(1, float64(p.N())*math.Erfc(1/(2*std)))
+}
+
+// relinearizationNoiseVariance returns the variance of the coefficients of the noise added by a
+// key-switching, N * Sigma^2 * sum_j D_j^2/12 / P^2 + (1 + h)/12, with D_j the moduli of the digits
+// of the RNS decomposition.
+func (p Parameters) relinearizationNoiseVariance(prec uint, N, h, sigma2 *big.Float) *big.Float {
+
+	qi, pi := p.Q(), p.P()
+
+	alpha := utils.MaxInt(len(pi), 1)
+
+	P := new(big.Int).SetUint64(1)
+	for _, pj := range pi {
+		P.Mul(P, new(big.Int).SetUint64(pj))
+	}
+
+	sumD2 := new(big.Int)
+	for j := 0; j < len(qi); j += alpha {
+		D := new(big.Int).SetUint64(1)
+		for _, qj := range qi[j:utils.MinInt(j+alpha, len(qi))] {
+			D.Mul(D, new(big.Int).SetUint64(qj))
+		}
+		sumD2.Add(sumD2, D.Mul(D, D))
+	}
+
+	P2 := new(big.Float).SetPrec(prec).SetInt(P.Mul(P, P))
+
+	v := new(big.Float).SetPrec(prec).SetInt(sumD2)
+	v.Quo(v, P2)
+	v.Mul(v, N)
+	v.Mul(v, sigma2)
+	v.Quo(v, new(big.Float).SetPrec(prec).SetFloat64(12))
+
+	rounding := new(big.Float).SetPrec(prec).Add(h, new(big.Float).SetPrec(prec).SetFloat64(1))
+	rounding.Quo(rounding, new(big.Float).SetPrec(prec).SetFloat64(12))
+
+	return v.Add(v, rounding)
+}
+
+// RotationsForInnerSumLog generates the column rotations that will be performed by the
+// `Evaluator.InnerSumLog` operation when performed with parameters `batch` and `n`.
+func (p Parameters) RotationsForInnerSumLog(batch, n int) (rotations []int) {
+
+	rotIndex := make(map[int]bool)
+
+	for i := 1; i < n; i <<= 1 {
+		rotIndex[i*batch] = true
+		if k := n - (n & ((i << 1) - 1)); k != 0 {
+			rotIndex[k*batch] = true
+		}
+	}
+
+	rotations = make([]int, 0, len(rotIndex))
+	for k := range rotIndex {
+		rotations = append(rotations, k)
+	}
+
+	return
+}
+
+// GaloisElementsForPack returns the Galois elements of the column rotations required by
+// Evaluator.Pack to pack nbCiphertexts ciphertexts of n slots per row each.
+func (p Parameters) GaloisElementsForPack(nbCiphertexts, n int) (galEls []uint64) {
+	for stride := n; stride < nbCiphertexts*n; stride <<= 1 {
+		galEls = append(galEls, p.GaloisElementForColumnRotationBy(-stride))
+	}
+	return
+}
+
 // Equals compares two sets of parameters for equality.
 func (p Parameters) Equals(other Parameters) bool {
 	res := p.Parameters.Equals(other.Parameters)
 	res = res && (p.T() == other.T())
+	res = res && (p.scheme == other.scheme)
 	return res
 }
 
+// CompatiblePrefix returns the number of leading moduli of Q shared by the receiver and other,
+// and whether both parameter sets have the same ring degree N and plaintext modulus T.
+// Ciphertexts of the two parameter sets can be level-aligned up to the level prefix-1.
+func (p Parameters) CompatiblePrefix(other Parameters) (prefix int, ok bool) {
+	qi, qj := p.Q(), other.Q()
+	for prefix < utils.MinInt(len(qi), len(qj)) && qi[prefix] == qj[prefix] {
+		prefix++
+	}
+	return prefix, p.N() == other.N() && p.T() == other.T()
+}
+
 // CopyNew makes a deep copy of the receiver and returns it.
 //
 // Deprecated: Parameter is now a read-only struct, except for the UnmarshalBinary method: deep copying should only be
@@ -208,8 +581,10 @@ func (p Parameters) MarshalBinary() ([]byte, error) {
 
 	// len(rlweBytes) : RLWE parameters
 	// 8 byte : T
-	var tBytes [8]byte
-	binary.BigEndian.PutUint64(tBytes[:], p.T())
+	// 1 byte : Scheme
+	var tBytes [9]byte
+	binary.BigEndian.PutUint64(tBytes[:8], p.T())
+	tBytes[8] = uint8(p.scheme)
 	data := append(rlweBytes, tBytes[:]...)
 	return data, nil
 }
@@ -219,33 +594,43 @@ func (p *Parameters) UnmarshalBinary(data []byte) (err error) {
 	if err := p.Parameters.UnmarshalBinary(data); err != nil {
 		return err
 	}
-	dataBfv := data[len(data)-8:]
+	dataBfv := data[len(data)-9:]
 
 	nbQiMul := int(math.Ceil(float64(p.RingQ().ModulusBigint.BitLen()+p.LogN()) / 61.0))
 	if p.ringQMul, err = ring.NewRing(p.N(), ring.GenerateNTTPrimesP(61, 2*p.N(), nbQiMul)); err != nil {
 		return err
 	}
 
-	if p.ringT, err = ring.NewRing(p.N(), []uint64{binary.BigEndian.Uint64(dataBfv)}); err != nil {
+	if p.ringT, err = ring.NewRing(p.N(), []uint64{binary.BigEndian.Uint64(dataBfv[:8])}); err != nil {
 		return err
 	}
+
+	p.scheme = Scheme(dataBfv[8])
+	if err = checkScheme(p.scheme, p.T(), p.Q()); err != nil {
+		return err
+	}
+
+	p.delta, p.deltaQi = newDelta(p.RingQ(), p.T())
+
 	return nil
 }
 
 // MarshalBinarySize returns the length of the []byte encoding of the reciever.
 func (p Parameters) MarshalBinarySize() int {
-	return p.Parameters.MarshalBinarySize() + 8
+	return p.Parameters.MarshalBinarySize() + 9
 }
 
 // MarshalJSON returns a JSON representation of this parameter set. See `Marshal` from the `encoding/json` package.
 func (p Parameters) MarshalJSON() ([]byte, error) {
-	return json.Marshal(ParametersLiteral{LogN: p.LogN(), Q: p.Q(), P: p.P(), H: p.HammingWeight(), Sigma: p.Sigma(), T: p.T()})
+	return json.Marshal(ParametersLiteral{LogN: p.LogN(), Q: p.Q(), P: p.P(), H: p.HammingWeight(), Sigma: p.Sigma(), ErrorDistribution: p.ErrorDistribution(), GaussianTailFactor: p.GaussianTailFactor(), SecurityLevel: p.SecurityLevel(), T: p.T(), Scheme: p.scheme})
 }
 
 // UnmarshalJSON reads a JSON representation of a parameter set into the receiver Parameter. See `Unmarshal` from the `encoding/json` package.
 func (p *Parameters) UnmarshalJSON(data []byte) (err error) {
 	var params ParametersLiteral
-	json.Unmarshal(data, &params)
+	if err = json.Unmarshal(data, &params); err != nil {
+		return err
+	}
 	*p, err = NewParametersFromLiteral(params)
 	return
 }
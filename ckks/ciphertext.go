@@ -16,12 +16,10 @@ type Ciphertext struct {
 	Scale float64
 }
 
-// NewCiphertext creates a new Ciphertext parameterized by degree, level and scale.
+// NewCiphertext creates a new Ciphertext parameterized by degree, level and scale, in the NTT domain
+// expected by the CKKS Encryptor and Evaluator.
 func NewCiphertext(params Parameters, degree, level int, scale float64) (ciphertext *Ciphertext) {
-	ciphertext = &Ciphertext{Ciphertext: rlwe.NewCiphertext(params.Parameters, degree, level)}
-	for _, pol := range ciphertext.Value {
-		pol.IsNTT = true
-	}
+	ciphertext = &Ciphertext{Ciphertext: params.Parameters.NewCiphertextNTT(degree, level)}
 	ciphertext.Scale = scale
 	return ciphertext
 }
@@ -55,6 +53,37 @@ func (ct *Ciphertext) SetScalingFactor(scale float64) {
 	ct.Scale = scale
 }
 
+// RemainingMuls returns the number of successive multiplications that ct can undergo before exhausting
+// its modulus chain, where each multiplication is by an operand at params.DefaultScale() and is followed
+// by a Rescale with params.DefaultScale() as minScale. It replays the scale management of Rescale on the
+// moduli of params, so a multiplication may consume several levels if the moduli are smaller than the scale.
+func (ct *Ciphertext) RemainingMuls(params Parameters) (muls int) {
+
+	Q := params.Q()
+	minScale := params.DefaultScale()
+	level, scale := ct.Level(), ct.Scale
+
+	for level > 0 {
+
+		scale *= minScale
+
+		var nbRescales int
+		for level-nbRescales > 0 && scale/float64(Q[level-nbRescales]) >= minScale/2 {
+			scale /= float64(Q[level-nbRescales])
+			nbRescales++
+		}
+
+		if nbRescales == 0 {
+			break
+		}
+
+		level -= nbRescales
+		muls++
+	}
+
+	return
+}
+
 // Copy copies the given ciphertext ctp into the receiver ciphertext.
 func (ct *Ciphertext) Copy(ctp *Ciphertext) {
 	ct.Ciphertext.Copy(ctp.Ciphertext)
@@ -185,6 +185,12 @@ func benchEvaluator(tc *testContext, b *testing.B) {
 		}
 	})
 
+	b.Run(GetTestName(tc.params, "Evaluator/SquareMethod"), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			eval.Square(ciphertext1, receiver)
+		}
+	})
+
 	b.Run(GetTestName(tc.params, "Evaluator/Rescale"), func(b *testing.B) {
 
 		if tc.params.PCount() == 0 {
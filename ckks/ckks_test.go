@@ -277,6 +277,32 @@ func testEncoder(tc *testContext, t *testing.T) {
 		require.GreaterOrEqual(t, math.Log2(1/meanprec), minPrec)
 	})
 
+	t.Run(GetTestName(tc.params, "Encoder/EncodePrecision"), func(t *testing.T) {
+
+		values, _, _ := newTestVectors(tc, nil, complex(-1, -1), complex(1, 1), t)
+
+		scale := tc.params.DefaultScale()
+
+		bitsReal, bitsImag := tc.encoder.EncodePrecision(values, scale)
+
+		// the encoding error is of the order of sqrt(N)/scale
+		minPrec := math.Log2(scale) - float64(tc.params.LogN())
+		require.GreaterOrEqual(t, bitsReal, minPrec)
+		require.LessOrEqual(t, bitsReal, math.Log2(scale)+1)
+
+		require.GreaterOrEqual(t, bitsImag, minPrec)
+
+		// the precision grows with the scale
+		bitsRealLow, _ := tc.encoder.EncodePrecision(values, scale/(1<<10))
+		require.InDelta(t, bitsReal-10, bitsRealLow, 3)
+
+		bitsReal, bitsImag = tc.encoder.EncodePrecision(make([]complex128, 4), scale)
+		require.True(t, math.IsInf(bitsReal, 1) || bitsReal > minPrec)
+		require.True(t, math.IsInf(bitsImag, 1) || bitsImag > minPrec)
+
+		require.Panics(t, func() { tc.encoder.EncodePrecision(nil, scale) })
+	})
+
 }
 
 func testEvaluatorAdd(tc *testContext, t *testing.T) {
@@ -473,6 +499,28 @@ func testEvaluatorRescale(tc *testContext, t *testing.T) {
 
 		verifyTestVectors(tc.params, tc.encoder, tc.decryptor, values, ciphertext, tc.params.LogSlots(), 0, t)
 	})
+
+	t.Run(GetTestName(tc.params, "Evaluator/Rescale/RemainingMuls"), func(t *testing.T) {
+
+		values, _, ciphertext := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+
+		remainingMuls := ciphertext.RemainingMuls(tc.params)
+
+		var muls int
+		for ciphertext.Level() > 0 {
+			level := ciphertext.Level()
+			plaintext := tc.encoder.EncodeNew(values, level, tc.params.DefaultScale(), tc.params.LogSlots())
+			tc.evaluator.Mul(ciphertext, plaintext, ciphertext)
+			require.NoError(t, tc.evaluator.Rescale(ciphertext, tc.params.DefaultScale(), ciphertext))
+			if ciphertext.Level() == level {
+				break
+			}
+			muls++
+			require.Equal(t, remainingMuls-muls, ciphertext.RemainingMuls(tc.params))
+		}
+
+		require.Equal(t, remainingMuls, muls)
+	})
 }
 
 func testEvaluatorAddConst(tc *testContext, t *testing.T) {
@@ -641,6 +689,29 @@ func testEvaluatorMul(tc *testContext, t *testing.T) {
 		verifyTestVectors(tc.params, tc.encoder, tc.decryptor, values1, ciphertext2, tc.params.LogSlots(), 0, t)
 	})
 
+	t.Run(GetTestName(tc.params, "Evaluator/Square"), func(t *testing.T) {
+
+		values1, _, ciphertext1 := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+
+		for i := range values1 {
+			values1[i] *= values1[i]
+		}
+
+		ciphertext2 := tc.evaluator.SquareNew(ciphertext1)
+		require.Equal(t, 2, ciphertext2.Degree())
+		require.Equal(t, ciphertext1.Scale*ciphertext1.Scale, ciphertext2.Scale)
+
+		verifyTestVectors(tc.params, tc.encoder, tc.decryptor, values1, ciphertext2, tc.params.LogSlots(), 0, t)
+
+		tc.evaluator.Square(ciphertext1, ciphertext1)
+		require.Equal(t, 2, ciphertext1.Degree())
+		for i := range ciphertext1.Value {
+			require.True(t, tc.ringQ.EqualLvl(ciphertext1.Level(), ciphertext1.Value[i], ciphertext2.Value[i]))
+		}
+
+		require.Panics(t, func() { tc.evaluator.Square(ciphertext1, ciphertext2) })
+	})
+
 	t.Run(GetTestName(tc.params, "Evaluator/Mul/Relinearize(ct0*ct1->ct0)"), func(t *testing.T) {
 
 		if tc.params.PCount() == 0 {
@@ -1025,6 +1096,68 @@ func testSwitchKeys(tc *testContext, t *testing.T) {
 
 		verifyTestVectors(tc.params, tc.encoder, decryptorSk2, values, ciphertext, tc.params.LogSlots(), 0, t)
 	})
+
+	t.Run(GetTestName(tc.params, "SwitchKeys/SparseToDense"), func(t *testing.T) {
+
+		if tc.params.PCount() == 0 {
+			t.Skip("method is unsuported when params.PCount() == 0")
+		}
+
+		skSparse := tc.kgen.GenSecretKeyWithHammingWeight(64)
+		swkSparseToDense := tc.kgen.GenSwitchingKey(skSparse, tc.sk)
+
+		values, _, ciphertext := newTestVectors(tc, NewEncryptor(tc.params, skSparse), complex(-1, -1), complex(1, 1), t)
+
+		tc.evaluator.SwitchKeys(ciphertext, swkSparseToDense, ciphertext)
+
+		verifyTestVectors(tc.params, tc.encoder, tc.decryptor, values, ciphertext, tc.params.LogSlots(), 0, t)
+	})
+
+	t.Run(GetTestName(tc.params, "RingSwitch"), func(t *testing.T) {
+
+		if tc.params.PCount() == 0 {
+			t.Skip("method is unsuported when params.PCount() == 0")
+		}
+
+		if tc.params.RingType() != ring.Standard {
+			t.Skip("only tested for params.RingType() == ring.Standard")
+		}
+
+		logSlots := tc.params.LogN() - 2
+
+		paramsSmall, err := NewParametersFromLiteral(ParametersLiteral{
+			LogN:         tc.params.LogN() - 1,
+			Q:            tc.params.Q(),
+			P:            tc.params.P(),
+			H:            tc.params.HammingWeight(),
+			Sigma:        tc.params.Sigma(),
+			LogSlots:     logSlots,
+			DefaultScale: tc.params.DefaultScale(),
+		})
+		require.NoError(t, err)
+
+		skSmall := NewKeyGenerator(paramsSmall).GenSecretKey()
+		encoderSmall := NewEncoder(paramsSmall)
+		decryptorSmall := NewDecryptor(paramsSmall, skSmall)
+
+		values := make([]complex128, 1<<logSlots)
+		for i := range values {
+			values[i] = complex(utils.RandFloat64(-1, 1), utils.RandFloat64(-1, 1))
+		}
+
+		plaintext := tc.encoder.EncodeNew(values, tc.params.MaxLevel(), tc.params.DefaultScale(), logSlots)
+		ciphertext := tc.encryptorSk.EncryptNew(plaintext)
+
+		ctSmall := NewCiphertext(paramsSmall, 1, ciphertext.Level(), ciphertext.Scale)
+		tc.evaluator.RingSwitch(ciphertext, paramsSmall, tc.kgen.GenSwitchingKey(tc.sk, skSmall), ctSmall)
+
+		verifyTestVectors(paramsSmall, encoderSmall, decryptorSmall, values, ctSmall, logSlots, 0, t)
+
+		ctLarge := NewCiphertext(tc.params, 1, ctSmall.Level(), ctSmall.Scale)
+		tc.evaluator.RingSwitch(ctSmall, tc.params, tc.kgen.GenSwitchingKey(skSmall, tc.sk), ctLarge)
+
+		verifyTestVectors(tc.params, tc.encoder, tc.decryptor, values, ctLarge, logSlots, 0, t)
+	})
 }
 
 func testBridge(tc *testContext, t *testing.T) {
@@ -1160,6 +1293,64 @@ func testAutomorphisms(tc *testContext, t *testing.T) {
 			verifyTestVectors(tc.params, tc.encoder, tc.decryptor, utils.RotateComplex128Slice(values1, n), ciphertexts[n], tc.params.LogSlots(), 0, t)
 		}
 	})
+
+	t.Run(GetTestName(tc.params, "PermuteSlots"), func(t *testing.T) {
+
+		if params.PCount() == 0 {
+			t.Skip("method is unsuported when params.PCount() == 0")
+		}
+
+		slots := params.Slots()
+
+		values1, _, ciphertext1 := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+		ciphertext2 := NewCiphertext(params, ciphertext1.Degree(), ciphertext1.Level(), ciphertext1.Scale)
+
+		perm := make([]int, slots)
+		for i := range perm {
+			perm[i] = (i + 4) % slots
+		}
+
+		require.NoError(t, evaluator.PermuteSlots(ciphertext1, perm, ciphertext2))
+		verifyTestVectors(params, tc.encoder, tc.decryptor, utils.RotateComplex128Slice(values1, 4), ciphertext2, params.LogSlots(), 0, t)
+
+		perm[0], perm[1] = perm[1], perm[0]
+		require.Error(t, evaluator.PermuteSlots(ciphertext1, perm, ciphertext2))
+
+		for i := range perm {
+			perm[i] = (i + 3) % slots
+		}
+		require.Error(t, evaluator.PermuteSlots(ciphertext1, perm, ciphertext2))
+		require.Error(t, evaluator.PermuteSlots(ciphertext1, perm[1:], ciphertext2))
+
+		// indices out of range
+		for i := range perm {
+			perm[i] = i - 1
+		}
+		require.Error(t, evaluator.PermuteSlots(ciphertext1, perm, ciphertext2))
+
+		// evaluator without rotation keys
+		for i := range perm {
+			perm[i] = (i + 4) % slots
+		}
+		require.Error(t, evaluator.WithKey(rlwe.EvaluationKey{Rlk: tc.rlk}).PermuteSlots(ciphertext1, perm, ciphertext2))
+	})
+
+	t.Run(GetTestName(tc.params, "RotateSteps"), func(t *testing.T) {
+
+		if params.PCount() == 0 {
+			t.Skip("method is unsuported when params.PCount() == 0")
+		}
+
+		steps := []int{5, -5, 5}
+		evaluator := tc.evaluator.WithKey(rlwe.EvaluationKey{Rlk: tc.rlk, Rtks: tc.kgen.GenRotationKeysForSteps(tc.sk, steps)})
+
+		values1, _, ciphertext1 := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+
+		evaluator.Rotate(ciphertext1, steps[0], ciphertext1)
+		evaluator.Rotate(ciphertext1, steps[1], ciphertext1)
+
+		verifyTestVectors(tc.params, tc.encoder, tc.decryptor, values1, ciphertext1, tc.params.LogSlots(), 0, t)
+	})
 }
 
 func testInnerSum(tc *testContext, t *testing.T) {
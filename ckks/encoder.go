@@ -7,6 +7,7 @@ import (
 	"math"
 	"math/big"
 	"math/bits"
+	"math/cmplx"
 
 	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/rlwe"
@@ -64,6 +65,7 @@ type Encoder interface {
 	Embed(values interface{}, logSlots int, scale float64, montgomery bool, polyOut interface{})
 	GetErrSTDCoeffDomain(valuesWant, valuesHave []complex128, scale float64) (std float64)
 	GetErrSTDSlotDomain(valuesWant, valuesHave []complex128, scale float64) (std float64)
+	EncodePrecision(values []complex128, scale float64) (bitsReal, bitsImag float64)
 	ShallowCopy() Encoder
 }
 
@@ -105,7 +107,7 @@ func (ecd *encoder) ShallowCopy() *encoder {
 		polypool:        ecd.params.RingQ().NewPoly(),
 		m:               ecd.m,
 		rotGroup:        ecd.rotGroup,
-		gaussianSampler: ring.NewGaussianSampler(prng, ecd.params.RingQ(), ecd.params.Sigma(), int(6*ecd.params.Sigma())),
+		gaussianSampler: ring.NewGaussianSampler(prng, ecd.params.RingQ(), ecd.params.Sigma(), ecd.params.ErrorBound()),
 	}
 }
 
@@ -126,7 +128,7 @@ func newEncoder(params Parameters) encoder {
 		panic(err)
 	}
 
-	gaussianSampler := ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), int(6*params.Sigma()))
+	gaussianSampler := ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), params.ErrorBound())
 
 	return encoder{
 		params:          params,
@@ -297,6 +299,38 @@ func (ecd *encoderComplex128) GetErrSTDCoeffDomain(valuesWant, valuesHave []comp
 	return StandardDeviation(ecd.valuesFloat[:len(valuesWant)*2], scale)
 }
 
+// EncodePrecision encodes values at the maximum level and at the given scale on the default number of slots of the
+// parameters (padding values with zeros), decodes the result and returns the precision in bits achieved on the real
+// and imaginary parts, that is log2(max|values|/maxErr) where maxErr is the largest absolute error of the component
+// over all the values. If all the values are zero, the precision is absolute, i.e. log2(1/maxErr).
+// An exact round-trip yields +Inf.
+// The procedure will panic if len(values) is zero or larger than the number of slots.
+func (ecd *encoderComplex128) EncodePrecision(values []complex128, scale float64) (bitsReal, bitsImag float64) {
+
+	if len(values) == 0 || len(values) > ecd.params.Slots() {
+		panic(fmt.Errorf("cannot EncodePrecision: len(values) must be between 1 and %d", ecd.params.Slots()))
+	}
+
+	logSlots := ecd.params.LogSlots()
+
+	plaintext := NewPlaintext(ecd.params, ecd.params.MaxLevel(), scale)
+	ecd.Encode(values, plaintext, logSlots)
+	valuesHave := ecd.Decode(plaintext, logSlots)
+
+	var norm, errReal, errImag float64
+	for i := range values {
+		norm = math.Max(norm, cmplx.Abs(values[i]))
+		errReal = math.Max(errReal, math.Abs(real(valuesHave[i])-real(values[i])))
+		errImag = math.Max(errImag, math.Abs(imag(valuesHave[i])-imag(values[i])))
+	}
+
+	if norm == 0 {
+		norm = 1
+	}
+
+	return math.Log2(norm / errReal), math.Log2(norm / errImag)
+}
+
 // GetErrSTDSlotDomain returns StandardDeviation(valuesWant-valuesHave)*scale
 // which is the scaled standard deviation of two complex vectors.
 func (ecd *encoderComplex128) GetErrSTDSlotDomain(valuesWant, valuesHave []complex128, scale float64) (std float64) {
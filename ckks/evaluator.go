@@ -69,6 +69,8 @@ type Evaluator interface {
 	MulNew(op0, op1 Operand) (ctOut *Ciphertext)
 	MulRelin(op0, op1 Operand, ctOut *Ciphertext)
 	MulRelinNew(op0, op1 Operand) (ctOut *Ciphertext)
+	Square(ctIn *Ciphertext, ctOut *Ciphertext)
+	SquareNew(ctIn *Ciphertext) (ctOut *Ciphertext)
 
 	MulAndAdd(op0, op1 Operand, ctOut *Ciphertext)
 	MulRelinAndAdd(op0, op1 Operand, ctOut *Ciphertext)
@@ -76,6 +78,7 @@ type Evaluator interface {
 	// Slot Rotations
 	RotateNew(ctIn *Ciphertext, k int) (ctOut *Ciphertext)
 	Rotate(ctIn *Ciphertext, k int, ctOut *Ciphertext)
+	PermuteSlots(ctIn *Ciphertext, perm []int, ctOut *Ciphertext) (err error)
 	RotateHoistedNew(ctIn *Ciphertext, rotations []int) (ctOut map[int]*Ciphertext)
 	RotateHoisted(ctIn *Ciphertext, rotations []int, ctOut map[int]*Ciphertext)
 	RotateHoistedNoModDownNew(level int, rotations []int, c0 *ring.Poly, c2DecompQP []rlwe.PolyQP) (cOut map[int][2]rlwe.PolyQP)
@@ -128,6 +131,7 @@ type Evaluator interface {
 	// Key-Switching
 	SwitchKeysNew(ctIn *Ciphertext, switchingKey *rlwe.SwitchingKey) (ctOut *Ciphertext)
 	SwitchKeys(ctIn *Ciphertext, switchingKey *rlwe.SwitchingKey, ctOut *Ciphertext)
+	RingSwitch(ctIn *Ciphertext, targetParams Parameters, switchingKey *rlwe.SwitchingKey, ctOut *Ciphertext)
 
 	// Degree Management
 	RelinearizeNew(ctIn *Ciphertext) (ctOut *Ciphertext)
@@ -1157,6 +1161,27 @@ func (eval *evaluator) MulRelin(op0, op1 Operand, ctOut *Ciphertext) {
 	eval.mulRelin(op0, op1, true, ctOut)
 }
 
+// SquareNew squares ctIn without relinearization and returns the result in a newly created element of degree 2.
+// The procedure will panic if ctIn.Degree != 1.
+func (eval *evaluator) SquareNew(ctIn *Ciphertext) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(eval.params, 2, ctIn.Level(), 0)
+	eval.Square(ctIn, ctOut)
+	return
+}
+
+// Square squares ctIn without relinearization and returns the result in ctOut.
+// It is equivalent to Mul(ctIn, ctIn, ctOut), which already uses the symmetry of the product in the tensoring.
+// The output is of degree 2 and can be relinearized.
+// The procedure will panic if ctIn.Degree != 1.
+func (eval *evaluator) Square(ctIn *Ciphertext, ctOut *Ciphertext) {
+
+	if ctIn.Degree() != 1 {
+		panic("cannot Square: input ciphertext must be of degree 1")
+	}
+
+	eval.mulRelin(ctIn, ctIn, false, ctOut)
+}
+
 func (eval *evaluator) mulRelin(op0, op1 Operand, relin bool, ctOut *Ciphertext) {
 
 	eval.checkBinary(op0, op1, ctOut, utils.MaxInt(op0.Degree(), op1.Degree()))
@@ -1394,6 +1419,8 @@ func (eval *evaluator) SwitchKeysNew(ct0 *Ciphertext, switchingKey *rlwe.Switchi
 // SwitchKeys re-encrypts ct0 under a different key and returns the result in ctOut.
 // It requires a SwitchingKey, which is computed from the key under which the Ciphertext is currently encrypted,
 // and the key under which the Ciphertext will be re-encrypted.
+// For example, a ciphertext encrypted under a sparse secret-key, such as the ones used for the bootstrapping, is
+// re-encrypted under a dense secret-key with the SwitchingKey generated by KeyGenerator.GenSwitchingKey(skSparse, skDense).
 func (eval *evaluator) SwitchKeys(ct0 *Ciphertext, switchingKey *rlwe.SwitchingKey, ctOut *Ciphertext) {
 
 	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
@@ -1411,6 +1438,72 @@ func (eval *evaluator) SwitchKeys(ct0 *Ciphertext, switchingKey *rlwe.SwitchingK
 	ring.CopyValuesLvl(level, eval.Pool[2].Q, ctOut.Value[1])
 }
 
+// RingSwitch switches the ring degree of ct0 to the one of targetParams and returns the result in ctOut.
+// The Evaluator must be instantiated with the parameters of the larger ring degree, and the moduli of targetParams
+// must match the ones of the Evaluator up to the level of ctOut.
+// If the degree of targetParams is smaller, the switchingKey must be generated with the KeyGenerator of the larger
+// parameters as GenSwitchingKey(skLarge, skSmall) and the message of ct0 must lie in the subring Y = X^{N/n}, which
+// holds if ct0 encodes at most n/2 slots. The slots of ct0 are preserved in ctOut.
+// If the degree of targetParams is larger, the switchingKey must be generated as GenSwitchingKey(skSmall, skLarge).
+func (eval *evaluator) RingSwitch(ct0 *Ciphertext, targetParams Parameters, switchingKey *rlwe.SwitchingKey, ctOut *Ciphertext) {
+
+	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot RingSwitch: input and output Ciphertext must be of degree 1")
+	}
+
+	N := eval.params.N()
+	NIn, NOut := len(ct0.Value[0].Coeffs[0]), len(ctOut.Value[0].Coeffs[0])
+
+	if NOut != targetParams.N() {
+		panic("cannot RingSwitch: ctOut ring degree does not match targetParams")
+	}
+
+	if targetParams.RingType() != eval.params.RingType() {
+		panic("cannot RingSwitch: targetParams ring type does not match the Evaluator")
+	}
+
+	if utils.MaxInt(NIn, NOut) != N || NIn == NOut {
+		panic("cannot RingSwitch: the Evaluator must be instantiated with the parameters of the larger ring degree")
+	}
+
+	level := utils.MinInt(ct0.Level(), ctOut.Level())
+
+	for i := 0; i < level+1; i++ {
+		if targetParams.Q()[i] != eval.params.Q()[i] {
+			panic("cannot RingSwitch: targetParams moduli do not match the Evaluator moduli")
+		}
+	}
+
+	ringQ := eval.params.RingQ()
+
+	ctOut.Scale = ct0.Scale
+
+	if NIn > NOut {
+
+		eval.SwitchKeysInPlace(level, ct0.Value[1], switchingKey, eval.Pool[1].Q, eval.Pool[2].Q)
+		ringQ.AddLvl(level, ct0.Value[0], eval.Pool[1].Q, eval.Pool[1].Q)
+
+		ctTmp := &rlwe.Ciphertext{Value: []*ring.Poly{eval.Pool[1].Q, eval.Pool[2].Q}}
+		ctOutLvl := &rlwe.Ciphertext{Value: []*ring.Poly{
+			{Coeffs: ctOut.Value[0].Coeffs[:level+1]},
+			{Coeffs: ctOut.Value[1].Coeffs[:level+1]},
+		}}
+
+		rlwe.SwitchCiphertextRingDegreeNTT(ctTmp, targetParams.RingQ(), ringQ, ctOutLvl)
+
+	} else {
+
+		for i := range ctOut.Value {
+			ring.MapSmallDimensionToLargerDimensionNTT(&ring.Poly{Coeffs: ct0.Value[i].Coeffs[:level+1]}, ctOut.Value[i])
+		}
+
+		eval.SwitchKeysInPlace(level, ctOut.Value[1], switchingKey, eval.Pool[1].Q, eval.Pool[2].Q)
+
+		ringQ.AddLvl(level, ctOut.Value[0], eval.Pool[1].Q, ctOut.Value[0])
+		ring.CopyValuesLvl(level, eval.Pool[2].Q, ctOut.Value[1])
+	}
+}
+
 // RotateNew rotates the columns of ct0 by k positions to the left, and returns the result in a newly created element.
 // If the provided element is a Ciphertext, a key-switching operation is necessary and a rotation key for the specific rotation needs to be provided.
 func (eval *evaluator) RotateNew(ct0 *Ciphertext, k int) (ctOut *Ciphertext) {
@@ -1439,6 +1532,46 @@ func (eval *evaluator) Rotate(ct0 *Ciphertext, k int, ctOut *Ciphertext) {
 	}
 }
 
+// PermuteSlots applies the slot permutation perm on ct0 and returns the result in ctOut, such that the i-th slot
+// of ctOut is the perm[i]-th slot of ct0. The permutation must be realizable by a single automorphism X -> X^{5^k},
+// i.e. be a cyclic rotation perm[i] = (i + k) mod params.Slots(), and the rotation key for k must be provided.
+// An error is returned if perm is not a list of params.Slots() indices in [0, params.Slots()), if it is not of
+// this form, or if the rotation key is not available.
+func (eval *evaluator) PermuteSlots(ct0 *Ciphertext, perm []int, ctOut *Ciphertext) (err error) {
+
+	slots := eval.params.Slots()
+
+	if len(perm) != slots {
+		return fmt.Errorf("cannot PermuteSlots: len(perm)=%d does not match the number of slots %d", len(perm), slots)
+	}
+
+	for i, idx := range perm {
+		if idx < 0 || idx >= slots {
+			return fmt.Errorf("cannot PermuteSlots: perm[%d]=%d is not in [0, %d)", i, idx, slots)
+		}
+	}
+
+	k := perm[0]
+	for i := range perm {
+		if perm[i] != (i+k)%slots {
+			return errors.New("cannot PermuteSlots: perm is not realizable by a single automorphism")
+		}
+	}
+
+	if k != 0 {
+		if eval.rtks == nil {
+			return errors.New("cannot PermuteSlots: the evaluator has no rotation keys")
+		}
+		if _, generated := eval.rtks.GetRotationKey(eval.params.GaloisElementForColumnRotationBy(k)); !generated {
+			return fmt.Errorf("cannot PermuteSlots: rotation key k=%d not available", k)
+		}
+	}
+
+	eval.Rotate(ct0, k, ctOut)
+
+	return nil
+}
+
 // ConjugateNew conjugates ct0 (which is equivalent to a row rotation) and returns the result in a newly
 // created element. If the provided element is a Ciphertext, a key-switching operation is necessary and a rotation key
 // for the row rotation needs to be provided.
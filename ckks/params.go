@@ -24,10 +24,11 @@ var (
 		LogSlots: 11,
 		Q: []uint64{0x200000e001, // 37 + 32
 			0x100006001},
-		P:            []uint64{0x3ffffea001}, // 38
-		DefaultScale: 1 << 32,
-		Sigma:        rlwe.DefaultSigma,
-		RingType:     ring.Standard,
+		P:             []uint64{0x3ffffea001}, // 38
+		DefaultScale:  1 << 32,
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
+		RingType:      ring.Standard,
 	}
 
 	// PN13QP218 is a default parameter set for logN=13 and logQP=218
@@ -40,10 +41,11 @@ var (
 			0x40020001,
 			0x40038001,
 			0x3ffc0001},
-		P:            []uint64{0x800004001}, // 35
-		DefaultScale: 1 << 30,
-		Sigma:        rlwe.DefaultSigma,
-		RingType:     ring.Standard,
+		P:             []uint64{0x800004001}, // 35
+		DefaultScale:  1 << 30,
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
+		RingType:      ring.Standard,
 	}
 	// PN14QP438 is a default parameter set for logN=14 and logQP=438
 	PN14QP438 = ParametersLiteral{
@@ -54,10 +56,11 @@ var (
 			0x400068001, 0x3fff90001,
 			0x400080001, 0x4000a8001,
 			0x400108001, 0x3ffeb8001},
-		P:            []uint64{0x7fffffd8001, 0x7fffffc8001}, // 43, 43
-		DefaultScale: 1 << 34,
-		Sigma:        rlwe.DefaultSigma,
-		RingType:     ring.Standard,
+		P:             []uint64{0x7fffffd8001, 0x7fffffc8001}, // 43, 43
+		DefaultScale:  1 << 34,
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
+		RingType:      ring.Standard,
 	}
 
 	// PN15QP880 is a default parameter set for logN=15 and logQP=880
@@ -70,10 +73,11 @@ var (
 			0x10000500001, 0x10000650001, 0xffff940001,
 			0xffff8a0001, 0xffff820001, 0xffff780001,
 			0x10000890001, 0xffff750001, 0x10000960001},
-		P:            []uint64{0x40000001b0001, 0x3ffffffdf0001, 0x4000000270001}, // 50, 50, 50
-		DefaultScale: 1 << 40,
-		Sigma:        rlwe.DefaultSigma,
-		RingType:     ring.Standard,
+		P:             []uint64{0x40000001b0001, 0x3ffffffdf0001, 0x4000000270001}, // 50, 50, 50
+		DefaultScale:  1 << 40,
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
+		RingType:      ring.Standard,
 	}
 	// PN16QP1761 is a default parameter set for logN=16 and logQP = 1761
 	PN16QP1761 = ParametersLiteral{
@@ -88,10 +92,11 @@ var (
 			0x2000019a0001, 0x1ffffe640001, 0x200001a00001, 0x1ffffe520001,
 			0x200001e80001, 0x1ffffe0c0001, 0x1ffffdee0001, 0x200002480001,
 			0x1ffffdb60001, 0x200002560001},
-		P:            []uint64{0x80000000440001, 0x7fffffffba0001, 0x80000000500001, 0x7fffffffaa0001}, // 4 x 55
-		DefaultScale: 1 << 45,
-		Sigma:        rlwe.DefaultSigma,
-		RingType:     ring.Standard,
+		P:             []uint64{0x80000000440001, 0x7fffffffba0001, 0x80000000500001, 0x7fffffffaa0001}, // 4 x 55
+		DefaultScale:  1 << 45,
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
+		RingType:      ring.Standard,
 	}
 
 	// PN12QP109CI is a default parameter set for logN=12 and logQP=109
@@ -100,10 +105,11 @@ var (
 		LogSlots: 12,
 		Q: []uint64{0x1ffffe0001, // 37 + 32
 			0x100014001},
-		P:            []uint64{0x4000038001}, // 38
-		DefaultScale: 1 << 32,
-		Sigma:        rlwe.DefaultSigma,
-		RingType:     ring.ConjugateInvariant,
+		P:             []uint64{0x4000038001}, // 38
+		DefaultScale:  1 << 32,
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
+		RingType:      ring.ConjugateInvariant,
 	}
 
 	// PN13QP218CI is a default parameter set for logN=13 and logQP=218
@@ -116,10 +122,11 @@ var (
 			0x40038001,
 			0x3ffc0001,
 			0x40080001},
-		P:            []uint64{0x800008001}, // 35
-		DefaultScale: 1 << 30,
-		Sigma:        rlwe.DefaultSigma,
-		RingType:     ring.ConjugateInvariant,
+		P:             []uint64{0x800008001}, // 35
+		DefaultScale:  1 << 30,
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
+		RingType:      ring.ConjugateInvariant,
 	}
 	// PN14QP438CI is a default parameter set for logN=14 and logQP=438
 	PN14QP438CI = ParametersLiteral{
@@ -130,10 +137,11 @@ var (
 			0x400080001, 0x400180001,
 			0x3ffd20001, 0x400300001,
 			0x400360001, 0x4003e0001},
-		P:            []uint64{0x80000050001, 0x7ffffdb0001}, // 43, 43
-		DefaultScale: 1 << 34,
-		Sigma:        rlwe.DefaultSigma,
-		RingType:     ring.ConjugateInvariant,
+		P:             []uint64{0x80000050001, 0x7ffffdb0001}, // 43, 43
+		DefaultScale:  1 << 34,
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
+		RingType:      ring.ConjugateInvariant,
 	}
 
 	// PN15QP880CI is a default parameter set for logN=15 and logQP=880
@@ -147,10 +155,11 @@ var (
 			0xffff780001, 0x10000960001, 0x10000a40001,
 			0xffff580001, 0x10000b60001, 0xffff480001,
 			0xffff420001, 0xffff340001},
-		P:            []uint64{0x3ffffffd20001, 0x4000000420001, 0x3ffffffb80001}, // 50, 50, 50
-		DefaultScale: 1 << 40,
-		Sigma:        rlwe.DefaultSigma,
-		RingType:     ring.ConjugateInvariant,
+		P:             []uint64{0x3ffffffd20001, 0x4000000420001, 0x3ffffffb80001}, // 50, 50, 50
+		DefaultScale:  1 << 40,
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
+		RingType:      ring.ConjugateInvariant,
 	}
 	// PN16QP1761CI is a default parameter set for logN=16 and logQP = 1761
 	PN16QP1761CI = ParametersLiteral{
@@ -166,31 +175,34 @@ var (
 			0x1ffffc140001, 0x200004100001, 0x200004180001, 0x1ffffbc40001,
 			0x200004700001, 0x1ffffb900001, 0x200004cc0001, 0x1ffffb240001,
 			0x200004e80001},
-		P:            []uint64{0x80000000440001, 0x80000000500001, 0x7fffffff380001, 0x80000000e00001}, // 4 x 55
-		DefaultScale: 1 << 45,
-		Sigma:        rlwe.DefaultSigma,
-		RingType:     ring.ConjugateInvariant,
+		P:             []uint64{0x80000000440001, 0x80000000500001, 0x7fffffff380001, 0x80000000e00001}, // 4 x 55
+		DefaultScale:  1 << 45,
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
+		RingType:      ring.ConjugateInvariant,
 	}
 
 	// PN12QP101pq is a default (post quantum) parameter set for logN=12 and logQP=101
 	PN12QP101pq = ParametersLiteral{
-		LogN:         12,
-		LogSlots:     11,
-		Q:            []uint64{0x800004001, 0x40002001}, // 35 + 30
-		P:            []uint64{0x1000002001},            // 36
-		DefaultScale: 1 << 30,
-		Sigma:        rlwe.DefaultSigma,
-		RingType:     ring.Standard,
+		LogN:          12,
+		LogSlots:      11,
+		Q:             []uint64{0x800004001, 0x40002001}, // 35 + 30
+		P:             []uint64{0x1000002001},            // 36
+		DefaultScale:  1 << 30,
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
+		RingType:      ring.Standard,
 	}
 	// PN13QP202pq is a default (post quantum) parameter set for logN=13 and logQP=202
 	PN13QP202pq = ParametersLiteral{
-		LogN:         13,
-		LogSlots:     12,
-		Q:            []uint64{0x1fffec001, 0x8008001, 0x8020001, 0x802c001, 0x7fa8001, 0x7f74001}, // 33 + 5 x 27
-		P:            []uint64{0x400018001},                                                        // 34
-		DefaultScale: 1 << 27,
-		Sigma:        rlwe.DefaultSigma,
-		RingType:     ring.Standard,
+		LogN:          13,
+		LogSlots:      12,
+		Q:             []uint64{0x1fffec001, 0x8008001, 0x8020001, 0x802c001, 0x7fa8001, 0x7f74001}, // 33 + 5 x 27
+		P:             []uint64{0x400018001},                                                        // 34
+		DefaultScale:  1 << 27,
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
+		RingType:      ring.Standard,
 	}
 
 	// PN14QP411pq is a default (post quantum) parameter set for logN=14 and logQP=411
@@ -200,10 +212,11 @@ var (
 		Q: []uint64{0x10000048001, 0x200038001, 0x1fff90001, 0x200080001, 0x1fff60001,
 			0x2000b8001, 0x200100001, 0x1fff00001, 0x1ffef0001, 0x200128001}, // 40 + 9 x 33
 
-		P:            []uint64{0x1ffffe0001, 0x1ffffc0001}, // 37, 37
-		DefaultScale: 1 << 33,
-		Sigma:        rlwe.DefaultSigma,
-		RingType:     ring.Standard,
+		P:             []uint64{0x1ffffe0001, 0x1ffffc0001}, // 37, 37
+		DefaultScale:  1 << 33,
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
+		RingType:      ring.Standard,
 	}
 
 	// PN15QP827pq is a default (post quantum) parameter set for logN=15 and logQP=827
@@ -214,10 +227,11 @@ var (
 			0x3fffcf0001, 0x40003f0001, 0x3fffc10001, 0x4000450001, 0x3fffb80001,
 			0x3fffb70001, 0x40004a0001, 0x3fffb20001, 0x4000510001, 0x3fffaf0001,
 			0x4000540001, 0x4000560001, 0x4000590001}, // 46 + 17 x 38
-		P:            []uint64{0x2000000a0001, 0x2000000e0001, 0x2000001d0001}, // 3 x 45
-		DefaultScale: 1 << 38,
-		Sigma:        rlwe.DefaultSigma,
-		RingType:     ring.Standard,
+		P:             []uint64{0x2000000a0001, 0x2000000e0001, 0x2000001d0001}, // 3 x 45
+		DefaultScale:  1 << 38,
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
+		RingType:      ring.Standard,
 	}
 	// PN16QP1654pq is a default (post quantum) parameter set for logN=16 and logQP=1654
 	PN16QP1654pq = ParametersLiteral{LogN: 16,
@@ -229,31 +243,34 @@ var (
 			0x1ffffeca0001, 0x1ffffeb40001, 0x200001520001, 0x1ffffe760001, 0x2000019a0001,
 			0x1ffffe640001, 0x200001a00001, 0x1ffffe520001, 0x200001e80001, 0x1ffffe0c0001,
 			0x1ffffdee0001, 0x200002480001}, // 55 + 31 x 45
-		P:            []uint64{0x7fffffffe0001, 0x80000001c0001, 0x80000002c0001, 0x7ffffffd20001}, // 4 x 51
-		DefaultScale: 1 << 45,
-		Sigma:        rlwe.DefaultSigma,
-		RingType:     ring.Standard,
+		P:             []uint64{0x7fffffffe0001, 0x80000001c0001, 0x80000002c0001, 0x7ffffffd20001}, // 4 x 51
+		DefaultScale:  1 << 45,
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
+		RingType:      ring.Standard,
 	}
 
 	// PN12QP101pq is a default (post quantum) parameter set for logN=12 and logQP=101
 	PN12QP101CIpq = ParametersLiteral{
-		LogN:         12,
-		LogSlots:     12,
-		Q:            []uint64{0x800004001, 0x3fff4001}, // 35 + 30
-		P:            []uint64{0xffffc4001},             // 36
-		DefaultScale: 1 << 30,
-		Sigma:        rlwe.DefaultSigma,
-		RingType:     ring.ConjugateInvariant,
+		LogN:          12,
+		LogSlots:      12,
+		Q:             []uint64{0x800004001, 0x3fff4001}, // 35 + 30
+		P:             []uint64{0xffffc4001},             // 36
+		DefaultScale:  1 << 30,
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
+		RingType:      ring.ConjugateInvariant,
 	}
 	// PN13QP202CIpq is a default (post quantum) parameter set for logN=13 and logQP=202
 	PN13QP202CIpq = ParametersLiteral{
-		LogN:         13,
-		LogSlots:     13,
-		Q:            []uint64{0x1ffffe0001, 0x100050001, 0xfff88001, 0x100098001, 0x1000b0001}, // 37 + 4 x 32
-		P:            []uint64{0x1ffffc0001},                                                    // 37
-		DefaultScale: 1 << 32,
-		Sigma:        rlwe.DefaultSigma,
-		RingType:     ring.ConjugateInvariant,
+		LogN:          13,
+		LogSlots:      13,
+		Q:             []uint64{0x1ffffe0001, 0x100050001, 0xfff88001, 0x100098001, 0x1000b0001}, // 37 + 4 x 32
+		P:             []uint64{0x1ffffc0001},                                                    // 37
+		DefaultScale:  1 << 32,
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
+		RingType:      ring.ConjugateInvariant,
 	}
 
 	// PN14QP411CIpq is a default (post quantum) parameter set for logN=14 and logQP=411
@@ -265,10 +282,11 @@ var (
 			0x1ffef0001, 0x1ffe60001, 0x2001d0001,
 			0x2002e0001}, // 40 + 9 x 33
 
-		P:            []uint64{0x1ffffe0001, 0x1ffffc0001}, // 37, 37
-		DefaultScale: 1 << 33,
-		Sigma:        rlwe.DefaultSigma,
-		RingType:     ring.ConjugateInvariant,
+		P:             []uint64{0x1ffffe0001, 0x1ffffc0001}, // 37, 37
+		DefaultScale:  1 << 33,
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
+		RingType:      ring.ConjugateInvariant,
 	}
 
 	// PN15QP827CIpq is a default (post quantum) parameter set for logN=15 and logQP=827
@@ -280,10 +298,11 @@ var (
 			0x3fff900001, 0x4000720001, 0x3fff8e0001, 0x4000800001,
 			0x40008a0001, 0x3fff6c0001, 0x40009e0001, 0x3fff300001,
 			0x3fff1c0001, 0x4000fc0001}, // 46 + 17 x 38
-		P:            []uint64{0x2000000a0001, 0x2000000e0001, 0x1fffffc20001}, // 3 x 45
-		DefaultScale: 1 << 38,
-		Sigma:        rlwe.DefaultSigma,
-		RingType:     ring.ConjugateInvariant,
+		P:             []uint64{0x2000000a0001, 0x2000000e0001, 0x1fffffc20001}, // 3 x 45
+		DefaultScale:  1 << 38,
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
+		RingType:      ring.ConjugateInvariant,
 	}
 	// PN16QP1654CIpq is a default (post quantum) parameter set for logN=16 and logQP=1654
 	PN16QP1654CIpq = ParametersLiteral{LogN: 16,
@@ -296,10 +315,11 @@ var (
 			0x1ffffc980001, 0x200003740001, 0x200003800001, 0x200003d40001,
 			0x1ffffc200001, 0x1ffffc140001, 0x200004100001, 0x200004180001,
 			0x1ffffbc40001, 0x200004700001, 0x1ffffb900001, 0x200004cc0001}, // 55 + 31 x 45
-		P:            []uint64{0x80000001c0001, 0x80000002c0001, 0x8000000500001, 0x7ffffff9c0001}, // 4 x 51
-		DefaultScale: 1 << 45,
-		Sigma:        rlwe.DefaultSigma,
-		RingType:     ring.ConjugateInvariant,
+		P:             []uint64{0x80000001c0001, 0x80000002c0001, 0x8000000500001, 0x7ffffff9c0001}, // 4 x 51
+		DefaultScale:  1 << 45,
+		Sigma:         rlwe.DefaultSigma,
+		SecurityLevel: 128,
+		RingType:      ring.ConjugateInvariant,
 	}
 )
 
@@ -316,16 +336,20 @@ var (
 // type (RingType) and the number of slots (in log_2, LogSlots). If left unset, standard default values for
 // these field are substituted at parameter creation (see NewParametersFromLiteral).
 type ParametersLiteral struct {
-	LogN         int // Ring degree (power of 2)
-	Q            []uint64
-	P            []uint64
-	LogQ         []int `json:",omitempty"`
-	LogP         []int `json:",omitempty"`
-	H            int
-	Sigma        float64 // Gaussian sampling variance
-	LogSlots     int
-	DefaultScale float64
-	RingType     ring.Type
+	LogN               int // Ring degree (power of 2)
+	Q                  []uint64
+	P                  []uint64
+	LogQ               []int `json:",omitempty"`
+	LogP               []int `json:",omitempty"`
+	H                  int
+	Sigma              float64                // Gaussian sampling variance
+	ErrorDistribution  rlwe.ErrorDistribution `json:",omitempty"`
+	GaussianTailFactor float64                `json:",omitempty"` // Tail-cut of the error distribution, in multiples of Sigma
+	LogSlots           int
+	DefaultScale       float64
+	RingType           ring.Type
+	SecurityLevel      int     `json:",omitempty"` // Bit-security label (128, 192 or 256), not checked
+	MinModuliGap       float64 `json:",omitempty"` // Minimum relative gap between consecutive Qi, checked if set
 }
 
 // DefaultParams is a set of default CKKS parameters ensuring 128 bit security in a classic setting.
@@ -340,6 +364,27 @@ var DefaultPostQuantumParams = []ParametersLiteral{PN12QP101pq, PN13QP202pq, PN1
 // DefaultPostQuantumConjugateInvariantParams is a set of default conjugate invariant parameters for encrypting real values and ensuring 128 bit security in a post-quantum setting.
 var DefaultPostQuantumConjugateInvariantParams = []ParametersLiteral{PN12QP101CIpq, PN13QP202CIpq, PN14QP411CIpq, PN15QP827CIpq, PN16QP1654CIpq}
 
+// FilterParamsBySecurity returns the parameters of DefaultParams, DefaultConjugateInvariantParams,
+// DefaultPostQuantumParams and DefaultPostQuantumConjugateInvariantParams whose SecurityLevel label
+// is equal to level.
+func FilterParamsBySecurity(level int) (params []Parameters) {
+	var defaults []ParametersLiteral
+	defaults = append(defaults, DefaultParams...)
+	defaults = append(defaults, DefaultConjugateInvariantParams...)
+	defaults = append(defaults, DefaultPostQuantumParams...)
+	defaults = append(defaults, DefaultPostQuantumConjugateInvariantParams...)
+	for _, pl := range defaults {
+		if pl.SecurityLevel == level {
+			p, err := NewParametersFromLiteral(pl)
+			if err != nil {
+				panic(err)
+			}
+			params = append(params, p)
+		}
+	}
+	return
+}
+
 // Parameters represents a parameter set for the CKKS cryptosystem. Its fields are private and
 // immutable. See ParametersLiteral for user-specified parameters.
 type Parameters struct {
@@ -371,7 +416,7 @@ func NewParameters(rlweParams rlwe.Parameters, logSlots int, defaultScale float6
 //
 // See `rlwe.NewParametersFromLiteral` for default values of the other optional fields.
 func NewParametersFromLiteral(pl ParametersLiteral) (Parameters, error) {
-	rlweParams, err := rlwe.NewParametersFromLiteral(rlwe.ParametersLiteral{LogN: pl.LogN, Q: pl.Q, P: pl.P, LogQ: pl.LogQ, LogP: pl.LogP, H: pl.H, Sigma: pl.Sigma, RingType: pl.RingType})
+	rlweParams, err := rlwe.NewParametersFromLiteral(rlwe.ParametersLiteral{LogN: pl.LogN, Q: pl.Q, P: pl.P, LogQ: pl.LogQ, LogP: pl.LogP, H: pl.H, Sigma: pl.Sigma, ErrorDistribution: pl.ErrorDistribution, GaussianTailFactor: pl.GaussianTailFactor, RingType: pl.RingType, SecurityLevel: pl.SecurityLevel, MinModuliGap: pl.MinModuliGap})
 	if err != nil {
 		return Parameters{}, err
 	}
@@ -595,7 +640,7 @@ func (p Parameters) MarshalBinarySize() int {
 
 // MarshalJSON returns a JSON representation of this parameter set. See `Marshal` from the `encoding/json` package.
 func (p Parameters) MarshalJSON() ([]byte, error) {
-	return json.Marshal(ParametersLiteral{LogN: p.LogN(), Q: p.Q(), P: p.P(), H: p.HammingWeight(), Sigma: p.Sigma(), LogSlots: p.logSlots, DefaultScale: p.defaultScale, RingType: p.RingType()})
+	return json.Marshal(ParametersLiteral{LogN: p.LogN(), Q: p.Q(), P: p.P(), H: p.HammingWeight(), Sigma: p.Sigma(), ErrorDistribution: p.ErrorDistribution(), GaussianTailFactor: p.GaussianTailFactor(), SecurityLevel: p.SecurityLevel(), LogSlots: p.logSlots, DefaultScale: p.defaultScale, RingType: p.RingType()})
 }
 
 // UnmarshalJSON reads a JSON representation of a parameter set into the receiver Parameter. See `Unmarshal` from the `encoding/json` package.
@@ -21,6 +21,7 @@ func NewCKSProtocol(params bfv.Parameters, sigmaSmudging float64) *CKSProtocol {
 // KeySwitch performs the actual keyswitching operation on a ciphertext ct and put the result in ctOut
 func (cks *CKSProtocol) KeySwitch(ctIn *bfv.Ciphertext, combined *drlwe.CKSShare, ctOut *bfv.Ciphertext) {
 	cks.CKSProtocol.KeySwitch(ctIn.Ciphertext, combined, ctOut.Ciphertext)
+	ctOut.NoiseTracker = nil
 }
 
 // AllocateShare allocates the shares of one party in the CKS protocol for BFV.
@@ -55,6 +56,7 @@ func (pcks *PCKSProtocol) AllocateShare() *drlwe.PCKSShare {
 // KeySwitch performs the actual keyswitching operation on a ciphertext ct and put the result in ctOut.
 func (pcks *PCKSProtocol) KeySwitch(ctIn *bfv.Ciphertext, combined *drlwe.PCKSShare, ctOut *bfv.Ciphertext) {
 	pcks.PCKSProtocol.KeySwitch(ctIn.Ciphertext, combined, ctOut.Ciphertext)
+	ctOut.NoiseTracker = nil
 }
 
 // ShallowCopy creates a shallow copy of PCKSProtocol in which all the read-only data-structures are
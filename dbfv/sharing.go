@@ -140,4 +140,5 @@ func (s2e *S2EProtocol) GetEncryption(c0Agg *drlwe.CKSShare, crp drlwe.CKSCRP, c
 	}
 	ctOut.Value[0].Copy(c0Agg.Value)
 	ctOut.Value[1].Copy((*ring.Poly)(&crp))
+	ctOut.NoiseTracker = nil
 }
@@ -44,15 +44,12 @@ type testContext struct {
 
 func newTestContext(params rlwe.Parameters) testContext {
 
-	levelQ, levelP := params.QCount()-1, params.PCount()-1
-
 	kgen := rlwe.NewKeyGenerator(params)
 	skShares := make([]*rlwe.SecretKey, nbParties)
-	skIdeal := rlwe.NewSecretKey(params)
 	for i := range skShares {
 		skShares[i] = kgen.GenSecretKey()
-		params.RingQP().AddLvl(levelQ, levelP, skIdeal.Value, skShares[i].Value, skIdeal.Value)
 	}
+	skIdeal := params.AddSecretKeys(skShares...)
 
 	prng, _ := utils.NewKeyedPRNG([]byte{'t', 'e', 's', 't'})
 	unifSampler := ring.NewUniformSampler(prng, params.RingQ())
@@ -133,6 +130,14 @@ func testPublicKeyGen(testCtx testContext, t *testing.T) {
 		pk := rlwe.NewPublicKey(params)
 		ckg[0].GenPublicKey(shares[0], crp, pk)
 
+		// Enc_pk(0) decrypts under the sum of the secret-key shares
+		plaintext := rlwe.NewPlaintext(params, params.MaxLevel())
+		plaintext.Value.IsNTT = true
+		ciphertext := rlwe.NewEncryptor(params, pk).EncryptNew(plaintext)
+		rlwe.NewDecryptor(params, params.AddSecretKeys(testCtx.skShares...)).Decrypt(ciphertext, plaintext)
+		ringQ.InvNTTLvl(plaintext.Level(), plaintext.Value, plaintext.Value)
+		require.GreaterOrEqual(t, 9+params.LogN()+bits.Len64(uint64(nbParties)), log2OfInnerSum(plaintext.Level(), ringQ, plaintext.Value))
+
 		// [-as + e] + [as]
 		ringQP.MulCoeffsMontgomeryAndAddLvl(levelQ, levelP, testCtx.skIdeal.Value, pk.Value[1], pk.Value[0])
 		ringQP.InvNTTLvl(levelQ, levelP, pk.Value[0], pk.Value[0])
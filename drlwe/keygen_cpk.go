@@ -30,7 +30,7 @@ func (ckg *CKGProtocol) ShallowCopy() *CKGProtocol {
 		panic(err)
 	}
 
-	return &CKGProtocol{ckg.params, ring.NewGaussianSampler(prng, ckg.params.RingQ(), ckg.params.Sigma(), int(6*ckg.params.Sigma()))}
+	return &CKGProtocol{ckg.params, ring.NewGaussianSampler(prng, ckg.params.RingQ(), ckg.params.Sigma(), ckg.params.ErrorBound())}
 }
 
 // CKGShare is a struct storing the CKG protocol's share.
@@ -65,7 +65,7 @@ func NewCKGProtocol(params rlwe.Parameters) *CKGProtocol {
 	if err != nil {
 		panic(err)
 	}
-	ckg.gaussianSamplerQ = ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), int(6*params.Sigma()))
+	ckg.gaussianSamplerQ = ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), params.ErrorBound())
 	return ckg
 }
 
@@ -111,4 +111,11 @@ func (ckg *CKGProtocol) AggregateShare(share1, share2, shareOut *CKGShare) {
 func (ckg *CKGProtocol) GenPublicKey(roundShare *CKGShare, crp CKGCRP, pubkey *rlwe.PublicKey) {
 	pubkey.Value[0].Copy(roundShare.Value)
 	pubkey.Value[1].Copy(rlwe.PolyQP(crp))
+
+	for i := range pubkey.Value {
+		pubkey.Value[i].Q.IsNTT, pubkey.Value[i].Q.IsMForm = true, false
+		if pubkey.Value[i].P != nil {
+			pubkey.Value[i].P.IsNTT, pubkey.Value[i].P.IsMForm = true, false
+		}
+	}
 }
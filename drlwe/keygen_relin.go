@@ -44,7 +44,7 @@ func (ekg *RKGProtocol) ShallowCopy() *RKGProtocol {
 	return &RKGProtocol{
 		params:           ekg.params,
 		pBigInt:          ekg.pBigInt,
-		gaussianSamplerQ: ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), int(6*params.Sigma())),
+		gaussianSamplerQ: ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), params.ErrorBound()),
 		ternarySamplerQ:  ring.NewTernarySamplerWithHammingWeight(prng, params.RingQ(), params.HammingWeight(), false),
 		tmpPoly1:         params.RingQP().NewPoly(),
 		tmpPoly2:         params.RingQP().NewPoly(),
@@ -71,7 +71,7 @@ func NewRKGProtocol(params rlwe.Parameters) *RKGProtocol {
 	}
 
 	rkg.pBigInt = params.PBigInt()
-	rkg.gaussianSamplerQ = ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), int(6*params.Sigma()))
+	rkg.gaussianSamplerQ = ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), params.ErrorBound())
 	rkg.ternarySamplerQ = ring.NewTernarySamplerWithHammingWeight(prng, params.RingQ(), params.HammingWeight(), false)
 	rkg.tmpPoly1 = params.RingQP().NewPoly()
 	rkg.tmpPoly2 = params.RingQP().NewPoly()
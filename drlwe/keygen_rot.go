@@ -47,7 +47,7 @@ func (rtg *RTGProtocol) ShallowCopy() *RTGProtocol {
 		params:           rtg.params,
 		tmpPoly0:         params.RingQP().NewPoly(),
 		tmpPoly1:         params.RingQP().NewPoly(),
-		gaussianSamplerQ: ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), int(6*params.Sigma())),
+		gaussianSamplerQ: ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), params.ErrorBound()),
 	}
 }
 
@@ -60,7 +60,7 @@ func NewRTGProtocol(params rlwe.Parameters) *RTGProtocol {
 	if err != nil {
 		panic(err)
 	}
-	rtg.gaussianSamplerQ = ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), int(6*params.Sigma()))
+	rtg.gaussianSamplerQ = ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), params.ErrorBound())
 	rtg.tmpPoly0 = params.RingQP().NewPoly()
 	rtg.tmpPoly1 = params.RingQP().NewPoly()
 	return rtg
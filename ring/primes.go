@@ -10,6 +10,46 @@ func IsPrime(x uint64) bool {
 	return NewUint(x).ProbablyPrime(0)
 }
 
+// IsNTTFriendly returns true if q is a prime with q = 1 mod 2n, in which case q supports
+// the negacyclic NTT of size n. The primality test is the one of IsPrime.
+func IsNTTFriendly(q uint64, n int) bool {
+	return n > 0 && (q-1)%(uint64(n)<<1) == 0 && IsPrime(q)
+}
+
+// FindNTTPrime returns the largest prime q of exactly bitSize bits with q = 1 mod 2n.
+// It returns an error if bitSize is not in [1, 61] or if there is no such prime.
+func FindNTTPrime(bitSize, n int) (q uint64, err error) {
+
+	if bitSize < 1 || bitSize > 61 {
+		return 0, fmt.Errorf("bitSize=%d must be between 1 and 61", bitSize)
+	}
+
+	if n < 1 {
+		return 0, fmt.Errorf("n=%d must be positive", n)
+	}
+
+	NthRoot := uint64(n) << 1
+	lo, hi := uint64(1)<<(bitSize-1), uint64(1)<<bitSize
+
+	if NthRoot >= hi {
+		return 0, fmt.Errorf("no %d-bit NTT prime for n=%d", bitSize, n)
+	}
+
+	// largest candidate q = 1 mod 2n smaller than 2^bitSize (hi - 2n + 1 is only 1 mod 2n if n is a power of two)
+	for q = ((hi-2)/NthRoot)*NthRoot + 1; q >= lo; q -= NthRoot {
+
+		if IsPrime(q) {
+			return q, nil
+		}
+
+		if q < NthRoot {
+			break
+		}
+	}
+
+	return 0, fmt.Errorf("no %d-bit NTT prime for n=%d", bitSize, n)
+}
+
 // GenerateNTTPrimes generates n NthRoot NTT friendly primes given logQ = size of the primes.
 // It will return all the appropriate primes, up to the number of n, with the
 // best available deviation from the base power of 2 for the given n.
@@ -170,6 +170,52 @@ func (be *BasisExtender) ShallowCopy() *BasisExtender {
 	}
 }
 
+// BasisExtenderPool is a pool of BasisExtenders sharing the same read-only data-structures, from which
+// concurrent workers can each draw their own BasisExtender. It is safe for concurrent use.
+type BasisExtenderPool struct {
+	base *BasisExtender
+	pool chan *BasisExtender
+}
+
+// NewBasisExtenderPool creates a new BasisExtenderPool for the rings ringQ and ringP holding up to size
+// BasisExtenders, all of which are allocated at creation.
+func NewBasisExtenderPool(ringQ, ringP *Ring, size int) *BasisExtenderPool {
+
+	if size < 1 {
+		panic("cannot NewBasisExtenderPool: size must be at least 1")
+	}
+
+	base := NewBasisExtender(ringQ, ringP)
+
+	pool := make(chan *BasisExtender, size)
+	pool <- base
+	for i := 1; i < size; i++ {
+		pool <- base.ShallowCopy()
+	}
+
+	return &BasisExtenderPool{base: base, pool: pool}
+}
+
+// Get returns a BasisExtender of the pool, which is not given to any other caller until it is given
+// back with Put. If the pool is empty, a new shallow copy of the BasisExtenders of the pool is returned.
+func (bp *BasisExtenderPool) Get() *BasisExtender {
+	select {
+	case be := <-bp.pool:
+		return be
+	default:
+		return bp.base.ShallowCopy()
+	}
+}
+
+// Put gives back to the pool a BasisExtender returned by Get, which must not be used by the caller afterward.
+// The BasisExtender is discarded if the pool is full.
+func (bp *BasisExtenderPool) Put(be *BasisExtender) {
+	select {
+	case bp.pool <- be:
+	default:
+	}
+}
+
 // ModUpQtoP extends the RNS basis of a polynomial from Q to QP.
 // Given a polynomial with coefficients in basis {Q0,Q1....Qlevel},
 // it extends its basis from {Q0,Q1....Qlevel} to {Q0,Q1....Qlevel,P0,P1...Pj}
@@ -0,0 +1,136 @@
+package ring
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// autoTuneNTTBudget is the total time spent by AutoTuneNTTThreads benchmarking a list of rings.
+const autoTuneNTTBudget = 4 * time.Millisecond
+
+// autoTuneNTTCache maps the type, degree and moduli of each tuned list of rings to its number of goroutines.
+// Its size is bounded by the number of distinct parameter sets rather than of Ring instances.
+var autoTuneNTTCache sync.Map
+
+// AutoTuneNTTThreads returns the number of goroutines across which the limbs of an NTT over the rings
+// should be distributed with EvaluateLimbsParallel to maximize the throughput on the current machine,
+// for example with the rings Q and P of an NTT over the ring QP. Nil rings are ignored. The candidates
+// are the powers of two up to min(GOMAXPROCS, #limbs). They are measured by a micro-benchmark of a few
+// milliseconds on the first call for given ring types, degrees and moduli, and the result is cached
+// for the subsequent calls, including with other Ring instances sharing these parameters.
+// A larger number of goroutines is only selected if it improves the throughput by more than 10%,
+// which keeps the result stable across runs.
+func AutoTuneNTTThreads(rings ...*Ring) int {
+
+	var key string
+	for _, r := range rings {
+		if r != nil {
+			key += fmt.Sprint(r.Type(), r.N, r.Modulus)
+		}
+	}
+
+	if nbThreads, ok := autoTuneNTTCache.Load(key); ok {
+		return nbThreads.(int)
+	}
+	nbThreads, _ := autoTuneNTTCache.LoadOrStore(key, autoTuneNTTThreads(rings))
+	return nbThreads.(int)
+}
+
+func autoTuneNTTThreads(rings []*Ring) int {
+
+	limbs := []LimbsOperand{}
+	for _, r := range rings {
+		if r != nil {
+			p := r.NewPoly()
+			limbs = append(limbs, LimbsOperand{Ring: r, Level: len(r.Modulus) - 1, P1: p, P2: p})
+		}
+	}
+
+	var nbLimbs int
+	for _, l := range limbs {
+		nbLimbs += l.Level + 1
+	}
+
+	maxThreads := runtime.GOMAXPROCS(0)
+	if maxThreads > nbLimbs {
+		maxThreads = nbLimbs
+	}
+
+	candidates := []int{1}
+	for nbThreads := 2; nbThreads <= maxThreads; nbThreads <<= 1 {
+		candidates = append(candidates, nbThreads)
+	}
+
+	if len(candidates) == 1 {
+		return 1
+	}
+
+	budget := autoTuneNTTBudget / time.Duration(len(candidates))
+
+	var best int
+	var bestThroughput float64
+
+	for _, nbThreads := range candidates {
+
+		var count int
+		start := time.Now()
+		for count == 0 || time.Since(start) < budget {
+			EvaluateLimbsParallel(nbThreads, (*Ring).NTTSingle, limbs...)
+			count++
+		}
+
+		if throughput := float64(count) / float64(time.Since(start)); throughput > 1.1*bestThroughput {
+			best, bestThroughput = nbThreads, throughput
+		}
+	}
+
+	return best
+}
+
+// LimbsOperand is a pair of polynomials of a Ring whose limbs up to Level are processed by EvaluateLimbsParallel.
+type LimbsOperand struct {
+	Ring   *Ring
+	Level  int
+	P1, P2 *Poly
+}
+
+// EvaluateLimbsParallel applies the per-limb function evaluate on the limbs of the operands, mapping the
+// i-th limb of P1 to the i-th limb of P2 for i up to Level, and distributes the limbs of all the operands
+// across nbThreads goroutines. Values of nbThreads smaller than 2 evaluate the limbs sequentially.
+// For example, EvaluateLimbsParallel(nbThreads, (*Ring).NTTSingle, limbs...) is a limb-parallel NTT.
+func EvaluateLimbsParallel(nbThreads int, evaluate func(r *Ring, i int, p1, p2 []uint64), limbs ...LimbsOperand) {
+
+	type limb struct {
+		r      *Ring
+		i      int
+		p1, p2 []uint64
+	}
+
+	all := []limb{}
+	for _, l := range limbs {
+		for i := 0; i < l.Level+1; i++ {
+			all = append(all, limb{l.Ring, i, l.P1.Coeffs[i], l.P2.Coeffs[i]})
+		}
+	}
+
+	if nbThreads < 2 {
+		for _, l := range all {
+			evaluate(l.r, l.i, l.p1, l.p2)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(nbThreads)
+	for i := 0; i < nbThreads; i++ {
+		go func(i int) {
+			for j := i; j < len(all); j += nbThreads {
+				evaluate(all[j].r, all[j].i, all[j].p1, all[j].p2)
+			}
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+}
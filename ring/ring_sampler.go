@@ -1,6 +1,8 @@
 package ring
 
 import (
+	"fmt"
+
 	"github.com/tuneinsight/lattigo/v3/utils"
 )
 
@@ -11,9 +13,59 @@ type baseSampler struct {
 	baseRing *Ring
 }
 
+// checkLevel panics with a descriptive message if level is not a valid level of the ring r
+// or if pol does not have enough moduli to be sampled at this level.
+func checkLevel(method string, r *Ring, level int, pol *Poly) {
+
+	if maxLevel := len(r.Modulus) - 1; level < 0 || level > maxLevel {
+		panic(fmt.Errorf("cannot %s: level %d is not in [0, %d]", method, level, maxLevel))
+	}
+
+	if level > pol.Level() {
+		panic(fmt.Errorf("cannot %s: level %d is greater than the level %d of the polynomial", method, level, pol.Level()))
+	}
+}
+
 // Sampler is an interface for random polynomial samplers.
 // It has a single Read method which takes as argument the polynomial to be
 // populated according to the Sampler's distribution.
 type Sampler interface {
 	Read(pOut *Poly)
 }
+
+// ErrorSampler is an interface for samplers of small error polynomials.
+type ErrorSampler interface {
+	Sampler
+	ReadLvl(level int, pOut *Poly)
+	ReadAndAddLvl(level int, pOut *Poly)
+	MarshalState() []byte
+	UnmarshalState(data []byte) error
+}
+
+// marshalBufferState encodes the random bytes buffered by a sampler along with
+// the position of the next unread byte.
+func marshalBufferState(buffer []byte, ptr int) []byte {
+	buff := utils.NewBuffer(make([]byte, 0, 8+len(buffer)))
+	buff.WriteUint64(uint64(ptr))
+	buff.WriteUint8Slice(buffer)
+	return buff.Bytes()
+}
+
+// unmarshalBufferState decodes a state encoded by marshalBufferState on buffer
+// and returns the position of the next unread byte.
+func unmarshalBufferState(data []byte, buffer []byte) (ptr int, err error) {
+
+	if len(data) != 8+len(buffer) {
+		return 0, fmt.Errorf("cannot UnmarshalState: invalid data length %d, expected %d", len(data), 8+len(buffer))
+	}
+
+	buff := utils.NewBuffer(data)
+
+	if ptr = int(buff.ReadUint64()); ptr < 0 || ptr > len(buffer) {
+		return 0, fmt.Errorf("cannot UnmarshalState: invalid buffer position %d", ptr)
+	}
+
+	buff.ReadUint8Slice(buffer)
+
+	return ptr, nil
+}
@@ -0,0 +1,125 @@
+package ring
+
+import (
+	"math"
+	"math/bits"
+
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// CenteredBinomialSampler keeps the state of a truncated centered binomial polynomial sampler.
+type CenteredBinomialSampler struct {
+	baseSampler
+	k            int
+	bound        int
+	randomBuffer []byte
+	ptr          int
+}
+
+// NewCenteredBinomialSampler creates a new instance of CenteredBinomialSampler from a PRNG, a ring definition and the
+// truncated distribution parameters. The coefficients are sampled as sum_{i<k} (a_i - b_i), with a_i and b_i uniform
+// bits and k = round(2*sigma^2), such that the standard deviation of the distribution is sqrt(k/2) ~ sigma.
+// Samples of norm larger than bound are rejected.
+func NewCenteredBinomialSampler(prng utils.PRNG, baseRing *Ring, sigma float64, bound int) *CenteredBinomialSampler {
+	cbd := new(CenteredBinomialSampler)
+	cbd.prng = prng
+	cbd.baseRing = baseRing
+	cbd.k = utils.MaxInt(1, int(math.Round(2*sigma*sigma)))
+	cbd.bound = bound
+	cbd.randomBuffer = make([]byte, 1024*2*((cbd.k+7)>>3))
+	cbd.ptr = len(cbd.randomBuffer)
+	return cbd
+}
+
+// Read samples a truncated centered binomial polynomial on "pol" at the maximum level in the default ring.
+func (cbd *CenteredBinomialSampler) Read(pol *Poly) {
+	cbd.ReadLvl(len(cbd.baseRing.Modulus)-1, pol)
+}
+
+// ReadLvl samples a truncated centered binomial polynomial on "pol" at the provided level in the default ring.
+func (cbd *CenteredBinomialSampler) ReadLvl(level int, pol *Poly) {
+	checkLevel("ReadLvl", cbd.baseRing, level, pol)
+	for i := 0; i < cbd.baseRing.N; i++ {
+		coeff, sign := cbd.sample()
+		for j, qi := range cbd.baseRing.Modulus[:level+1] {
+			pol.Coeffs[j][i] = (coeff * sign) | (qi-coeff)*(sign^1)
+		}
+	}
+}
+
+// ReadNew samples a new truncated centered binomial polynomial at the maximum level in the default ring.
+func (cbd *CenteredBinomialSampler) ReadNew() (pol *Poly) {
+	pol = cbd.baseRing.NewPoly()
+	cbd.Read(pol)
+	return pol
+}
+
+// ReadLvlNew samples a new truncated centered binomial polynomial at the provided level in the default ring.
+func (cbd *CenteredBinomialSampler) ReadLvlNew(level int) (pol *Poly) {
+	pol = cbd.baseRing.NewPolyLvl(level)
+	cbd.ReadLvl(level, pol)
+	return pol
+}
+
+// ReadAndAddLvl samples a truncated centered binomial polynomial at the provided level in the default ring and adds it on "pol".
+func (cbd *CenteredBinomialSampler) ReadAndAddLvl(level int, pol *Poly) {
+	checkLevel("ReadAndAddLvl", cbd.baseRing, level, pol)
+	for i := 0; i < cbd.baseRing.N; i++ {
+		coeff, sign := cbd.sample()
+		for j, qi := range cbd.baseRing.Modulus[:level+1] {
+			pol.Coeffs[j][i] = CRed(pol.Coeffs[j][i]+((coeff*sign)|(qi-coeff)*(sign^1)), qi)
+		}
+	}
+}
+
+// MarshalState encodes the random bytes buffered by the sampler between two calls.
+// Together with the state of its PRNG, it allows to resume the exact same sequence of samples.
+func (cbd *CenteredBinomialSampler) MarshalState() []byte {
+	return marshalBufferState(cbd.randomBuffer, cbd.ptr)
+}
+
+// UnmarshalState decodes a state encoded by MarshalState on the sampler.
+func (cbd *CenteredBinomialSampler) UnmarshalState(data []byte) (err error) {
+	ptr, err := unmarshalBufferState(data, cbd.randomBuffer)
+	if err == nil {
+		cbd.ptr = ptr
+	}
+	return
+}
+
+// sample returns the absolute value of a sample and its sign (1 if positive, 0 if negative).
+func (cbd *CenteredBinomialSampler) sample() (coeff uint64, sign uint64) {
+
+	half := (cbd.k + 7) >> 3
+
+	for {
+
+		if cbd.ptr == len(cbd.randomBuffer) {
+			cbd.prng.Clock(cbd.randomBuffer)
+			cbd.ptr = 0
+		}
+
+		buff := cbd.randomBuffer[cbd.ptr : cbd.ptr+2*half]
+		cbd.ptr += 2 * half
+
+		var a, b int
+		for i := 0; i < half; i++ {
+
+			mask := byte(0xff)
+			if rem := cbd.k - (i << 3); rem < 8 {
+				mask = byte(1<<rem) - 1
+			}
+
+			a += bits.OnesCount8(buff[i] & mask)
+			b += bits.OnesCount8(buff[half+i] & mask)
+		}
+
+		if a >= b && a-b <= cbd.bound {
+			return uint64(a - b), 1
+		}
+
+		if b > a && b-a <= cbd.bound {
+			return uint64(b - a), 0
+		}
+	}
+}
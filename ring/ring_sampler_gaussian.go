@@ -37,6 +37,7 @@ func (gaussianSampler *GaussianSampler) Read(pol *Poly) {
 
 // ReadLvl samples a truncated Gaussian polynomial at the provided level, in the default ring, standard deviation and bound.
 func (gaussianSampler *GaussianSampler) ReadLvl(level int, pol *Poly) {
+	checkLevel("ReadLvl", gaussianSampler.baseRing, level, pol)
 	gaussianSampler.readLvl(level, pol, gaussianSampler.baseRing, gaussianSampler.sigma, gaussianSampler.bound)
 }
 
@@ -56,6 +57,7 @@ func (gaussianSampler *GaussianSampler) ReadLvlNew(level int) (pol *Poly) {
 
 // ReadFromDistLvl samples a truncated Gaussian polynomial at the given level in the provided ring, standard deviation and bound.
 func (gaussianSampler *GaussianSampler) ReadFromDistLvl(level int, pol *Poly, ring *Ring, sigma float64, bound int) {
+	checkLevel("ReadFromDistLvl", ring, level, pol)
 	gaussianSampler.readLvl(level, pol, ring, sigma, bound)
 }
 
@@ -66,6 +68,7 @@ func (gaussianSampler *GaussianSampler) ReadAndAddLvl(level int, pol *Poly) {
 
 // ReadAndAddFromDistLvl samples a truncated Gaussian polynomial at the given level in the provided ring, standard deviation and bound and adds it on "pol".
 func (gaussianSampler *GaussianSampler) ReadAndAddFromDistLvl(level int, pol *Poly, ring *Ring, sigma float64, bound int) {
+	checkLevel("ReadAndAddFromDistLvl", ring, level, pol)
 	var coeffFlo float64
 	var coeffInt, sign uint64
 
@@ -89,6 +92,21 @@ func (gaussianSampler *GaussianSampler) ReadAndAddFromDistLvl(level int, pol *Po
 	}
 }
 
+// MarshalState encodes the random bytes buffered by the sampler between two calls.
+// Together with the state of its PRNG, it allows to resume the exact same sequence of samples.
+func (gaussianSampler *GaussianSampler) MarshalState() []byte {
+	return marshalBufferState(gaussianSampler.randomBufferN, int(gaussianSampler.ptr))
+}
+
+// UnmarshalState decodes a state encoded by MarshalState on the sampler.
+func (gaussianSampler *GaussianSampler) UnmarshalState(data []byte) (err error) {
+	ptr, err := unmarshalBufferState(data, gaussianSampler.randomBufferN)
+	if err == nil {
+		gaussianSampler.ptr = uint64(ptr)
+	}
+	return
+}
+
 func (gaussianSampler *GaussianSampler) readLvl(level int, pol *Poly, ring *Ring, sigma float64, bound int) {
 	var coeffFlo float64
 	var coeffInt uint64
@@ -290,3 +308,111 @@ var fn = [128]float32{
 	0.025693292, 0.022103304, 0.018592102, 0.015167298,
 	0.011839478, 0.008624485, 0.005548995, 0.0026696292,
 }
+
+// RoundedGaussianSampler keeps the state of a truncated rounded continuous Gaussian polynomial sampler.
+type RoundedGaussianSampler struct {
+	baseSampler
+	sigma        float64
+	bound        int
+	randomBuffer []byte
+	ptr          int
+}
+
+// NewRoundedGaussianSampler creates a new instance of RoundedGaussianSampler from a PRNG, a ring definition and the
+// truncated distribution parameters. The coefficients are sampled from a continuous Gaussian distribution of
+// standard deviation sigma (using the Box-Muller transform) and rounded to the nearest integer. Samples of norm
+// larger than bound are rejected.
+func NewRoundedGaussianSampler(prng utils.PRNG, baseRing *Ring, sigma float64, bound int) *RoundedGaussianSampler {
+	rgs := new(RoundedGaussianSampler)
+	rgs.prng = prng
+	rgs.baseRing = baseRing
+	rgs.sigma = sigma
+	rgs.bound = bound
+	rgs.randomBuffer = make([]byte, 1024)
+	rgs.ptr = len(rgs.randomBuffer)
+	return rgs
+}
+
+// Read samples a truncated rounded Gaussian polynomial on "pol" at the maximum level in the default ring.
+func (rgs *RoundedGaussianSampler) Read(pol *Poly) {
+	rgs.ReadLvl(len(rgs.baseRing.Modulus)-1, pol)
+}
+
+// ReadLvl samples a truncated rounded Gaussian polynomial on "pol" at the provided level in the default ring.
+func (rgs *RoundedGaussianSampler) ReadLvl(level int, pol *Poly) {
+	checkLevel("ReadLvl", rgs.baseRing, level, pol)
+	for i := 0; i < rgs.baseRing.N; i++ {
+		coeff, sign := rgs.sample()
+		for j, qi := range rgs.baseRing.Modulus[:level+1] {
+			pol.Coeffs[j][i] = (coeff * sign) | (qi-coeff)*(sign^1)
+		}
+	}
+}
+
+// ReadNew samples a new truncated rounded Gaussian polynomial at the maximum level in the default ring.
+func (rgs *RoundedGaussianSampler) ReadNew() (pol *Poly) {
+	pol = rgs.baseRing.NewPoly()
+	rgs.Read(pol)
+	return pol
+}
+
+// ReadLvlNew samples a new truncated rounded Gaussian polynomial at the provided level in the default ring.
+func (rgs *RoundedGaussianSampler) ReadLvlNew(level int) (pol *Poly) {
+	pol = rgs.baseRing.NewPolyLvl(level)
+	rgs.ReadLvl(level, pol)
+	return pol
+}
+
+// ReadAndAddLvl samples a truncated rounded Gaussian polynomial at the provided level in the default ring and adds it on "pol".
+func (rgs *RoundedGaussianSampler) ReadAndAddLvl(level int, pol *Poly) {
+	checkLevel("ReadAndAddLvl", rgs.baseRing, level, pol)
+	for i := 0; i < rgs.baseRing.N; i++ {
+		coeff, sign := rgs.sample()
+		for j, qi := range rgs.baseRing.Modulus[:level+1] {
+			pol.Coeffs[j][i] = CRed(pol.Coeffs[j][i]+((coeff*sign)|(qi-coeff)*(sign^1)), qi)
+		}
+	}
+}
+
+// MarshalState encodes the random bytes buffered by the sampler between two calls.
+// Together with the state of its PRNG, it allows to resume the exact same sequence of samples.
+func (rgs *RoundedGaussianSampler) MarshalState() []byte {
+	return marshalBufferState(rgs.randomBuffer, rgs.ptr)
+}
+
+// UnmarshalState decodes a state encoded by MarshalState on the sampler.
+func (rgs *RoundedGaussianSampler) UnmarshalState(data []byte) (err error) {
+	ptr, err := unmarshalBufferState(data, rgs.randomBuffer)
+	if err == nil {
+		rgs.ptr = ptr
+	}
+	return
+}
+
+// sample returns the absolute value of a sample and its sign (1 if positive, 0 if negative).
+func (rgs *RoundedGaussianSampler) sample() (coeff uint64, sign uint64) {
+	for {
+
+		if rgs.ptr == len(rgs.randomBuffer) {
+			rgs.prng.Clock(rgs.randomBuffer)
+			rgs.ptr = 0
+		}
+
+		u1 := randFloat64(rgs.randomBuffer[rgs.ptr : rgs.ptr+8])
+		u2 := randFloat64(rgs.randomBuffer[rgs.ptr+8 : rgs.ptr+16])
+		rgs.ptr += 16
+
+		if u1 == 0 {
+			continue
+		}
+
+		x := math.Round(math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2) * rgs.sigma)
+
+		if math.Abs(x) <= float64(rgs.bound) {
+			if x < 0 {
+				return uint64(-x), 0
+			}
+			return uint64(x), 1
+		}
+	}
+}
@@ -58,6 +58,7 @@ func (ts *TernarySampler) Read(pol *Poly) {
 
 // ReadLvl samples a polynomial into pol at the speciefied level.
 func (ts *TernarySampler) ReadLvl(lvl int, pol *Poly) {
+	checkLevel("ReadLvl", ts.baseRing, lvl, pol)
 	ts.sample(lvl, pol)
 }
 
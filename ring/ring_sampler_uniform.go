@@ -2,6 +2,8 @@ package ring
 
 import (
 	"encoding/binary"
+	"fmt"
+	"math/bits"
 
 	"github.com/tuneinsight/lattigo/v3/utils"
 )
@@ -68,6 +70,8 @@ func (uniformSampler *UniformSampler) Read(Pol *Poly) {
 // ReadLvl generates a new polynomial with coefficients following a uniform distribution over [0, Qi-1].
 func (uniformSampler *UniformSampler) ReadLvl(level int, Pol *Poly) {
 
+	checkLevel("ReadLvl", uniformSampler.baseRing, level, Pol)
+
 	var randomUint, mask, qi uint64
 	var ptr int
 
@@ -125,6 +129,142 @@ func (uniformSampler *UniformSampler) ReadLvlNew(level int) (Pol *Poly) {
 	return
 }
 
+// ReadTweaked generates a new polynomial with coefficients following a uniform distribution over [0, Qi-1],
+// at the specified level, from a PRNG forked from the PRNG of the sampler with the label "ReadTweaked"||tweak,
+// with tweak in big-endian. The output only depends on the key of the PRNG, on the tweak and on the ring: it
+// does not depend on the state of the sampler, which it does not advance. Parties sharing the key of the PRNG
+// can hence reproduce, in any order, a matrix of polynomials indexed by their position, e.g. with
+// tweak = i*cols + j, while distinct tweaks yield independent polynomials.
+// The method panics if the PRNG of the sampler does not implement utils.Forker.
+func (uniformSampler *UniformSampler) ReadTweaked(tweak uint64, level int, pOut *Poly) {
+
+	checkLevel("ReadTweaked", uniformSampler.baseRing, level, pOut)
+
+	forker, ok := uniformSampler.prng.(utils.Forker)
+	if !ok {
+		panic(fmt.Sprintf("cannot ReadTweaked: the PRNG of type %T does not implement utils.Forker", uniformSampler.prng))
+	}
+
+	label := make([]byte, 19)
+	copy(label, "ReadTweaked")
+	binary.BigEndian.PutUint64(label[11:], tweak)
+
+	tweaked := UniformSampler{
+		baseSampler:   baseSampler{prng: forker.Fork(label), baseRing: uniformSampler.baseRing},
+		randomBufferN: uniformSampler.randomBufferN,
+	}
+
+	tweaked.ReadLvl(level, pOut)
+}
+
+// SparseUniformSampler wraps a util.PRNG and represents the state of a sampler of polynomials with a
+// fixed number of nonzero coefficients, uniformly distributed over [1, Q-1], at uniformly random positions.
+type SparseUniformSampler struct {
+	baseSampler
+	weight        int
+	randomBufferN []byte
+	ptr           int
+}
+
+// NewSparseUniformSampler creates a new instance of SparseUniformSampler from a PRNG, the ring definition
+// and the number of nonzero coefficients of the output polynomials.
+func NewSparseUniformSampler(prng utils.PRNG, baseRing *Ring, weight int) *SparseUniformSampler {
+	sparseUniformSampler := new(SparseUniformSampler)
+	sparseUniformSampler.baseRing = baseRing
+	sparseUniformSampler.prng = prng
+	sparseUniformSampler.weight = weight
+	sparseUniformSampler.randomBufferN = make([]byte, baseRing.N)
+	return sparseUniformSampler
+}
+
+// Read samples a polynomial into pol.
+func (sus *SparseUniformSampler) Read(pol *Poly) {
+	sus.sample(len(sus.baseRing.Modulus)-1, pol)
+}
+
+// ReadLvl samples a polynomial into pol at the specified level.
+func (sus *SparseUniformSampler) ReadLvl(lvl int, pol *Poly) {
+	checkLevel("ReadLvl", sus.baseRing, lvl, pol)
+	sus.sample(lvl, pol)
+}
+
+// ReadNew allocates and samples a polynomial at the max level.
+func (sus *SparseUniformSampler) ReadNew() (pol *Poly) {
+	pol = sus.baseRing.NewPoly()
+	sus.sample(len(sus.baseRing.Modulus)-1, pol)
+	return pol
+}
+
+// ReadLvlNew allocates and samples a polynomial at the specified level.
+func (sus *SparseUniformSampler) ReadLvlNew(lvl int) (pol *Poly) {
+	pol = sus.baseRing.NewPolyLvl(lvl)
+	sus.sample(lvl, pol)
+	return pol
+}
+
+func (sus *SparseUniformSampler) sample(lvl int, pol *Poly) {
+
+	N := sus.baseRing.N
+
+	weight := sus.weight
+	if weight > N {
+		weight = N
+	}
+
+	for k := 0; k < lvl+1; k++ {
+		for i := range pol.Coeffs[k][:N] {
+			pol.Coeffs[k][i] = 0
+		}
+	}
+
+	index := make([]int, N)
+	for i := 0; i < N; i++ {
+		index[i] = i
+	}
+
+	sus.prng.Clock(sus.randomBufferN)
+	sus.ptr = 0
+
+	var j uint64
+
+	for i := 0; i < weight; i++ {
+
+		// rejection sampling of a random variable between [0, len(index)]
+		j = sus.randUniform(uint64(N-i), (1<<uint64(bits.Len64(uint64(N-i))))-1)
+
+		// Samples a coefficient uniformly in [0, Q-1] until it is nonzero
+		for nonZero := false; !nonZero; {
+			for k := 0; k < lvl+1; k++ {
+				pol.Coeffs[k][index[j]] = sus.randUniform(sus.baseRing.Modulus[k], sus.baseRing.Mask[k])
+				nonZero = nonZero || pol.Coeffs[k][index[j]] != 0
+			}
+		}
+
+		// Remove the element in position j of the slice (order not preserved)
+		index[j] = index[len(index)-1]
+		index = index[:len(index)-1]
+	}
+}
+
+// randUniform samples a uniform variable in the range [0, v-1] from the internal buffer of random bytes,
+// by rejection sampling in the range [0, mask]. mask needs to be of the form 2^n -1.
+func (sus *SparseUniformSampler) randUniform(v, mask uint64) (randomInt uint64) {
+	for {
+		// Refill the pool if it runs empty
+		if sus.ptr == len(sus.randomBufferN) {
+			sus.prng.Clock(sus.randomBufferN)
+			sus.ptr = 0
+		}
+
+		randomInt = binary.BigEndian.Uint64(sus.randomBufferN[sus.ptr:sus.ptr+8]) & mask
+		sus.ptr += 8
+
+		if randomInt < v {
+			return randomInt
+		}
+	}
+}
+
 // RandUniform samples a uniform randomInt variable in the range [0, mask] until randomInt is in the range [0, v-1].
 // mask needs to be of the form 2^n -1.
 func RandUniform(prng utils.PRNG, v uint64, mask uint64) (randomInt uint64) {
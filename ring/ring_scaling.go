@@ -395,6 +395,54 @@ func (r *Ring) DivRoundByLastModulusLvl(level int, p0, p1 *Poly) {
 	}
 }
 
+// DivRoundBySpecificModulusLvl divides (rounded) the polynomial by the modulus Qi with i = modulusIndex. The input must not be in the NTT domain.
+// The remaining limbs are reordered such that the output limb j is modulo Qj for j < modulusIndex and modulo Qj+1 for j >= modulusIndex.
+// When modulusIndex == level, this is equivalent to DivRoundByLastModulusLvl.
+// Output poly level must be equal or one less than input level.
+func (r *Ring) DivRoundBySpecificModulusLvl(level, modulusIndex int, p0, p1 *Poly) {
+
+	if modulusIndex < 0 || modulusIndex > level {
+		panic("cannot DivRoundBySpecificModulusLvl: modulusIndex must be between 0 and level")
+	}
+
+	if modulusIndex == level {
+		r.DivRoundByLastModulusLvl(level, p0, p1)
+		return
+	}
+
+	// Moves the limb to divide by at the last position, shifting the limbs above it down by one.
+	tmp := p0.Coeffs[modulusIndex]
+	copy(p0.Coeffs[modulusIndex:level], p0.Coeffs[modulusIndex+1:level+1])
+	p0.Coeffs[level] = tmp
+
+	// Center by (p-1)/2
+	pj := r.Modulus[modulusIndex]
+	pHalf := (pj - 1) >> 1
+
+	AddScalarVec(p0.Coeffs[level], p0.Coeffs[level], pHalf, pj)
+
+	for i := 0; i < level; i++ {
+
+		idx := i
+		if i >= modulusIndex {
+			idx++
+		}
+
+		qi := r.Modulus[idx]
+		rescaleParam := MForm(qi-ModExp(pj, qi-2, qi), qi, r.BredParams[idx])
+
+		AddScalarNoModAndNegTwoQiNoModVec(p0.Coeffs[i], p0.Coeffs[i], qi-BRedAdd(pHalf, qi, r.BredParams[idx]), qi)
+		AddVecNoModAndMulScalarMontgomeryVec(p0.Coeffs[level], p0.Coeffs[i], p1.Coeffs[i], rescaleParam, qi, r.MredParams[idx])
+	}
+
+	// Restores the original order of the limbs of the input.
+	if p0 != p1 {
+		tmp = p0.Coeffs[level]
+		copy(p0.Coeffs[modulusIndex+1:level+1], p0.Coeffs[modulusIndex:level])
+		p0.Coeffs[modulusIndex] = tmp
+	}
+}
+
 // DivRoundByLastModulusManyNTTLvl divides (rounded) sequentially nbRescales times the polynomial by its last modulus. The input must be in the NTT domain.
 // Output poly level must be equal or nbRescales less than input level.
 func (r *Ring) DivRoundByLastModulusManyNTTLvl(level, nbRescales int, p0, pool, p1 *Poly) {
@@ -3,7 +3,10 @@ package ring
 import (
 	"flag"
 	"fmt"
+	"math"
 	"math/big"
+	"math/bits"
+	"runtime"
 	"testing"
 
 	"github.com/tuneinsight/lattigo/v3/utils"
@@ -67,14 +70,18 @@ func TestRing(t *testing.T) {
 			t.Error(err)
 		}
 		testNTTConjugateInvariant(testContext, t)
+		testAutoTuneNTTThreads(testContext, t)
 		testPRNG(testContext, t)
 		testGenerateNTTPrimes(testContext, t)
 		testImportExportPolyString(testContext, t)
 		testDivFloorByLastModulusMany(testContext, t)
 		testDivRoundByLastModulusMany(testContext, t)
+		testDivRoundBySpecificModulus(testContext, t)
 		testMarshalBinary(testContext, t)
 		testUniformSampler(testContext, t)
 		testGaussianSampler(testContext, t)
+		testErrorSamplers(testContext, t)
+		testSamplersLevelBounds(testContext, t)
 		testTernarySampler(testContext, t)
 		testGaloisShift(testContext, t)
 		testModularReduction(testContext, t)
@@ -86,6 +93,37 @@ func TestRing(t *testing.T) {
 	}
 }
 
+func testAutoTuneNTTThreads(testContext *testParams, t *testing.T) {
+
+	t.Run(testString("AutoTuneNTTThreads/", testContext.ringQ), func(t *testing.T) {
+
+		ringQ := testContext.ringQ
+		level := len(ringQ.Modulus) - 1
+
+		p0 := testContext.uniformSamplerQ.ReadNew()
+		p1 := p0.CopyNew()
+		ringQ.NTTLvl(level, p0, p0)
+		EvaluateLimbsParallel(3, (*Ring).NTTSingle, LimbsOperand{Ring: ringQ, Level: level, P1: p1, P2: p1})
+		require.True(t, ringQ.EqualLvl(level, p0, p1))
+
+		nbThreads := AutoTuneNTTThreads(ringQ)
+		require.GreaterOrEqual(t, nbThreads, 1)
+		require.LessOrEqual(t, nbThreads, utils.MinInt(runtime.GOMAXPROCS(0), level+1))
+		require.Equal(t, nbThreads, AutoTuneNTTThreads(ringQ))
+
+		// the result is shared by the rings with the same parameters
+		ringQCopy, err := NewRingFromType(ringQ.N, ringQ.Modulus, ringQ.Type())
+		require.NoError(t, err)
+		require.Equal(t, nbThreads, AutoTuneNTTThreads(ringQCopy))
+
+		// the limbs of all the rings are tuned together, and nil rings are ignored
+		nbThreads = AutoTuneNTTThreads(ringQ, ringQ, nil)
+		require.GreaterOrEqual(t, nbThreads, 1)
+		require.LessOrEqual(t, nbThreads, utils.MinInt(runtime.GOMAXPROCS(0), 2*(level+1)))
+		require.Equal(t, nbThreads, AutoTuneNTTThreads(ringQ, ringQ))
+	})
+}
+
 func testNTTConjugateInvariant(testContext *testParams, t *testing.T) {
 
 	t.Run(testString("NTTConjugateInvariant/", testContext.ringQ), func(t *testing.T) {
@@ -207,6 +245,52 @@ func testGenerateNTTPrimes(testContext *testParams, t *testing.T) {
 			require.True(t, IsPrime(q), q)
 		}
 	})
+
+	t.Run(testString("FindNTTPrime/", testContext.ringQ), func(t *testing.T) {
+
+		N := testContext.ringQ.N
+
+		for _, q := range testContext.ringQ.Modulus {
+			require.True(t, IsNTTFriendly(q, N), q)
+		}
+
+		require.False(t, IsNTTFriendly(uint64(2*N+1)*uint64(2*N+1), N)) // 1 mod 2N but not prime
+		require.False(t, IsNTTFriendly(0x7fffffff, N))                  // prime but not 1 mod 2N
+		require.False(t, IsNTTFriendly(1, N))
+
+		for _, bitSize := range []int{30, 45, 55, 60, 61} {
+			q, err := FindNTTPrime(bitSize, N)
+			require.NoError(t, err)
+			require.Equal(t, bitSize, bits.Len64(q))
+			require.True(t, IsNTTFriendly(q, N), q)
+
+			// no larger candidate of the same bit-size is prime
+			for c := q + uint64(N<<1); bits.Len64(c) == bitSize; c += uint64(N << 1) {
+				require.False(t, IsPrime(c), c)
+			}
+		}
+
+		// n is not required to be a power of two
+		for _, n := range []int{3, 5, 6, 12, 3 * N} {
+			for _, bitSize := range []int{10, 30, 61} {
+				if q, err := FindNTTPrime(bitSize, n); err == nil {
+					require.Equal(t, bitSize, bits.Len64(q))
+					require.True(t, IsNTTFriendly(q, n), q)
+					for c := q + uint64(n<<1); bits.Len64(c) == bitSize; c += uint64(n << 1) {
+						require.False(t, IsPrime(c), c)
+					}
+				}
+			}
+		}
+		q, err := FindNTTPrime(10, 3)
+		require.NoError(t, err)
+		require.True(t, IsNTTFriendly(q, 3), q)
+
+		_, err = FindNTTPrime(62, N)
+		require.Error(t, err)
+		_, err = FindNTTPrime(bits.Len64(uint64(N<<1))-1, N)
+		require.Error(t, err)
+	})
 }
 
 func testImportExportPolyString(testContext *testParams, t *testing.T) {
@@ -296,6 +380,71 @@ func testDivRoundByLastModulusMany(testContext *testParams, t *testing.T) {
 	})
 }
 
+func testDivRoundBySpecificModulus(testContext *testParams, t *testing.T) {
+
+	t.Run(testString("DivRoundBySpecificModulus/", testContext.ringQ), func(t *testing.T) {
+
+		ringQ := testContext.ringQ
+		level := len(ringQ.Modulus) - 1
+
+		if level < 1 {
+			t.Skip("#Qi < 2")
+		}
+
+		coeffs := make([]*big.Int, ringQ.N)
+		for i := 0; i < ringQ.N; i++ {
+			coeffs[i] = RandInt(ringQ.ModulusBigint)
+		}
+
+		polTest0 := ringQ.NewPoly()
+		polTest1 := ringQ.NewPoly()
+
+		for modulusIndex := 0; modulusIndex < level+1; modulusIndex++ {
+
+			ringQ.SetCoefficientsBigint(coeffs, polTest0)
+			ringQ.DivRoundBySpecificModulusLvl(level, modulusIndex, polTest0, polTest1)
+
+			qi := NewUint(ringQ.Modulus[modulusIndex])
+
+			for i := 0; i < ringQ.N; i++ {
+
+				want := new(big.Int).Set(coeffs[i])
+				DivRound(want, qi, want)
+
+				for j := 0; j < level; j++ {
+
+					idx := j
+					if j >= modulusIndex {
+						idx++
+					}
+
+					wantj := new(big.Int).Mod(want, NewUint(ringQ.Modulus[idx])).Uint64()
+					require.Equalf(t, wantj, polTest1.Coeffs[j][i], "coeff %v Qi%v = %s", i, idx, coeffs[i].String())
+				}
+			}
+		}
+
+		// Matches the standard rescale when the modulus is the last one, also in place
+		polWant := ringQ.NewPoly()
+		ringQ.SetCoefficientsBigint(coeffs, polTest0)
+		ringQ.DivRoundByLastModulusLvl(level, polTest0, polWant)
+		ringQ.SetCoefficientsBigint(coeffs, polTest0)
+		ringQ.DivRoundBySpecificModulusLvl(level, level, polTest0, polTest0)
+		for j := 0; j < level; j++ {
+			require.Equal(t, polWant.Coeffs[j], polTest0.Coeffs[j])
+		}
+
+		// In place with a limb other than the last one
+		ringQ.SetCoefficientsBigint(coeffs, polTest0)
+		ringQ.DivRoundBySpecificModulusLvl(level, 0, polTest0, polTest1)
+		ringQ.SetCoefficientsBigint(coeffs, polTest0)
+		ringQ.DivRoundBySpecificModulusLvl(level, 0, polTest0, polTest0)
+		for j := 0; j < level; j++ {
+			require.Equal(t, polTest1.Coeffs[j], polTest0.Coeffs[j])
+		}
+	})
+}
+
 func testMarshalBinary(testContext *testParams, t *testing.T) {
 
 	t.Run(testString("MarshalBinary/Ring/", testContext.ringQ), func(t *testing.T) {
@@ -344,6 +493,105 @@ func testUniformSampler(testContext *testParams, t *testing.T) {
 			}
 		}
 	})
+
+	t.Run(testString("UniformSampler/ReadTweaked/", testContext.ringQ), func(t *testing.T) {
+		ringQ := testContext.ringQ
+		level := len(ringQ.Modulus) - 1
+		key := []byte("ReadTweaked test key")
+
+		prng0, _ := utils.NewKeyedPRNG(key)
+		prng1, _ := utils.NewKeyedPRNG(key)
+		sampler0 := NewUniformSampler(prng0, ringQ)
+		sampler1 := NewUniformSampler(prng1, ringQ)
+
+		// the state of the sampler does not change the output
+		sampler1.Read(ringQ.NewPoly())
+
+		rows, cols := 2, 3
+		crp := make([][]*Poly, rows)
+		for i := range crp {
+			crp[i] = make([]*Poly, cols)
+			for j := range crp[i] {
+				crp[i][j] = ringQ.NewPoly()
+				sampler0.ReadTweaked(uint64(i*cols+j), level, crp[i][j])
+				for k, qi := range ringQ.Modulus {
+					for _, c := range crp[i][j].Coeffs[k] {
+						require.Less(t, c, qi)
+					}
+				}
+			}
+		}
+
+		// in reverse order from the other party
+		for i := rows - 1; i >= 0; i-- {
+			for j := cols - 1; j >= 0; j-- {
+				pol := ringQ.NewPoly()
+				sampler1.ReadTweaked(uint64(i*cols+j), level, pol)
+				require.True(t, ringQ.Equal(crp[i][j], pol))
+			}
+		}
+
+		require.False(t, ringQ.Equal(crp[0][0], crp[0][1]))
+
+		// ReadTweaked does not advance the sampler
+		prng2, _ := utils.NewKeyedPRNG(key)
+		want, have := ringQ.NewPoly(), ringQ.NewPoly()
+		NewUniformSampler(prng2, ringQ).Read(want)
+		sampler0.Read(have)
+		require.True(t, ringQ.Equal(want, have))
+
+		if level > 0 {
+			require.Panics(t, func() { sampler0.ReadTweaked(0, level, ringQ.NewPolyLvl(level-1)) })
+		}
+	})
+
+	t.Run(testString("SparseUniformSampler/", testContext.ringQ), func(t *testing.T) {
+
+		ringQ := testContext.ringQ
+		N := ringQ.N
+		level := len(ringQ.Modulus) - 1
+
+		for _, weight := range []int{0, 1, 64, N >> 1, N} {
+
+			sampler := NewSparseUniformSampler(testContext.prng, ringQ, weight)
+			hits := make([]int, N)
+
+			for trial := 0; trial < 64; trial++ {
+
+				pol := sampler.ReadNew()
+
+				var nonZero int
+				inRange := true
+				for i := 0; i < N; i++ {
+					var isNonZero bool
+					for j, qi := range ringQ.Modulus {
+						inRange = inRange && pol.Coeffs[j][i] < qi
+						isNonZero = isNonZero || pol.Coeffs[j][i] != 0
+					}
+					if isNonZero {
+						nonZero++
+						hits[i]++
+					}
+				}
+
+				require.True(t, inRange)
+				require.Equal(t, weight, nonZero)
+			}
+
+			// With weight N/2, the probability that a position is never hit over 64 trials is 2^-64.
+			if weight == N>>1 {
+				for i := range hits {
+					require.NotZero(t, hits[i])
+				}
+			}
+		}
+
+		pol := ringQ.NewPoly()
+		NewSparseUniformSampler(testContext.prng, ringQ, 16).ReadLvl(level-1, pol)
+		for i := range pol.Coeffs[level] {
+			require.Zero(t, pol.Coeffs[level][i])
+		}
+	})
 }
 
 func testGaussianSampler(testContext *testParams, t *testing.T) {
@@ -360,6 +608,91 @@ func testGaussianSampler(testContext *testParams, t *testing.T) {
 	})
 }
 
+func testErrorSamplers(testContext *testParams, t *testing.T) {
+
+	for _, sampler := range []struct {
+		name string
+		ErrorSampler
+	}{
+		{"GaussianSampler", NewGaussianSampler(testContext.prng, testContext.ringQ, DefaultSigma, DefaultBound)},
+		{"RoundedGaussianSampler", NewRoundedGaussianSampler(testContext.prng, testContext.ringQ, DefaultSigma, DefaultBound)},
+		{"CenteredBinomialSampler", NewCenteredBinomialSampler(testContext.prng, testContext.ringQ, DefaultSigma, DefaultBound)},
+	} {
+		t.Run(testString("ErrorSampler/"+sampler.name+"/", testContext.ringQ), func(t *testing.T) {
+
+			pol := testContext.ringQ.NewPoly()
+			sampler.Read(pol)
+
+			q := testContext.ringQ.Modulus[0]
+
+			var sum, sumSquares float64
+			for i := 0; i < testContext.ringQ.N; i++ {
+
+				c := float64(pol.Coeffs[0][i] % q)
+				if pol.Coeffs[0][i] > q>>1 {
+					c = -float64(q - pol.Coeffs[0][i])
+				}
+
+				require.LessOrEqual(t, math.Abs(c), float64(DefaultBound))
+
+				for j, qj := range testContext.ringQ.Modulus {
+					if c < 0 {
+						require.Equal(t, (qj-uint64(-c))%qj, pol.Coeffs[j][i]%qj)
+					} else {
+						require.Equal(t, uint64(c), pol.Coeffs[j][i]%qj)
+					}
+				}
+
+				sum += c
+				sumSquares += c * c
+			}
+
+			N := float64(testContext.ringQ.N)
+			mean := sum / N
+			stddev := math.Sqrt(sumSquares/N - mean*mean)
+
+			// the standard error of the empirical standard deviation is about sigma/sqrt(2N)
+			require.InDelta(t, DefaultSigma, stddev, 8*DefaultSigma/math.Sqrt(2*N)+0.05)
+
+			// ReadAndAddLvl on the zero polynomial must sample from the same distribution
+			pol.Zero()
+			sampler.ReadAndAddLvl(0, pol)
+			for i := 0; i < testContext.ringQ.N; i++ {
+				require.False(t, uint64(DefaultBound) < pol.Coeffs[0][i] && pol.Coeffs[0][i] < (q-uint64(DefaultBound)))
+			}
+		})
+	}
+}
+
+func testSamplersLevelBounds(testContext *testParams, t *testing.T) {
+
+	t.Run(testString("Sampler/ReadLvl/LevelBounds/", testContext.ringQ), func(t *testing.T) {
+
+		ringQ := testContext.ringQ
+		maxLevel := len(ringQ.Modulus) - 1
+
+		gaussianSampler := NewGaussianSampler(testContext.prng, ringQ, DefaultSigma, DefaultBound)
+
+		readLvl := map[string]func(level int, pol *Poly){
+			"UniformSampler":          NewUniformSampler(testContext.prng, ringQ).ReadLvl,
+			"TernarySampler":          NewTernarySampler(testContext.prng, ringQ, 1.0/3, false).ReadLvl,
+			"GaussianSampler":         gaussianSampler.ReadLvl,
+			"GaussianSampler/AndAdd":  gaussianSampler.ReadAndAddLvl,
+			"RoundedGaussianSampler":  NewRoundedGaussianSampler(testContext.prng, ringQ, DefaultSigma, DefaultBound).ReadLvl,
+			"CenteredBinomialSampler": NewCenteredBinomialSampler(testContext.prng, ringQ, DefaultSigma, DefaultBound).ReadLvl,
+		}
+
+		for name, read := range readLvl {
+			require.NotPanics(t, func() { read(maxLevel, ringQ.NewPoly()) }, name)
+			require.Panics(t, func() { read(maxLevel+1, ringQ.NewPoly()) }, name)
+			require.Panics(t, func() { read(-1, ringQ.NewPoly()) }, name)
+			if maxLevel > 0 {
+				require.Panics(t, func() { read(maxLevel, ringQ.NewPolyLvl(maxLevel-1)) }, name)
+			}
+		}
+	})
+}
+
 func testTernarySampler(testContext *testParams, t *testing.T) {
 
 	for _, p := range []float64{.5, 1. / 3., 128. / 65536.} {
@@ -0,0 +1,124 @@
+package rlwe
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+)
+
+// CoeffEncoder is a type for encoding real values directly on the coefficients of a plaintext polynomial,
+// as opposed to the slots of the canonical embedding. The product of two encoded plaintexts is the encoding
+// of the negacyclic convolution of their values, with the product of their scales, which makes the encoding
+// suitable for convolution-style computations.
+//
+// A CoeffEncoder holds temporary buffers and cannot be used concurrently (see ShallowCopy).
+type CoeffEncoder struct {
+	params       Parameters
+	buff         *ring.Poly
+	bigintCoeffs []*big.Int
+}
+
+// NewCoeffEncoder creates a new CoeffEncoder from the provided parameters.
+func NewCoeffEncoder(params Parameters) *CoeffEncoder {
+
+	bigintCoeffs := make([]*big.Int, params.N())
+	for i := range bigintCoeffs {
+		bigintCoeffs[i] = new(big.Int)
+	}
+
+	return &CoeffEncoder{
+		params:       params,
+		buff:         params.RingQ().NewPoly(),
+		bigintCoeffs: bigintCoeffs,
+	}
+}
+
+// ShallowCopy creates a shallow copy of the CoeffEncoder in which the temporary buffers are reallocated.
+// The receiver and the returned CoeffEncoder can be used concurrently.
+func (ecd *CoeffEncoder) ShallowCopy() *CoeffEncoder {
+	return NewCoeffEncoder(ecd.params)
+}
+
+// Encode encodes at most N values on the coefficients of the plaintext, at its level: the i-th coefficient is
+// round(values[i] * scale) and the coefficients after len(values) are zero. The plaintext is left in the domain
+// given by its IsNTT flag. The method panics before modifying the plaintext if there are more than N values or if
+// a scaled value is not finite or not smaller than Q/2 in absolute value, with Q the modulus at the level of the
+// plaintext, in which case it would wrap around.
+func (ecd *CoeffEncoder) Encode(values []float64, scale float64, pt *Plaintext) {
+
+	if len(values) > ecd.params.N() {
+		panic(fmt.Errorf("cannot Encode: too many values (maximum is N=%d)", ecd.params.N()))
+	}
+
+	ringQ := ecd.params.RingQ()
+	level := pt.Level()
+
+	QHalf := ring.NewUint(1)
+	for _, qi := range ringQ.Modulus[:level+1] {
+		QHalf.Mul(QHalf, ring.NewUint(qi))
+	}
+	QHalf.Rsh(QHalf, 1)
+
+	scaleFlo := new(big.Float).SetPrec(128).SetFloat64(scale)
+	xFlo := new(big.Float).SetPrec(128)
+
+	for i := range ecd.bigintCoeffs {
+
+		if i >= len(values) {
+			ecd.bigintCoeffs[i].SetUint64(0)
+			continue
+		}
+
+		if x := values[i] * scale; math.IsNaN(x) || math.IsInf(x, 0) {
+			panic(fmt.Errorf("cannot Encode: values[%d]=%v scaled by %v is not finite", i, values[i], scale))
+		}
+
+		// round(values[i] * scale), half away from zero
+		xFlo.SetFloat64(values[i])
+		xFlo.Mul(xFlo, scaleFlo)
+		if xFlo.Sign() < 0 {
+			xFlo.Sub(xFlo, big.NewFloat(0.5))
+		} else {
+			xFlo.Add(xFlo, big.NewFloat(0.5))
+		}
+		xFlo.Int(ecd.bigintCoeffs[i])
+
+		if new(big.Int).Abs(ecd.bigintCoeffs[i]).Cmp(QHalf) >= 0 {
+			panic(fmt.Errorf("cannot Encode: values[%d]=%v scaled by %v is not smaller than Q/2", i, values[i], scale))
+		}
+	}
+
+	ringQ.SetCoefficientsBigintLvl(level, ecd.bigintCoeffs, pt.Value)
+
+	if pt.Value.IsNTT {
+		ringQ.NTTLvl(level, pt.Value, pt.Value)
+	}
+}
+
+// Decode decodes the coefficients of the plaintext, at its level and in the domain given by its IsNTT flag, on a new
+// slice of N values: the i-th value is the i-th coefficient, centered modulo Q, divided by scale.
+func (ecd *CoeffEncoder) Decode(pt *Plaintext, scale float64) (values []float64) {
+
+	ringQ := ecd.params.RingQ()
+	level := pt.Level()
+
+	if pt.Value.IsNTT {
+		ringQ.InvNTTLvl(level, pt.Value, ecd.buff)
+	} else {
+		ring.CopyValuesLvl(level, pt.Value, ecd.buff)
+	}
+
+	ringQ.PolyToBigintCenteredLvl(level, ecd.buff, 1, ecd.bigintCoeffs)
+
+	scaleFlo := new(big.Float).SetFloat64(scale)
+	xFlo := new(big.Float)
+
+	values = make([]float64, len(ecd.bigintCoeffs))
+	for i, c := range ecd.bigintCoeffs {
+		values[i], _ = xFlo.Quo(xFlo.SetInt(c), scaleFlo).Float64()
+	}
+
+	return
+}
@@ -1,10 +1,14 @@
 package rlwe
 
 import (
+	"encoding/binary"
+	"fmt"
+	"math"
 	"math/big"
 
 	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/utils"
+	"golang.org/x/crypto/blake2b"
 )
 
 // Plaintext is a common base type for RLWE plaintexts.
@@ -13,6 +17,7 @@ type Plaintext struct {
 }
 
 // Ciphertext is a generic type for RLWE ciphertext.
+// A ciphertext of degree 1 is the pair (b, a) = (Value[0], Value[1]), which decrypts as b + a*s.
 type Ciphertext struct {
 	Value []*ring.Poly
 }
@@ -77,6 +82,33 @@ func (pt *Plaintext) Copy(other *Plaintext) {
 	}
 }
 
+// IsConstant returns true if the plaintext polynomial is a constant, that is, if all its coefficients but
+// the constant one are zero, or, if it is in the NTT domain, if all its coefficients are equal.
+// In both cases, the constant is pt.Value.Coeffs[i][0] for the i-th modulus.
+func (pt *Plaintext) IsConstant() bool {
+	for _, coeffs := range pt.Value.Coeffs {
+		for _, c := range coeffs[1:] {
+			if (pt.Value.IsNTT && c != coeffs[0]) || (!pt.Value.IsNTT && c != 0) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// TruncateLevel drops the moduli of the receiver above `level`, releasing their
+// memory. Since the Encryptor encrypts at min(pt.Level(), ct.Level()) and resizes
+// the output accordingly, a ciphertext encrypting a truncated plaintext will be at
+// level at most `level`, unless the Encryptor has the KeepFullLevel option.
+func (pt *Plaintext) TruncateLevel(level int) {
+	if level < 0 || level > pt.Level() {
+		panic(fmt.Sprintf("cannot TruncateLevel: level must be in [0, %d] but is %d", pt.Level(), level))
+	}
+	coeffs := make([][]uint64, level+1)
+	copy(coeffs, pt.Value.Coeffs[:level+1])
+	pt.Value.Coeffs = coeffs
+}
+
 // NewCiphertext returns a new Element with zero values.
 func NewCiphertext(params Parameters, degree, level int) *Ciphertext {
 	el := new(Ciphertext)
@@ -106,10 +138,52 @@ func NewCiphertextRandom(prng utils.PRNG, params Parameters, degree, level int)
 }
 
 // SetValue sets the input slice of polynomials as the value of the target element.
+// The degree of the target element becomes len(value)-1. The method panics if value
+// is empty, or if its polynomials do not share the same level and NTT domain.
 func (el *Ciphertext) SetValue(value []*ring.Poly) {
+
+	if len(value) == 0 {
+		panic("cannot SetValue: value must contain at least one polynomial")
+	}
+
+	for i := range value {
+
+		if value[i] == nil {
+			panic("cannot SetValue: value cannot contain nil polynomials")
+		}
+
+		if value[i].Level() != value[0].Level() {
+			panic("cannot SetValue: all polynomials must have the same level")
+		}
+
+		if value[i].IsNTT != value[0].IsNTT {
+			panic("cannot SetValue: all polynomials must be in the same domain (NTT or coefficient)")
+		}
+	}
+
 	el.Value = value
 }
 
+// GetValue returns the slice of polynomials of the target element.
+func (el *Ciphertext) GetValue() []*ring.Poly {
+	return el.Value
+}
+
+// B returns a reference to the polynomial b = Value[0] of the target element, i.e. the component
+// that is not multiplied by the secret-key during the decryption.
+func (el *Ciphertext) B() *ring.Poly {
+	return el.Value[0]
+}
+
+// A returns a reference to the polynomial a = Value[1] of the target element, i.e. the mask that is
+// multiplied by the secret-key during the decryption. The method panics if the element is of degree 0.
+func (el *Ciphertext) A() *ring.Poly {
+	if el.Degree() < 1 {
+		panic("cannot A: element must be of degree at least 1")
+	}
+	return el.Value[1]
+}
+
 // Degree returns the degree of the target element.
 func (el *Ciphertext) Degree() int {
 	return len(el.Value) - 1
@@ -138,6 +212,183 @@ func (el *Ciphertext) Resize(params Parameters, degree int) {
 	}
 }
 
+// CanonicalizeNTT brings all the polynomials of the target element in the domain given by
+// targetNTT (NTT if true, coefficient otherwise), transforming with ringQ only the polynomials
+// that are in the other domain, and sets their IsNTT flag accordingly.
+func (el *Ciphertext) CanonicalizeNTT(ringQ *ring.Ring, targetNTT bool) {
+	for _, pol := range el.Value {
+		if pol.IsNTT != targetNTT {
+			if targetNTT {
+				ringQ.NTTLvl(pol.Level(), pol, pol)
+			} else {
+				ringQ.InvNTTLvl(pol.Level(), pol, pol)
+			}
+			pol.IsNTT = targetNTT
+		}
+	}
+}
+
+// Digest returns a 128-bit BLAKE2b digest of the degree, the level and the coefficients of the target element.
+// The polynomials in the NTT domain are first brought in the coefficient domain with ringQ, without modifying the
+// element, such that two elements whose polynomials are equal up to their domain have the same digest. It is
+// intended to detect identical ciphertexts, e.g. for memoization, and not as a commitment to the ciphertext.
+func (el *Ciphertext) Digest(ringQ *ring.Ring) (digest [16]byte) {
+
+	h, err := blake2b.New(16, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	header := make([]byte, 16)
+	binary.BigEndian.PutUint64(header[:8], uint64(el.Degree()))
+	binary.BigEndian.PutUint64(header[8:], uint64(el.Level()))
+	h.Write(header)
+
+	buff := make([]byte, 8*el.Value[0].Degree())
+
+	for _, pol := range el.Value {
+
+		coeffs := pol
+		if pol.IsNTT {
+			coeffs = ringQ.NewPolyLvl(pol.Level())
+			ringQ.InvNTTLvl(pol.Level(), pol, coeffs)
+		}
+
+		for _, limb := range coeffs.Coeffs {
+			for j, c := range limb {
+				binary.BigEndian.PutUint64(buff[8*j:], c)
+			}
+			h.Write(buff)
+		}
+	}
+
+	copy(digest[:], h.Sum(nil))
+
+	return
+}
+
+// AggregateCiphertexts sums the ciphertexts of cts and writes the result on out, which is used as the accumulator
+// and can be one of the ciphertexts of cts. The sum is computed at the minimum level of cts and out, and out is
+// truncated to this level. The polynomials of the ciphertexts must have the ring degree of the parameters and
+// must all be in or all be out of the NTT domain, and out must have the degree of the largest ciphertext of cts.
+// The inputs are validated before out is modified.
+//
+// The coefficients are added without modular reduction and are only reduced when the accumulator could overflow,
+// which happens every floor((2^64-1)/qmax) ciphertexts for qmax the largest modulus, and once at the end.
+func AggregateCiphertexts(params Parameters, cts []*Ciphertext, out *Ciphertext) (err error) {
+
+	if len(cts) == 0 {
+		return fmt.Errorf("cts cannot be empty")
+	}
+
+	if out == nil || len(out.Value) == 0 {
+		return fmt.Errorf("out cannot be nil")
+	}
+
+	N, level, degree := params.N(), out.Level(), 0
+
+	var isNTT bool
+	first := -1
+
+	for i, ct := range cts {
+
+		if ct == nil || len(ct.Value) == 0 {
+			return fmt.Errorf("cts[%d] cannot be nil", i)
+		}
+
+		if i == 0 {
+			isNTT = ct.Value[0].IsNTT
+		}
+
+		if ct == out {
+			if first != -1 {
+				return fmt.Errorf("out appears more than once in cts")
+			}
+			first = i
+		}
+
+		for _, pol := range ct.Value {
+			if pol.Degree() != N {
+				return fmt.Errorf("cts[%d] ring degree does not match params ring degree", i)
+			}
+			if pol.IsNTT != isNTT {
+				return fmt.Errorf("cts[%d] does not match the NTT domain of cts[0]", i)
+			}
+		}
+
+		level = utils.MinInt(level, ct.Level())
+		degree = utils.MaxInt(degree, ct.Degree())
+	}
+
+	if out.Degree() != degree {
+		return fmt.Errorf("out degree does not match the largest degree of cts")
+	}
+
+	for _, pol := range out.Value {
+		if pol.Degree() != N {
+			return fmt.Errorf("out ring degree does not match params ring degree")
+		}
+	}
+
+	ringQ := params.RingQ()
+
+	// The accumulator is initialized with out if it is in cts, else with a copy of cts[0].
+	if first == -1 {
+		first = 0
+		for i := range out.Value {
+			if i < len(cts[0].Value) {
+				ring.CopyValuesLvl(level, cts[0].Value[i], out.Value[i])
+			} else {
+				out.Value[i].Zero()
+			}
+		}
+	}
+
+	for _, pol := range out.Value {
+		pol.Coeffs = pol.Coeffs[:level+1]
+		pol.IsNTT = isNTT
+	}
+
+	var qmax uint64
+	for _, qi := range ringQ.Modulus[:level+1] {
+		if qi > qmax {
+			qmax = qi
+		}
+	}
+
+	// Maximum number of values smaller than qmax that can be summed without overflow.
+	maxSummands := math.MaxUint64 / qmax
+
+	summands := uint64(1)
+	for i, ct := range cts {
+
+		if i == first {
+			continue
+		}
+
+		if summands == maxSummands {
+			for _, pol := range out.Value {
+				ringQ.ReduceLvl(level, pol, pol)
+			}
+			summands = 1
+		}
+
+		for j := range ct.Value {
+			ringQ.AddNoModLvl(level, out.Value[j], ct.Value[j], out.Value[j])
+		}
+
+		summands++
+	}
+
+	if summands > 1 {
+		for _, pol := range out.Value {
+			ringQ.ReduceLvl(level, pol, pol)
+		}
+	}
+
+	return nil
+}
+
 // SwitchCiphertextRingDegreeNTT changes the ring degree of ctIn to the one of ctOut.
 // Maps Y^{N/n} -> X^{N} or X^{N} -> Y^{N/n}.
 // If the ring degree of ctOut is larger than the one of ctIn, then the ringQ of ctIn
@@ -152,7 +403,7 @@ func SwitchCiphertextRingDegreeNTT(ctIn *Ciphertext, ringQSmallDim, ringQLargeDi
 		pool := make([]uint64, NIn)
 		for i := range ctOut.Value {
 			for j := range ctOut.Value[i].Coeffs {
-				tmpIn, tmpOut := ctIn.Value[i].Coeffs[j], ctIn.Value[i].Coeffs[j]
+				tmpIn, tmpOut := ctIn.Value[i].Coeffs[j], ctOut.Value[i].Coeffs[j]
 				ringQLargeDim.InvNTTSingle(j, tmpIn, pool)
 				for w0, w1 := 0, 0; w0 < NOut; w0, w1 = w0+1, w1+gap {
 					tmpOut[w0] = pool[w1]
@@ -223,6 +474,142 @@ func (el *Ciphertext) RLWEElement() *Ciphertext {
 	return el
 }
 
+// LWECiphertext is a generic type for LWE ciphertexts in RNS representation: B[i] and A[i] are the
+// residues modulo Q[i] of the sample (b, a), whose decryption is b + <a, s> with s the vector of the
+// coefficients of the RLWE secret.
+type LWECiphertext struct {
+	B []uint64
+	A [][]uint64
+}
+
+// Level returns the level of the LWE ciphertext.
+func (lwe *LWECiphertext) Level() int {
+	return len(lwe.B) - 1
+}
+
+// ExtractLWE returns the LWE ciphertext, at the level of the receiver, encrypting the coeffIndex-th coefficient
+// of the message c0 + c1*s of the receiver. Since this coefficient is c0[k] + sum_{j<=k} c1[k-j]*s[j] -
+// sum_{j>k} c1[N+k-j]*s[j], the LWE ciphertext is b = c0[k] and a = (c1[k], ..., c1[0], -c1[N-1], ..., -c1[k+1]).
+// If the receiver is in the NTT domain, it is first brought back to the coefficient domain without being modified.
+// The method panics if the receiver is not of degree 1, if coeffIndex is not in [0, N) or if the ring type is
+// not ring.Standard.
+func (el *Ciphertext) ExtractLWE(params Parameters, coeffIndex int) (lwe *LWECiphertext) {
+
+	if el.Degree() != 1 {
+		panic("cannot ExtractLWE: ciphertext must be of degree 1")
+	}
+
+	if params.RingType() != ring.Standard {
+		panic("cannot ExtractLWE: only supported for ring.Standard")
+	}
+
+	N := params.N()
+
+	if coeffIndex < 0 || coeffIndex >= N {
+		panic(fmt.Sprintf("cannot ExtractLWE: coeffIndex %d is not in [0, %d)", coeffIndex, N))
+	}
+
+	ringQ := params.RingQ()
+	level := el.Level()
+
+	c0, c1 := el.Value[0], el.Value[1]
+	if c0.IsNTT {
+		c0, c1 = ringQ.NewPolyLvl(level), ringQ.NewPolyLvl(level)
+		ringQ.InvNTTLvl(level, el.Value[0], c0)
+		ringQ.InvNTTLvl(level, el.Value[1], c1)
+	}
+
+	lwe = &LWECiphertext{B: make([]uint64, level+1), A: make([][]uint64, level+1)}
+
+	for i, qi := range ringQ.Modulus[:level+1] {
+
+		lwe.B[i] = c0.Coeffs[i][coeffIndex]
+
+		a, c1i := make([]uint64, N), c1.Coeffs[i]
+		for j := 0; j <= coeffIndex; j++ {
+			a[j] = c1i[coeffIndex-j]
+		}
+		for j := coeffIndex + 1; j < N; j++ {
+			a[j] = (qi - c1i[N+coeffIndex-j]) % qi
+		}
+		lwe.A[i] = a
+	}
+
+	return
+}
+
+// TransposedCiphertext is a generic type for RLWE ciphertexts stored in a limb-major layout:
+// for each element of the ciphertext, the residues of the i-th coefficient modulo all the
+// moduli of the chain are contiguous in memory, i.e. Value[k][j*(Level()+1)+i] is the j-th
+// coefficient modulo Q[i] of the k-th element of the ciphertext.
+type TransposedCiphertext struct {
+	Value [][]uint64
+	N     int
+	IsNTT bool
+}
+
+// NewTransposedCiphertext returns a new TransposedCiphertext with zero values.
+func NewTransposedCiphertext(params Parameters, degree, level int) *TransposedCiphertext {
+	ct := &TransposedCiphertext{Value: make([][]uint64, degree+1), N: params.N()}
+	for i := range ct.Value {
+		ct.Value[i] = make([]uint64, params.N()*(level+1))
+	}
+	return ct
+}
+
+// Degree returns the degree of the target element.
+func (ct *TransposedCiphertext) Degree() int {
+	return len(ct.Value) - 1
+}
+
+// Level returns the level of the target element.
+func (ct *TransposedCiphertext) Level() int {
+	return len(ct.Value[0])/ct.N - 1
+}
+
+// Transpose writes el in the limb-major layout on the receiver.
+// The method panics if the degree, level or ring degree of el do not match the ones of the receiver.
+func (ct *TransposedCiphertext) Transpose(el *Ciphertext) {
+
+	if el.Degree() != ct.Degree() || el.Level() != ct.Level() || el.Value[0].Degree() != ct.N {
+		panic("cannot Transpose: receiver and input do not have the same degree, level or ring degree")
+	}
+
+	nbLimbs := ct.Level() + 1
+
+	for k, pol := range el.Value {
+		v := ct.Value[k]
+		for i, coeffs := range pol.Coeffs {
+			for j, c := range coeffs {
+				v[j*nbLimbs+i] = c
+			}
+		}
+	}
+
+	ct.IsNTT = el.Value[0].IsNTT
+}
+
+// Untranspose writes the receiver in the default coefficient-major layout on el.
+// The method panics if the degree, level or ring degree of el do not match the ones of the receiver.
+func (ct *TransposedCiphertext) Untranspose(el *Ciphertext) {
+
+	if el.Degree() != ct.Degree() || el.Level() != ct.Level() || el.Value[0].Degree() != ct.N {
+		panic("cannot Untranspose: receiver and input do not have the same degree, level or ring degree")
+	}
+
+	nbLimbs := ct.Level() + 1
+
+	for k, pol := range el.Value {
+		v := ct.Value[k]
+		for i, coeffs := range pol.Coeffs {
+			for j := range coeffs {
+				coeffs[j] = v[j*nbLimbs+i]
+			}
+		}
+		pol.IsNTT = ct.IsNTT
+	}
+}
+
 // GetSmallestLargest returns the provided element that has the smallest degree as a first
 // returned value and the largest degree as second return value. If the degree match, the
 // order is the same as for the input.
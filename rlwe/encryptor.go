@@ -1,6 +1,10 @@
 package rlwe
 
 import (
+	"encoding/binary"
+	"fmt"
+	"sync/atomic"
+
 	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/utils"
 )
@@ -8,9 +12,17 @@ import (
 // Encryptor a generic RLWE encryption interface.
 type Encryptor interface {
 	Encrypt(pt *Plaintext, ct *Ciphertext)
+	EncryptNew(pt *Plaintext) *Ciphertext
 	EncryptFromCRP(pt *Plaintext, crp *ring.Poly, ct *Ciphertext)
+	EncryptTransposed(pt *Plaintext, ct *TransposedCiphertext)
+	EncryptIndexed(index uint64, pt *Plaintext, ct *Ciphertext)
+	EncryptWithWitness(pt *Plaintext, ct *Ciphertext) (w *EncryptionWitness)
+	ShareCiphertext(ct *Ciphertext) (share0, share1 *Ciphertext)
 	ShallowCopy() Encryptor
 	WithKey(key interface{}) Encryptor
+	MarshalState() (data []byte, err error)
+	UnmarshalState(data []byte) (err error)
+	EntropyConsumed() uint64
 }
 
 type encryptor struct {
@@ -18,6 +30,8 @@ type encryptor struct {
 	*encryptorSamplers
 	*encryptorBuffers
 	basisextender *ring.BasisExtender
+	profiler      encryptProfiler
+	witness       *EncryptionWitness
 }
 
 type pkEncryptor struct {
@@ -30,23 +44,75 @@ type skEncryptor struct {
 	sk *SecretKey
 }
 
+// EncryptorOptions is a set of optional settings for the Encryptor.
+// Its zero value corresponds to the default Encryptor returned by NewEncryptor.
+type EncryptorOptions struct {
+	// NTTThreads is the number of goroutines across which the limbs of the NTTs
+	// over the ring QP are distributed during the public-key encryption.
+	// Values 0 and 1 disable the parallel NTT. Negative values select the number of
+	// goroutines with ring.AutoTuneNTTThreads on the rings Q and P of the parameters.
+	NTTThreads int
+
+	// BasisExtenderPool, if not nil, is the pool from which the public-key encryption draws a
+	// ring.BasisExtender for each encryption, instead of allocating one per Encryptor and per
+	// shallow copy. It must be created for the rings RingQ and RingP of the parameters.
+	BasisExtenderPool *ring.BasisExtenderPool
+
+	// DualErrorInjection, if true, makes the secret-key encryption also sample a small error and add it to the
+	// uniform mask Value[1] of the ciphertext, as the public-key encryption does. The decryption noise then
+	// gains the term e1*s. It has no effect on EncryptFromCRP, whose Value[1] must remain equal to the CRP.
+	DualErrorInjection bool
+
+	// PRNGKey, if not nil, is the key of the PRNG from which the Encryptor draws its randomness, instead of a key
+	// sampled from crypto/rand. Encryptors created with the same key produce the same sequence of ciphertexts, and
+	// the same ciphertext for a given index with EncryptIndexed. The key must be kept as secret as the keys.
+	PRNGKey []byte
+
+	// KeepFullLevel, if true, makes the encryption skip the final truncation of the ciphertext to the level
+	// min(pt.Level(), ct.Level()). The ciphertext then keeps its level and its allocated limbs, which avoids
+	// reallocating them in pipelines that bring it back to a higher level, but only its limbs up to the level of
+	// the plaintext are an encryption of the plaintext: the upper limbs are garbage until they are overwritten and
+	// the ciphertext must not be decrypted or evaluated at its full level.
+	KeepFullLevel bool
+}
+
 // NewEncryptor creates a new Encryptor
-// Accepts either a secret-key or a public-key.
+// Accepts either a secret-key or a public-key, possibly compressed as a *SeededPublicKey.
+// Public keys must be flagged as being in the NTT domain and in the standard form (see PublicKey.ToEvalForm).
 func NewEncryptor(params Parameters, key interface{}) Encryptor {
-	enc := newEncryptor(params)
+	return NewEncryptorWithOptions(params, key, EncryptorOptions{})
+}
+
+// NewEncryptorWithOptions creates a new Encryptor configured with the given options.
+// Accepts either a secret-key or a public-key.
+func NewEncryptorWithOptions(params Parameters, key interface{}, options EncryptorOptions) Encryptor {
+	enc := newEncryptor(params, options)
 	return enc.setKey(key)
 }
 
-func newEncryptor(params Parameters) encryptor {
+// NewEncryptorErr creates a new Encryptor from either a secret-key or a public-key.
+// Unlike NewEncryptor, it returns an error instead of panicking if the key is invalid.
+func NewEncryptorErr(params Parameters, key interface{}) (Encryptor, error) {
+	enc := newEncryptor(params, EncryptorOptions{})
+	return enc.setKeyErr(key)
+}
+
+func newEncryptor(params Parameters, options EncryptorOptions) encryptor {
+
+	if options.NTTThreads < 0 {
+		options.NTTThreads = ring.AutoTuneNTTThreads(params.RingQ(), params.RingP())
+	}
 
 	var bc *ring.BasisExtender
-	if params.PCount() != 0 {
+	if params.PCount() != 0 && options.BasisExtenderPool == nil {
 		bc = ring.NewBasisExtender(params.RingQ(), params.RingP())
 	}
 
+	base := newEncryptorBase(params, options)
+
 	return encryptor{
-		encryptorBase:     newEncryptorBase(params),
-		encryptorSamplers: newEncryptorSamplers(params),
+		encryptorBase:     base,
+		encryptorSamplers: newEncryptorSamplers(params, base.forkPRNG()),
 		encryptorBuffers:  newEncryptorBuffers(params),
 		basisextender:     bc,
 	}
@@ -54,50 +120,169 @@ func newEncryptor(params Parameters) encryptor {
 
 // encryptorBase is a struct used to encrypt Plaintexts. It stores the public-key and/or secret-key.
 type encryptorBase struct {
-	params Parameters
+	forks   uint64 // first field to ensure the 64-bit alignment required by atomic
+	params  Parameters
+	options EncryptorOptions
+	prng    *utils.KeyedPRNG
 }
 
-func newEncryptorBase(params Parameters) *encryptorBase {
-	return &encryptorBase{params}
+func newEncryptorBase(params Parameters, options EncryptorOptions) *encryptorBase {
+	var prng *utils.KeyedPRNG
+	var err error
+	if options.PRNGKey != nil {
+		prng, err = utils.NewKeyedPRNG(options.PRNGKey)
+	} else {
+		prng, err = utils.NewPRNG()
+	}
+	if err != nil {
+		panic(err)
+	}
+	return &encryptorBase{params: params, options: options, prng: prng}
+}
+
+// forkPRNG returns a new PRNG forked from the PRNG of the encryptorBase with the index of the
+// fork as label, such that the encryptor and each of its shallow copies sample from their own
+// independent stream, deterministically derived from a single key.
+func (enc *encryptorBase) forkPRNG() utils.PRNG {
+	label := make([]byte, 8)
+	binary.BigEndian.PutUint64(label, atomic.AddUint64(&enc.forks, 1)-1)
+	return enc.prng.Fork(label)
+}
+
+// indexedPRNG returns a new PRNG forked from the PRNG of the encryptorBase with a label derived from index.
+// The labels are domain-separated from those of forkPRNG, such that the PRNG of an index is independent of the
+// PRNGs of the shallow copies and of the PRNGs of the other indexes.
+func (enc *encryptorBase) indexedPRNG(index uint64) utils.PRNG {
+	label := append([]byte("EncryptIndexed"), make([]byte, 8)...)
+	binary.BigEndian.PutUint64(label[len(label)-8:], index)
+	return enc.prng.Fork(label)
 }
 
 type encryptorSamplers struct {
-	gaussianSampler *ring.GaussianSampler
-	ternarySampler  *ring.TernarySampler
-	uniformSampler  *ring.UniformSampler
+	prng           *utils.CountingPRNG
+	errorSampler   ring.ErrorSampler
+	ternarySampler *ring.TernarySampler
+	uniformSampler *ring.UniformSampler
 }
 
-func newEncryptorSamplers(params Parameters) *encryptorSamplers {
-	prng, err := utils.NewPRNG()
-	if err != nil {
-		panic(err)
+// newEncryptorSamplers creates the samplers of an encryptor, which all draw from fork through
+// a single CountingPRNG such that the entropy consumed by the encryptor can be measured.
+func newEncryptorSamplers(params Parameters, fork utils.PRNG) *encryptorSamplers {
+	prng := utils.NewCountingPRNG(fork)
+	return &encryptorSamplers{
+		prng:           prng,
+		errorSampler:   newErrorSampler(prng, params),
+		ternarySampler: ring.NewTernarySamplerWithHammingWeight(prng, params.ringQ, params.h, false),
+		uniformSampler: ring.NewUniformSampler(prng, params.RingQ()),
 	}
+}
 
-	return &encryptorSamplers{
-		gaussianSampler: ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), int(6*params.Sigma())),
-		ternarySampler:  ring.NewTernarySamplerWithHammingWeight(prng, params.ringQ, params.h, false),
-		uniformSampler:  ring.NewUniformSampler(prng, params.RingQ()),
+// MarshalState encodes the state of the samplers of the Encryptor, that is, the state of the PRNG they share
+// and the random bytes buffered by the error sampler. The ternary and uniform samplers do not keep random bytes
+// between two calls. An Encryptor on which this state is restored with UnmarshalState resumes the exact same
+// sequence of random samples. The state contains the key of the PRNG and must be kept as secret as the keys.
+func (enc *encryptorSamplers) MarshalState() (data []byte, err error) {
+
+	var prngState []byte
+	if prngState, err = enc.prng.MarshalBinary(); err != nil {
+		return nil, err
+	}
+
+	samplerState := enc.errorSampler.MarshalState()
+
+	buff := utils.NewBuffer(make([]byte, 0, 8+len(prngState)+len(samplerState)))
+	buff.WriteUint64(uint64(len(prngState)))
+	buff.WriteUint8Slice(prngState)
+	buff.WriteUint8Slice(samplerState)
+
+	return buff.Bytes(), nil
+}
+
+// UnmarshalState restores on the samplers of the Encryptor a state encoded by MarshalState.
+// The Encryptor must have been created with the same parameters as the one the state was taken from.
+func (enc *encryptorSamplers) UnmarshalState(data []byte) (err error) {
+
+	if len(data) < 8 {
+		return fmt.Errorf("cannot UnmarshalState: data is too short")
+	}
+
+	prngLen := binary.BigEndian.Uint64(data[:8])
+	if prngLen > uint64(len(data)-8) {
+		return fmt.Errorf("cannot UnmarshalState: invalid PRNG state length")
+	}
+
+	prngState, samplerState := data[8:8+prngLen], data[8+prngLen:]
+
+	// decodes the PRNG state on a scratch PRNG, such that the Encryptor is left
+	// unchanged if either of the states is invalid
+	prng := utils.NewCountingPRNG(new(utils.KeyedPRNG))
+	if err = prng.UnmarshalBinary(prngState); err != nil {
+		return err
+	}
+
+	// the sampler state is validated before being written
+	if err = enc.errorSampler.UnmarshalState(samplerState); err != nil {
+		return err
+	}
+
+	// the samplers share the pointer enc.prng, hence the state is restored by value
+	*enc.prng = *prng
+
+	return nil
+}
+
+// EntropyConsumed returns the number of random bytes drawn by the error, ternary and uniform samplers of the
+// Encryptor since its creation. Shallow copies of an Encryptor have their own samplers and their own count.
+func (enc *encryptorSamplers) EntropyConsumed() uint64 {
+	return enc.prng.BytesRead()
+}
+
+// newErrorSampler returns a sampler for the error distribution of the parameters.
+func newErrorSampler(prng utils.PRNG, params Parameters) ring.ErrorSampler {
+	bound := params.ErrorBound()
+	switch params.ErrorDistribution() {
+	case RoundedContinuous:
+		return ring.NewRoundedGaussianSampler(prng, params.RingQ(), params.Sigma(), bound)
+	case CenteredBinomial:
+		return ring.NewCenteredBinomialSampler(prng, params.RingQ(), params.Sigma(), bound)
+	default:
+		return ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), bound)
 	}
 }
 
 type encryptorBuffers struct {
-	poolQ [1]*ring.Poly
-	poolP [3]*ring.Poly
+	ringQ *ring.Ring
+	ringP *ring.Ring
+
+	// poolQ and poolP are allocated for the encryption into degree 1
+	// ciphertexts and are grown on demand by ensureBuffers.
+	poolQ []*ring.Poly
+	poolP []*ring.Poly
+
+	// ctTransposed is lazily allocated by EncryptTransposed.
+	ctTransposed *Ciphertext
 }
 
 func newEncryptorBuffers(params Parameters) *encryptorBuffers {
+	enc := &encryptorBuffers{ringQ: params.RingQ(), ringP: params.RingP()}
+	enc.ensureBuffers(1)
+	return enc
+}
 
-	ringQ := params.RingQ()
-	ringP := params.RingP()
+// ensureBuffers ensures that the pools hold enough polynomials for the encryption into
+// a ciphertext of degree n: one polynomial in Q and n+2 polynomials in P (one for each
+// element of the ciphertext and one for the encryption randomness). Polynomials are only
+// allocated when the pools are too small, thus the degree 1 case does not allocate.
+func (enc *encryptorBuffers) ensureBuffers(n int) {
 
-	var poolP [3]*ring.Poly
-	if params.PCount() != 0 {
-		poolP = [3]*ring.Poly{ringP.NewPoly(), ringP.NewPoly(), ringP.NewPoly()}
+	for len(enc.poolQ) < 1 {
+		enc.poolQ = append(enc.poolQ, enc.ringQ.NewPoly())
 	}
 
-	return &encryptorBuffers{
-		poolQ: [1]*ring.Poly{ringQ.NewPoly()},
-		poolP: poolP,
+	if enc.ringP != nil {
+		for len(enc.poolP) < n+2 {
+			enc.poolP = append(enc.poolP, enc.ringP.NewPoly())
+		}
 	}
 }
 
@@ -110,7 +295,7 @@ func newEncryptorBuffers(params Parameters) *encryptorBuffers {
 func (enc *pkEncryptor) Encrypt(pt *Plaintext, ct *Ciphertext) {
 	enc.uniformSampler.ReadLvl(utils.MinInt(pt.Level(), ct.Level()), ct.Value[1])
 
-	if enc.basisextender != nil {
+	if enc.params.PCount() != 0 {
 		enc.encrypt(pt, ct)
 	} else {
 		enc.encryptNoP(pt, ct)
@@ -125,19 +310,165 @@ func (enc *pkEncryptor) EncryptFromCRP(pt *Plaintext, crp *ring.Poly, ct *Cipher
 // Encrypt encrypts the input plaintext and write the result on ct.
 func (enc *skEncryptor) Encrypt(pt *Plaintext, ct *Ciphertext) {
 
-	enc.uniformSampler.ReadLvl(utils.MinInt(pt.Level(), ct.Level()), ct.Value[1])
+	levelQ := utils.MinInt(pt.Level(), ct.Level())
+
+	enc.uniformSampler.ReadLvl(levelQ, ct.Value[1])
 
 	enc.encrypt(pt, ct)
+
+	if enc.options.DualErrorInjection {
+		enc.addErrorToMask(levelQ, ct)
+	}
+}
+
+// addErrorToMask samples a small error and adds it to the mask Value[1] of ct, in the domain of ct.
+func (enc *skEncryptor) addErrorToMask(levelQ int, ct *Ciphertext) {
+	if ct.Value[1].IsNTT {
+		ringQ := enc.params.RingQ()
+		poolQ0 := enc.poolQ[0]
+		enc.errorSampler.ReadLvl(levelQ, poolQ0)
+		enc.witness.record(witnessE1, levelQ, poolQ0)
+		ringQ.NTTLvl(levelQ, poolQ0, poolQ0)
+		ringQ.AddLvl(levelQ, ct.Value[1], poolQ0, ct.Value[1])
+	} else {
+		enc.readAndAddError(levelQ, ct.Value[1], witnessE1)
+	}
 }
 
 // EncryptFromCRP encrypts the input plaintext and writes the result on ct.
-// The encryption algorithm depends on the implementor.
+// The crp is always interpreted as the mask in the NTT domain and is used as is, without any transform:
+// if ct is in the NTT domain, ct.Value[1] equals crp, else ct.Value[1] is the inverse NTT of crp.
 func (enc *skEncryptor) EncryptFromCRP(pt *Plaintext, crp *ring.Poly, ct *Ciphertext) {
 	ring.CopyValues(crp, ct.Value[1])
 
 	enc.encrypt(pt, ct)
 }
 
+// EncryptNew encrypts the input plaintext and returns the result in a newly allocated ciphertext of
+// degree 1, at the level of the plaintext and in the same domain as the plaintext.
+func (enc *pkEncryptor) EncryptNew(pt *Plaintext) (ct *Ciphertext) {
+	ct = enc.newCiphertext(pt)
+	enc.Encrypt(pt, ct)
+	return
+}
+
+// EncryptNew encrypts the input plaintext and returns the result in a newly allocated ciphertext of
+// degree 1, at the level of the plaintext and in the same domain as the plaintext.
+func (enc *skEncryptor) EncryptNew(pt *Plaintext) (ct *Ciphertext) {
+	ct = enc.newCiphertext(pt)
+	enc.Encrypt(pt, ct)
+	return
+}
+
+// ShareCiphertext splits ct into two additive shares such that share0 + share1 = ct.
+// The share share1 is a fresh encryption of zero under the stored public-key and share0 = ct - share1,
+// hence each share taken individually is independent of the message encrypted by ct.
+// The shares are at the level and in the domain of ct.
+func (enc *pkEncryptor) ShareCiphertext(ct *Ciphertext) (share0, share1 *Ciphertext) {
+	return enc.shareCiphertext(enc, ct)
+}
+
+// ShareCiphertext splits ct into two additive shares such that share0 + share1 = ct.
+// The share share1 is a fresh encryption of zero under the stored secret-key and share0 = ct - share1,
+// hence each share taken individually is independent of the message encrypted by ct.
+// The shares are at the level and in the domain of ct.
+func (enc *skEncryptor) ShareCiphertext(ct *Ciphertext) (share0, share1 *Ciphertext) {
+	return enc.shareCiphertext(enc, ct)
+}
+
+// shareCiphertext masks ct with an encryption of zero generated by encryptor.
+func (enc *encryptor) shareCiphertext(encryptor Encryptor, ct *Ciphertext) (share0, share1 *Ciphertext) {
+
+	if ct.Degree() < 1 {
+		panic("cannot ShareCiphertext: ct must be at least of degree 1")
+	}
+
+	level := ct.Level()
+
+	zero := NewPlaintext(enc.params, level)
+	zero.Value.IsNTT = ct.Value[0].IsNTT
+
+	share1 = NewCiphertext(enc.params, 1, level)
+	share1.Value[0].IsNTT = ct.Value[0].IsNTT
+	share1.Value[1].IsNTT = ct.Value[0].IsNTT
+	encryptor.Encrypt(zero, share1)
+
+	ringQ := enc.params.RingQ()
+
+	share0 = ct.CopyNew()
+	for i := range share1.Value {
+		ringQ.SubLvl(level, share0.Value[i], share1.Value[i], share0.Value[i])
+	}
+
+	return
+}
+
+// EncryptIndexed encrypts the input plaintext using the stored public-key and writes the result on ct.
+// The randomness of the encryption is derived from the key of the PRNG of the Encryptor and from index only:
+// the same index always yields the same ciphertext (for the same plaintext), and distinct indexes yield
+// independent randomness. It does not consume the randomness used by Encrypt.
+func (enc *pkEncryptor) EncryptIndexed(index uint64, pt *Plaintext, ct *Ciphertext) {
+	indexed := *enc
+	indexed.encryptorSamplers = newEncryptorSamplers(enc.params, enc.indexedPRNG(index))
+	indexed.Encrypt(pt, ct)
+}
+
+// EncryptIndexed encrypts the input plaintext and writes the result on ct.
+// The randomness of the encryption is derived from the key of the PRNG of the Encryptor and from index only:
+// the same index always yields the same ciphertext (for the same plaintext), and distinct indexes yield
+// independent randomness. It does not consume the randomness used by Encrypt.
+func (enc *skEncryptor) EncryptIndexed(index uint64, pt *Plaintext, ct *Ciphertext) {
+	indexed := *enc
+	indexed.encryptorSamplers = newEncryptorSamplers(enc.params, enc.indexedPRNG(index))
+	indexed.Encrypt(pt, ct)
+}
+
+// newCiphertext allocates a ciphertext of degree 1 matching the level and domain of pt.
+func (enc *encryptor) newCiphertext(pt *Plaintext) *Ciphertext {
+	if pt.Value.IsNTT {
+		return enc.params.NewCiphertextNTT(1, pt.Level())
+	}
+	return enc.params.NewCiphertext(1, pt.Level())
+}
+
+// EncryptTransposed encrypts the input plaintext using the stored public-key and writes the result
+// on ct in the limb-major layout. The domain of the encryption is given by ct.IsNTT.
+func (enc *pkEncryptor) EncryptTransposed(pt *Plaintext, ct *TransposedCiphertext) {
+	el := enc.transposedBuffer(ct)
+	enc.Encrypt(pt, el)
+	ct.Transpose(el)
+}
+
+// EncryptTransposed encrypts the input plaintext and writes the result on ct in the limb-major layout.
+// The domain of the encryption is given by ct.IsNTT.
+func (enc *skEncryptor) EncryptTransposed(pt *Plaintext, ct *TransposedCiphertext) {
+	el := enc.transposedBuffer(ct)
+	enc.Encrypt(pt, el)
+	ct.Transpose(el)
+}
+
+// transposedBuffer returns a ciphertext with the same degree, level and domain as ct,
+// backed by a buffer of the encryptor.
+func (enc *encryptor) transposedBuffer(ct *TransposedCiphertext) *Ciphertext {
+
+	if ct.Degree() != 1 {
+		panic("cannot EncryptTransposed: ct must be of degree 1")
+	}
+
+	if enc.ctTransposed == nil {
+		enc.ctTransposed = NewCiphertext(enc.params, 1, enc.params.MaxLevel())
+	}
+
+	level := ct.Level()
+
+	el := &Ciphertext{Value: make([]*ring.Poly, 2)}
+	for i := range el.Value {
+		el.Value[i] = &ring.Poly{Coeffs: enc.ctTransposed.Value[i].Coeffs[:level+1], IsNTT: ct.IsNTT}
+	}
+
+	return el
+}
+
 // ShallowCopy creates a shallow copy of this pkEncryptor in which all the read-only data-structures are
 // shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
 // Encryptors can be used concurrently.
@@ -158,13 +489,13 @@ func (enc *skEncryptor) ShallowCopy() Encryptor {
 func (enc *encryptor) ShallowCopy() *encryptor {
 
 	var bc *ring.BasisExtender
-	if enc.params.PCount() != 0 {
+	if enc.basisextender != nil {
 		bc = enc.basisextender.ShallowCopy()
 	}
 
 	return &encryptor{
 		encryptorBase:     enc.encryptorBase,
-		encryptorSamplers: newEncryptorSamplers(enc.params),
+		encryptorSamplers: newEncryptorSamplers(enc.params, enc.forkPRNG()),
 		encryptorBuffers:  newEncryptorBuffers(enc.params),
 		basisextender:     bc,
 	}
@@ -177,7 +508,20 @@ func (enc *encryptor) WithKey(key interface{}) Encryptor {
 	return enc.ShallowCopy().setKey(key)
 }
 
+// truncateLevel truncates the first two elements of ct to the level levelQ, unless the
+// KeepFullLevel option is set.
+func (enc *encryptor) truncateLevel(levelQ int, ct *Ciphertext) {
+	if enc.options.KeepFullLevel {
+		return
+	}
+	ct.Value[0].Coeffs = ct.Value[0].Coeffs[:levelQ+1]
+	ct.Value[1].Coeffs = ct.Value[1].Coeffs[:levelQ+1]
+}
+
 func (enc *pkEncryptor) encrypt(plaintext *Plaintext, ciphertext *Ciphertext) {
+
+	enc.ensureBuffers(ciphertext.Degree())
+
 	ringQ := enc.params.RingQ()
 	ringQP := enc.params.RingQP()
 
@@ -195,13 +539,20 @@ func (enc *pkEncryptor) encrypt(plaintext *Plaintext, ciphertext *Ciphertext) {
 
 	u := PolyQP{Q: poolQ0, P: poolP2}
 
+	enc.profiler.start()
+
 	enc.ternarySampler.ReadLvl(levelQ, u.Q)
+	enc.witness.record(witnessU, levelQ, u.Q)
 	ringQP.ExtendBasisSmallNormAndCenter(u.Q, levelP, nil, u.P)
 
+	enc.profiler.record(phaseSampling)
+
 	// (#Q + #P) NTT
-	ringQP.NTTLvl(levelQ, levelP, u, u)
+	ringQP.NTTLvlParallel(levelQ, levelP, u, u, enc.options.NTTThreads)
 	ringQP.MFormLvl(levelQ, levelP, u, u)
 
+	enc.profiler.record(phaseNTT)
+
 	ct0QP := PolyQP{Q: ciphertext.Value[0], P: poolP0}
 	ct1QP := PolyQP{Q: ciphertext.Value[1], P: poolP1}
 
@@ -210,25 +561,41 @@ func (enc *pkEncryptor) encrypt(plaintext *Plaintext, ciphertext *Ciphertext) {
 	ringQP.MulCoeffsMontgomeryLvl(levelQ, levelP, u, enc.pk.Value[0], ct0QP)
 	ringQP.MulCoeffsMontgomeryLvl(levelQ, levelP, u, enc.pk.Value[1], ct1QP)
 
+	enc.profiler.record(phaseMultiply)
+
 	// 2*(#Q + #P) NTT
-	ringQP.InvNTTLvl(levelQ, levelP, ct0QP, ct0QP)
-	ringQP.InvNTTLvl(levelQ, levelP, ct1QP, ct1QP)
+	ringQP.InvNTTLvlParallel(levelQ, levelP, ct0QP, ct0QP, enc.options.NTTThreads)
+	ringQP.InvNTTLvlParallel(levelQ, levelP, ct1QP, ct1QP, enc.options.NTTThreads)
+
+	enc.profiler.record(phaseNTT)
 
 	e := PolyQP{Q: poolQ0, P: poolP2}
 
-	enc.gaussianSampler.ReadLvl(levelQ, e.Q)
+	enc.errorSampler.ReadLvl(levelQ, e.Q)
+	enc.witness.record(witnessE0, levelQ, e.Q)
 	ringQP.ExtendBasisSmallNormAndCenter(e.Q, levelP, nil, e.P)
 	ringQP.AddLvl(levelQ, levelP, ct0QP, e, ct0QP)
 
-	enc.gaussianSampler.ReadLvl(levelQ, e.Q)
+	enc.errorSampler.ReadLvl(levelQ, e.Q)
+	enc.witness.record(witnessE1, levelQ, e.Q)
 	ringQP.ExtendBasisSmallNormAndCenter(e.Q, levelP, nil, e.P)
 	ringQP.AddLvl(levelQ, levelP, ct1QP, e, ct1QP)
 
+	enc.profiler.record(phaseSampling)
+
+	basisextender := enc.basisextender
+	if pool := enc.options.BasisExtenderPool; pool != nil {
+		basisextender = pool.Get()
+		defer pool.Put(basisextender)
+	}
+
 	// ct0 = (u*pk0 + e0)/P
-	enc.basisextender.ModDownQPtoQ(levelQ, levelP, ct0QP.Q, ct0QP.P, ct0QP.Q)
+	basisextender.ModDownQPtoQ(levelQ, levelP, ct0QP.Q, ct0QP.P, ct0QP.Q)
 
 	// ct1 = (u*pk1 + e1)/P
-	enc.basisextender.ModDownQPtoQ(levelQ, levelP, ct1QP.Q, ct1QP.P, ct1QP.Q)
+	basisextender.ModDownQPtoQ(levelQ, levelP, ct1QP.Q, ct1QP.P, ct1QP.Q)
+
+	enc.profiler.record(phaseModDown)
 
 	if ciphertextNTT {
 
@@ -255,9 +622,11 @@ func (enc *pkEncryptor) encrypt(plaintext *Plaintext, ciphertext *Ciphertext) {
 		}
 	}
 
+	enc.profiler.record(phaseNTT)
+	enc.profiler.stop()
+
 	ciphertext.Value[1].IsNTT = ciphertext.Value[0].IsNTT
-	ciphertext.Value[0].Coeffs = ciphertext.Value[0].Coeffs[:levelQ+1]
-	ciphertext.Value[1].Coeffs = ciphertext.Value[1].Coeffs[:levelQ+1]
+	enc.truncateLevel(levelQ, ciphertext)
 }
 
 func (enc *pkEncryptor) encryptNoP(plaintext *Plaintext, ciphertext *Ciphertext) {
@@ -269,24 +638,39 @@ func (enc *pkEncryptor) encryptNoP(plaintext *Plaintext, ciphertext *Ciphertext)
 
 	ciphertextNTT := ciphertext.Value[0].IsNTT
 
+	enc.profiler.start()
+
 	enc.ternarySampler.ReadLvl(levelQ, poolQ0)
+	enc.witness.record(witnessU, levelQ, poolQ0)
+
+	enc.profiler.record(phaseSampling)
+
 	ringQ.NTTLvl(levelQ, poolQ0, poolQ0)
 	ringQ.MFormLvl(levelQ, poolQ0, poolQ0)
 
+	enc.profiler.record(phaseNTT)
+
 	// ct0 = u*pk0
 	ringQ.MulCoeffsMontgomeryLvl(levelQ, poolQ0, enc.pk.Value[0].Q, ciphertext.Value[0])
 	// ct1 = u*pk1
 	ringQ.MulCoeffsMontgomeryLvl(levelQ, poolQ0, enc.pk.Value[1].Q, ciphertext.Value[1])
 
+	enc.profiler.record(phaseMultiply)
+
 	if ciphertextNTT {
 
 		// ct1 = u*pk1 + e1
-		enc.gaussianSampler.ReadLvl(levelQ, poolQ0)
+		enc.errorSampler.ReadLvl(levelQ, poolQ0)
+		enc.witness.record(witnessE1, levelQ, poolQ0)
+		enc.profiler.record(phaseSampling)
 		ringQ.NTTLvl(levelQ, poolQ0, poolQ0)
 		ringQ.AddLvl(levelQ, ciphertext.Value[1], poolQ0, ciphertext.Value[1])
+		enc.profiler.record(phaseNTT)
 
 		// ct0 = u*pk0 + e0
-		enc.gaussianSampler.ReadLvl(levelQ, poolQ0)
+		enc.errorSampler.ReadLvl(levelQ, poolQ0)
+		enc.witness.record(witnessE0, levelQ, poolQ0)
+		enc.profiler.record(phaseSampling)
 
 		if !plaintext.Value.IsNTT {
 			ringQ.AddLvl(levelQ, poolQ0, plaintext.Value, poolQ0)
@@ -303,11 +687,15 @@ func (enc *pkEncryptor) encryptNoP(plaintext *Plaintext, ciphertext *Ciphertext)
 		ringQ.InvNTTLvl(levelQ, ciphertext.Value[0], ciphertext.Value[0])
 		ringQ.InvNTTLvl(levelQ, ciphertext.Value[1], ciphertext.Value[1])
 
+		enc.profiler.record(phaseNTT)
+
 		// ct[0] = pk[0]*u + e0
-		enc.gaussianSampler.ReadAndAddLvl(ciphertext.Level(), ciphertext.Value[0])
+		enc.readAndAddError(ciphertext.Level(), ciphertext.Value[0], witnessE0)
 
 		// ct[1] = pk[1]*u + e1
-		enc.gaussianSampler.ReadAndAddLvl(ciphertext.Level(), ciphertext.Value[1])
+		enc.readAndAddError(ciphertext.Level(), ciphertext.Value[1], witnessE1)
+
+		enc.profiler.record(phaseSampling)
 
 		if !plaintext.Value.IsNTT {
 			ringQ.AddLvl(levelQ, ciphertext.Value[0], plaintext.Value, ciphertext.Value[0])
@@ -317,10 +705,12 @@ func (enc *pkEncryptor) encryptNoP(plaintext *Plaintext, ciphertext *Ciphertext)
 		}
 	}
 
+	enc.profiler.record(phaseNTT)
+	enc.profiler.stop()
+
 	ciphertext.Value[1].IsNTT = ciphertext.Value[0].IsNTT
 
-	ciphertext.Value[0].Coeffs = ciphertext.Value[0].Coeffs[:levelQ+1]
-	ciphertext.Value[1].Coeffs = ciphertext.Value[1].Coeffs[:levelQ+1]
+	enc.truncateLevel(levelQ, ciphertext)
 }
 
 func (enc *skEncryptor) encrypt(plaintext *Plaintext, ciphertext *Ciphertext) {
@@ -338,7 +728,8 @@ func (enc *skEncryptor) encrypt(plaintext *Plaintext, ciphertext *Ciphertext) {
 
 	if ciphertextNTT {
 
-		enc.gaussianSampler.ReadLvl(levelQ, poolQ0)
+		enc.errorSampler.ReadLvl(levelQ, poolQ0)
+		enc.witness.record(witnessE0, levelQ, poolQ0)
 
 		if plaintext.Value.IsNTT {
 			ringQ.NTTLvl(levelQ, poolQ0, poolQ0)
@@ -364,7 +755,7 @@ func (enc *skEncryptor) encrypt(plaintext *Plaintext, ciphertext *Ciphertext) {
 			ringQ.AddLvl(levelQ, ciphertext.Value[0], plaintext.Value, ciphertext.Value[0])
 		}
 
-		enc.gaussianSampler.ReadAndAddLvl(ciphertext.Level(), ciphertext.Value[0])
+		enc.readAndAddError(ciphertext.Level(), ciphertext.Value[0], witnessE0)
 
 		ringQ.InvNTTLvl(levelQ, ciphertext.Value[1], ciphertext.Value[1])
 
@@ -373,23 +764,54 @@ func (enc *skEncryptor) encrypt(plaintext *Plaintext, ciphertext *Ciphertext) {
 
 	}
 
-	ciphertext.Value[0].Coeffs = ciphertext.Value[0].Coeffs[:levelQ+1]
-	ciphertext.Value[1].Coeffs = ciphertext.Value[1].Coeffs[:levelQ+1]
+	enc.truncateLevel(levelQ, ciphertext)
 }
 
 func (enc *encryptor) setKey(key interface{}) Encryptor {
+	encryptor, err := enc.setKeyErr(key)
+	if err != nil {
+		panic(fmt.Errorf("cannot setKey: %w", err))
+	}
+	return encryptor
+}
+
+// setKeyErr is the same as setKey but returns an error instead of panicking if the key is invalid.
+func (enc *encryptor) setKeyErr(key interface{}) (Encryptor, error) {
 	switch key := key.(type) {
 	case *PublicKey:
+		if key == nil || key.Value[0].Q == nil || key.Value[1].Q == nil {
+			return nil, fmt.Errorf("pk cannot be nil")
+		}
 		if key.Value[0].Q.Degree() != enc.params.N() || key.Value[1].Q.Degree() != enc.params.N() {
-			panic("cannot setKey: pk ring degree does not match params ring degree")
+			return nil, fmt.Errorf("pk ring degree does not match params ring degree")
 		}
-		return &pkEncryptor{*enc, key}
+		for i := range key.Value {
+			if key.Value[i].Q.IsMForm || (key.Value[i].P != nil && key.Value[i].P.IsMForm) {
+				return nil, fmt.Errorf("pk must not be in Montgomery form (see PublicKey.ToEvalForm)")
+			}
+			if !key.Value[i].Q.IsNTT || (key.Value[i].P != nil && !key.Value[i].P.IsNTT) {
+				return nil, fmt.Errorf("pk must be in the NTT domain (see PublicKey.ToEvalForm)")
+			}
+		}
+		return &pkEncryptor{*enc, key}, nil
+	case *SeededPublicKey:
+		if key == nil {
+			return nil, fmt.Errorf("seeded pk cannot be nil")
+		}
+		pk, err := key.Decompress(enc.params)
+		if err != nil {
+			return nil, err
+		}
+		return enc.setKeyErr(pk)
 	case *SecretKey:
+		if key == nil || key.Value.Q == nil {
+			return nil, fmt.Errorf("sk cannot be nil")
+		}
 		if key.Value.Q.Degree() != enc.params.N() {
-			panic("cannot setKey: sk ring degree does not match params ring degree")
+			return nil, fmt.Errorf("sk ring degree does not match params ring degree")
 		}
-		return &skEncryptor{*enc, key}
+		return &skEncryptor{*enc, key}, nil
 	default:
-		panic("cannot setKey: key must be either *rlwe.PublicKey or *rlwe.SecretKey")
+		return nil, fmt.Errorf("key must be either *rlwe.PublicKey, *rlwe.SeededPublicKey or *rlwe.SecretKey")
 	}
 }
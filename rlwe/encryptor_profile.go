@@ -0,0 +1,42 @@
+package rlwe
+
+import (
+	"time"
+)
+
+// ProfileResult stores the time spent in each phase of a public-key encryption.
+type ProfileResult struct {
+	Sampling time.Duration // sampling of the ternary randomness and of the errors, and their basis extension
+	NTT      time.Duration // forward and inverse NTTs and Montgomery conversions, and addition of the plaintext
+	Multiply time.Duration // multiplication of the randomness with the public key
+	ModDown  time.Duration // division by the special modulus P
+}
+
+// Total returns the total time spent in the profiled phases.
+func (p ProfileResult) Total() time.Duration {
+	return p.Sampling + p.NTT + p.Multiply + p.ModDown
+}
+
+// ProfilingEncryptor is an Encryptor that records the time spent in each phase of its public-key encryptions.
+// The Encryptors of the package only implement it when built with the lattigo_profile build tag, so that the
+// profiling has no cost otherwise:
+//
+//	go build -tags lattigo_profile
+//
+// It can then be obtained with a type assertion on an Encryptor. Each shallow copy records its own timings.
+type ProfilingEncryptor interface {
+	Encryptor
+
+	// LastProfile returns the timings of the phases of the last public-key encryption performed by the
+	// Encryptor, or the zero value if it has not performed any public-key encryption.
+	LastProfile() ProfileResult
+}
+
+type encryptPhase int
+
+const (
+	phaseSampling encryptPhase = iota
+	phaseNTT
+	phaseMultiply
+	phaseModDown
+)
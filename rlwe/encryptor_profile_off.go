@@ -0,0 +1,17 @@
+//go:build !lattigo_profile
+// +build !lattigo_profile
+
+package rlwe
+
+// encryptProfileEnabled reports whether the Encryptors implement ProfilingEncryptor.
+const encryptProfileEnabled = false
+
+// encryptProfiler is a no-op placeholder for the profiler of the lattigo_profile build,
+// whose empty methods are inlined away by the compiler.
+type encryptProfiler struct{}
+
+func (p *encryptProfiler) start() {}
+
+func (p *encryptProfiler) record(phase encryptPhase) {}
+
+func (p *encryptProfiler) stop() {}
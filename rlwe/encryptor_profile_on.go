@@ -0,0 +1,54 @@
+//go:build lattigo_profile
+// +build lattigo_profile
+
+package rlwe
+
+import (
+	"time"
+)
+
+// encryptProfileEnabled reports whether the Encryptors implement ProfilingEncryptor.
+const encryptProfileEnabled = true
+
+// encryptProfiler records the timings of the phases of the public-key encryption.
+type encryptProfiler struct {
+	current ProfileResult
+	last    ProfileResult
+	lap     time.Time
+}
+
+// start resets the timings of the current encryption and starts the clock.
+func (p *encryptProfiler) start() {
+	p.current = ProfileResult{}
+	p.lap = time.Now()
+}
+
+// record adds the time elapsed since the last call to start or record to the given phase.
+func (p *encryptProfiler) record(phase encryptPhase) {
+
+	now := time.Now()
+	elapsed := now.Sub(p.lap)
+	p.lap = now
+
+	switch phase {
+	case phaseSampling:
+		p.current.Sampling += elapsed
+	case phaseNTT:
+		p.current.NTT += elapsed
+	case phaseMultiply:
+		p.current.Multiply += elapsed
+	case phaseModDown:
+		p.current.ModDown += elapsed
+	}
+}
+
+// stop makes the timings of the current encryption available to LastProfile.
+func (p *encryptProfiler) stop() {
+	p.last = p.current
+}
+
+// LastProfile returns the timings of the phases of the last public-key encryption performed by the
+// Encryptor, or the zero value if it has not performed any public-key encryption.
+func (enc *encryptor) LastProfile() ProfileResult {
+	return enc.profiler.last
+}
@@ -0,0 +1,107 @@
+package rlwe
+
+import (
+	"github.com/tuneinsight/lattigo/v3/ring"
+)
+
+// EncryptionWitness stores the secret randomness sampled by an encryption, in the coefficient domain and
+// modulo the moduli of Q up to the level of the ciphertext. It is the witness from which a caller can build a
+// proof that the ciphertext is a well-formed encryption of its plaintext.
+//
+// For a public-key encryption of m with the public key (pk0, pk1), the ciphertext is
+// ((u*pk0 + e0)/P + m, (u*pk1 + e1)/P), where the division by P is rounded and omitted if the parameters
+// have no modulus P, and u, e0 and e1 are extended to QP by centering them. For a secret-key encryption,
+// the ciphertext is (-a*s + m + e0, a + e1), U is nil and E1 is nil unless the Encryptor was created with
+// the DualErrorInjection option.
+//
+// The witness must be kept as secret as the secret key and should be erased with Zero once it is no longer needed.
+type EncryptionWitness struct {
+	U  *ring.Poly // ternary randomness of the public-key encryption
+	E0 *ring.Poly // error of the first element of the ciphertext
+	E1 *ring.Poly // error of the second element of the ciphertext
+}
+
+// Zero overwrites the coefficients of the witness with zeros and removes its polynomials.
+func (w *EncryptionWitness) Zero() {
+	for _, pol := range []*ring.Poly{w.U, w.E0, w.E1} {
+		if pol != nil {
+			pol.Zero()
+		}
+	}
+	w.U, w.E0, w.E1 = nil, nil, nil
+}
+
+// truncate drops the limbs of the polynomials of the witness above level.
+func (w *EncryptionWitness) truncate(level int) {
+	for _, pol := range []*ring.Poly{w.U, w.E0, w.E1} {
+		if pol != nil && pol.Level() > level {
+			pol.Coeffs = pol.Coeffs[:level+1]
+		}
+	}
+}
+
+type witnessComponent int
+
+const (
+	witnessU witnessComponent = iota
+	witnessE0
+	witnessE1
+)
+
+// record stores a copy of the first level+1 limbs of pol as the given component of the witness.
+// It is a no-op on a nil receiver.
+func (w *EncryptionWitness) record(component witnessComponent, level int, pol *ring.Poly) {
+	if w == nil {
+		return
+	}
+
+	cpy := ring.NewPoly(pol.Degree(), level+1)
+	for i := range cpy.Coeffs {
+		copy(cpy.Coeffs[i], pol.Coeffs[i])
+	}
+
+	switch component {
+	case witnessU:
+		w.U = cpy
+	case witnessE0:
+		w.E0 = cpy
+	case witnessE1:
+		w.E1 = cpy
+	}
+}
+
+// readAndAddError samples an error at the given level, adds it to pOut and records it as the given component of
+// the witness of the Encryptor, if any.
+func (enc *encryptor) readAndAddError(level int, pOut *ring.Poly, component witnessComponent) {
+	if enc.witness == nil {
+		enc.errorSampler.ReadAndAddLvl(level, pOut)
+		return
+	}
+
+	e := enc.ringQ.NewPolyLvl(level)
+	enc.errorSampler.ReadLvl(level, e)
+	enc.ringQ.AddLvl(level, pOut, e, pOut)
+	enc.witness.record(component, level, e)
+}
+
+// EncryptWithWitness encrypts the input plaintext, writes the result on ct and returns the randomness
+// sampled by the encryption. See EncryptionWitness.
+func (enc *pkEncryptor) EncryptWithWitness(pt *Plaintext, ct *Ciphertext) (w *EncryptionWitness) {
+	w = new(EncryptionWitness)
+	withWitness := *enc
+	withWitness.witness = w
+	withWitness.Encrypt(pt, ct)
+	w.truncate(ct.Level())
+	return
+}
+
+// EncryptWithWitness encrypts the input plaintext, writes the result on ct and returns the randomness
+// sampled by the encryption. See EncryptionWitness.
+func (enc *skEncryptor) EncryptWithWitness(pt *Plaintext, ct *Ciphertext) (w *EncryptionWitness) {
+	w = new(EncryptionWitness)
+	withWitness := *enc
+	withWitness.witness = w
+	withWitness.Encrypt(pt, ct)
+	w.truncate(ct.Level())
+	return
+}
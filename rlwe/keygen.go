@@ -1,11 +1,14 @@
 package rlwe
 
 import (
+	"crypto/sha512"
+	"fmt"
 	"math"
-	"math/big"
+	"strings"
 
 	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/utils"
+	"golang.org/x/crypto/pbkdf2"
 )
 
 // KeyGenerator is an interface implementing the methods of the KeyGenerator.
@@ -15,6 +18,8 @@ type KeyGenerator interface {
 	GenSecretKeyWithDistrib(p float64) (sk *SecretKey)
 	GenSecretKeyWithHammingWeight(hw int) (sk *SecretKey)
 	GenPublicKey(sk *SecretKey) (pk *PublicKey)
+	GenPublicKeySeeded(sk *SecretKey, seed []byte) (spk *SeededPublicKey)
+	GenPublicKeysFromCRPs(sk *SecretKey, crps []*ring.Poly) (pks []*PublicKey)
 	GenKeyPair() (sk *SecretKey, pk *PublicKey)
 	GenRelinearizationKey(sk *SecretKey, maxDegree int) (evk *RelinearizationKey)
 	GenSwitchingKey(skInput, skOutput *SecretKey) (newevakey *SwitchingKey)
@@ -22,6 +27,7 @@ type KeyGenerator interface {
 	GenRotationKeys(galEls []uint64, sk *SecretKey) (rks *RotationKeySet)
 	GenSwitchingKeyForRotationBy(k int, sk *SecretKey) (swk *SwitchingKey)
 	GenRotationKeysForRotations(ks []int, inclueSwapRows bool, sk *SecretKey) (rks *RotationKeySet)
+	GenRotationKeysForSteps(sk *SecretKey, steps []int) (rks *RotationKeySet)
 	GenSwitchingKeyForRowRotation(sk *SecretKey) (swk *SwitchingKey)
 	GenRotationKeysForInnerSum(sk *SecretKey) (rks *RotationKeySet)
 	GenSwitchingKeysForRingSwap(skCKKS, skCI *SecretKey) (swkStdToConjugateInvariant, swkConjugateInvariantToStd *SwitchingKey)
@@ -60,7 +66,7 @@ func NewKeyGenerator(params Parameters) KeyGenerator {
 		poolQ:            params.RingQ().NewPoly(),
 		poolQP:           poolQP,
 		ternarySampler:   ring.NewTernarySamplerWithHammingWeight(prng, params.ringQ, params.h, false),
-		gaussianSamplerQ: ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), int(6*params.Sigma())),
+		gaussianSamplerQ: ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), params.ErrorBound()),
 		uniformSamplerQ:  ring.NewUniformSampler(prng, params.RingQ()),
 		uniformSamplerP:  uniformSamplerP,
 	}
@@ -107,6 +113,7 @@ func (keygen *keyGenerator) genSecretKeyFromSampler(sampler ring.Sampler) (sk *S
 		ringQP.ExtendBasisSmallNormAndCenter(sk.Value.Q, levelP, nil, sk.Value.P)
 		ringQP.NTTLvl(levelQ, levelP, sk.Value, sk.Value)
 		ringQP.MFormLvl(levelQ, levelP, sk.Value, sk.Value)
+		sk.Value.P.IsNTT, sk.Value.P.IsMForm = true, true
 	} else {
 		ringQ := keygen.params.RingQ()
 		sk = new(SecretKey)
@@ -116,45 +123,134 @@ func (keygen *keyGenerator) genSecretKeyFromSampler(sampler ring.Sampler) (sk *S
 		ringQ.MForm(sk.Value.Q, sk.Value.Q)
 	}
 
+	sk.Value.Q.IsNTT, sk.Value.Q.IsMForm = true, true
+
 	return
 }
 
+// SecretKeyFromMnemonic deterministically derives a ternary SecretKey of Hamming weight params.HammingWeight()
+// from a mnemonic phrase, so that the key can be backed up as a list of words.
+//
+// The phrase is normalized by separating its words with single spaces and stretched into a 64-byte seed as in
+// BIP39, with PBKDF2-HMAC-SHA512, the salt "mnemonic" (empty passphrase) and 2048 iterations. The seed is the key
+// of the PRNG of the ternary sampler. The derived key hence only depends on the phrase and on the ring Q and the
+// Hamming weight of the parameters. The words are not checked against a word list and the phrase is not
+// NFKD-normalized, which is not needed for ASCII phrases. It returns an error if the phrase has no word.
+func SecretKeyFromMnemonic(params Parameters, mnemonic string) (*SecretKey, error) {
+
+	seed, err := mnemonicSeed(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+
+	prng, err := utils.NewKeyedPRNG(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	sampler := ring.NewTernarySamplerWithHammingWeight(prng, params.RingQ(), params.HammingWeight(), false)
+
+	return (&keyGenerator{params: params}).genSecretKeyFromSampler(sampler), nil
+}
+
+// mnemonicSeed returns the 64-byte BIP39 seed of the mnemonic phrase with an empty passphrase.
+func mnemonicSeed(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words) == 0 {
+		return nil, fmt.Errorf("cannot SecretKeyFromMnemonic: mnemonic is empty")
+	}
+	return pbkdf2.Key([]byte(strings.Join(words, " ")), []byte("mnemonic"), 2048, 64, sha512.New), nil
+}
+
 // GenPublicKey generates a new public key from the provided SecretKey.
 func (keygen *keyGenerator) GenPublicKey(sk *SecretKey) (pk *PublicKey) {
 
-	pk = new(PublicKey)
+	//pk[1] = [a]
+	pk = NewPublicKey(keygen.params)
+	keygen.uniformSamplerQ.Read(pk.Value[1].Q)
+	if keygen.params.PCount() > 0 {
+		keygen.uniformSamplerP.Read(pk.Value[1].P)
+	}
+
+	keygen.genPublicKeyFromMask(sk, pk)
+
+	return pk
+}
+
+// GenPublicKeySeeded generates a new public key whose uniform polynomial pk[1] is derived from the seed, and
+// returns it compressed as the polynomial pk[0] and the seed. The seed must be at most 64 bytes and should be
+// unique per public key. See SeededPublicKey.Decompress.
+func (keygen *keyGenerator) GenPublicKeySeeded(sk *SecretKey, seed []byte) (spk *SeededPublicKey) {
+
+	pk := NewPublicKey(keygen.params)
+	if err := genPublicKeyMaskFromSeed(keygen.params, seed, &pk.Value[1]); err != nil {
+		panic(fmt.Errorf("cannot GenPublicKeySeeded: %w", err))
+	}
+
+	keygen.genPublicKeyFromMask(sk, pk)
+
+	return &SeededPublicKey{Value: pk.Value[0], Seed: append([]byte{}, seed...)}
+}
+
+// GenPublicKeysFromCRPs generates one public key per CRP from the same secret key: the i-th public key
+// is [-crps[i]*s + e_i, crps[i]] with a fresh error e_i. Each CRP must be a uniform polynomial in the
+// NTT domain over the moduli Q followed by the moduli P of the parameters (i.e. with QCount()+PCount()
+// moduli), and Value[1] of the i-th public key is a copy of crps[i] split over Q and P.
+func (keygen *keyGenerator) GenPublicKeysFromCRPs(sk *SecretKey, crps []*ring.Poly) (pks []*PublicKey) {
+
+	N, levelQ := keygen.params.N(), keygen.params.QCount()-1
+
+	pks = make([]*PublicKey, len(crps))
+
+	for i, crp := range crps {
+
+		if crp == nil || crp.LenModuli() != keygen.params.QCount()+keygen.params.PCount() || crp.Degree() != N {
+			panic(fmt.Errorf("cannot GenPublicKeysFromCRPs: crps[%d] does not match the ring degree or the moduli QP of the parameters", i))
+		}
+
+		pk := NewPublicKey(keygen.params)
+		ring.CopyValues(&ring.Poly{Coeffs: crp.Coeffs[:levelQ+1]}, pk.Value[1].Q)
+		if keygen.params.PCount() > 0 {
+			ring.CopyValues(&ring.Poly{Coeffs: crp.Coeffs[levelQ+1:]}, pk.Value[1].P)
+		}
+
+		keygen.genPublicKeyFromMask(sk, pk)
+
+		pks[i] = pk
+	}
+
+	return pks
+}
+
+// genPublicKeyFromMask sets pk[0] = [-as + e] for the mask a = pk[1] in the NTT domain and flags pk as IsNTT.
+func (keygen *keyGenerator) genPublicKeyFromMask(sk *SecretKey, pk *PublicKey) {
 
 	if keygen.params.PCount() > 0 {
 
 		ringQP := keygen.params.RingQP()
 		levelQ, levelP := keygen.params.QCount()-1, keygen.params.PCount()-1
 
-		//pk[0] = [-as + e]
-		//pk[1] = [a]
-		pk = NewPublicKey(keygen.params)
 		keygen.gaussianSamplerQ.Read(pk.Value[0].Q)
 		ringQP.ExtendBasisSmallNormAndCenter(pk.Value[0].Q, levelP, nil, pk.Value[0].P)
 		ringQP.NTTLvl(levelQ, levelP, pk.Value[0], pk.Value[0])
 
-		keygen.uniformSamplerQ.Read(pk.Value[1].Q)
-		keygen.uniformSamplerP.Read(pk.Value[1].P)
-
 		ringQP.MulCoeffsMontgomeryAndSubLvl(levelQ, levelP, sk.Value, pk.Value[1], pk.Value[0])
 	} else {
 		ringQ := keygen.params.RingQ()
 
-		//pk[0] = [-as + e]
-		//pk[1] = [a]
-		pk = NewPublicKey(keygen.params)
 		keygen.gaussianSamplerQ.Read(pk.Value[0].Q)
 
 		ringQ.NTT(pk.Value[0].Q, pk.Value[0].Q)
 
-		keygen.uniformSamplerQ.Read(pk.Value[1].Q)
-
 		ringQ.MulCoeffsMontgomeryAndSub(sk.Value.Q, pk.Value[1].Q, pk.Value[0].Q)
 	}
-	return pk
+
+	for i := range pk.Value {
+		pk.Value[i].Q.IsNTT = true
+		if pk.Value[i].P != nil {
+			pk.Value[i].P.IsNTT = true
+		}
+	}
 }
 
 // GenKeyPair generates a new SecretKey with distribution [1/3, 1/3, 1/3] and a corresponding public key.
@@ -220,6 +316,22 @@ func (keygen *keyGenerator) GenRotationKeysForRotations(ks []int, includeConjuga
 	return keygen.GenRotationKeys(galEls, sk)
 }
 
+// GenRotationKeysForSteps generates a RotationKeySet supporting left rotations by k positions for all k in steps.
+// Negative k is equivalent to a right rotation by k positions.
+// Steps mapping to the same galois element are generated only once.
+func (keygen *keyGenerator) GenRotationKeysForSteps(sk *SecretKey, steps []int) (rks *RotationKeySet) {
+	galEls := make([]uint64, 0, len(steps))
+	seen := make(map[uint64]bool, len(steps))
+	for _, k := range steps {
+		galEl := keygen.params.GaloisElementForColumnRotationBy(k)
+		if !seen[galEl] {
+			seen[galEl] = true
+			galEls = append(galEls, galEl)
+		}
+	}
+	return keygen.GenRotationKeys(galEls, sk)
+}
+
 func (keygen *keyGenerator) GenSwitchingKeyForRowRotation(sk *SecretKey) (swk *SwitchingKey) {
 	swk = NewSwitchingKey(keygen.params, keygen.params.QCount()-1, keygen.params.PCount()-1)
 	keygen.genrotKey(sk.Value, keygen.params.GaloisElementForRowRotation(), swk)
@@ -334,19 +446,8 @@ func (keygen *keyGenerator) genSwitchingKey(skIn *ring.Poly, skOut PolyQP, swk *
 	levelQ := len(swk.Value[0][0].Q.Coeffs) - 1
 	levelP := len(swk.Value[0][0].P.Coeffs) - 1
 
-	var pBigInt *big.Int
-	if levelP == keygen.params.PCount()-1 {
-		pBigInt = keygen.params.RingP().ModulusBigint
-	} else {
-		P := keygen.params.RingP().Modulus
-		pBigInt = new(big.Int).SetUint64(P[0])
-		for i := 1; i < levelP+1; i++ {
-			pBigInt.Mul(pBigInt, ring.NewUint(P[i]))
-		}
-	}
-
 	// Computes P * skIn
-	ringQ.MulScalarBigintLvl(levelQ, skIn, pBigInt, keygen.poolQ)
+	ringQ.MulScalarBigintLvl(levelQ, skIn, keygen.params.ProductOfP(levelP), keygen.poolQ)
 
 	alpha := levelP + 1
 	beta := int(math.Ceil(float64(levelQ+1) / float64(levelP+1)))
@@ -1,7 +1,14 @@
 package rlwe
 
 import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
 	"math"
+	"runtime"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/utils"
 )
 
 // SecretKey is a type for generic RLWE secret keys.
@@ -10,10 +17,19 @@ type SecretKey struct {
 }
 
 // PublicKey is a type for generic RLWE public keys.
+// The Encryptor expects the polynomials of a PublicKey in the NTT domain and in standard (non-Montgomery) form,
+// which is the form returned by KeyGenerator.GenPublicKey. Keys in another form can be converted with ToEvalForm.
 type PublicKey struct {
 	Value [2]PolyQP
 }
 
+// SeededPublicKey is a compressed PublicKey that stores the first polynomial of the public key and the
+// seed from which its uniform second polynomial is regenerated by Decompress, which halves its size.
+type SeededPublicKey struct {
+	Value PolyQP
+	Seed  []byte
+}
+
 // SwitchingKey is a type for generic RLWE public switching keys.
 type SwitchingKey struct {
 	Value [][2]PolyQP
@@ -44,11 +60,109 @@ func NewSecretKey(params Parameters) *SecretKey {
 	return &SecretKey{Value: params.RingQP().NewPoly()}
 }
 
+// HammingWeight returns the number of nonzero coefficients of the secret key in its centered coefficient
+// representation. The domain of sk is given by the IsNTT flag of sk.Value.Q: if the key is in the NTT domain,
+// the count is done on a copy mapped back to the coefficient domain and sk is left unchanged.
+// The flag must therefore reflect the actual domain of the key: secret keys generated or serialized by versions
+// of the library that did not set it hold NTT data with IsNTT=false, for which the method counts the nonzero
+// NTT residues instead of the coefficients. Such keys must have their IsNTT flag set to true beforehand.
+func (sk *SecretKey) HammingWeight(ringQ *ring.Ring) (hw int) {
+
+	coeffs := sk.Value.Q.Coeffs[0]
+
+	if sk.Value.Q.IsNTT {
+		tmp := ringQ.NewPolyLvl(0)
+		ringQ.InvNTTLvl(0, sk.Value.Q, tmp)
+		coeffs = tmp.Coeffs[0]
+	}
+
+	// The coefficients of the secret are small, hence a coefficient is nonzero if and only
+	// if its residue modulo the first modulus is nonzero (in or out of the Montgomery form).
+	for _, c := range coeffs {
+		if c != 0 {
+			hw++
+		}
+	}
+
+	return
+}
+
 // NewPublicKey returns a new PublicKey with zero values.
 func NewPublicKey(params Parameters) (pk *PublicKey) {
 	return &PublicKey{Value: [2]PolyQP{params.RingQP().NewPoly(), params.RingQP().NewPoly()}}
 }
 
+// Decompress returns the PublicKey compressed by the target SeededPublicKey: its second polynomial is sampled
+// uniformly in the NTT domain from a KeyedPRNG keyed with the seed. It returns an error if the seed is not a
+// valid key for the KeyedPRNG or if the ring degree of the key does not match the parameters.
+func (spk *SeededPublicKey) Decompress(params Parameters) (pk *PublicKey, err error) {
+
+	if spk.Value.Q == nil || spk.Value.Q.Degree() != params.N() {
+		return nil, fmt.Errorf("seeded pk ring degree does not match params ring degree")
+	}
+
+	pk = NewPublicKey(params)
+	pk.Value[0].Copy(spk.Value)
+
+	if err = genPublicKeyMaskFromSeed(params, spk.Seed, &pk.Value[1]); err != nil {
+		return nil, err
+	}
+
+	for i := range pk.Value {
+		pk.Value[i].Q.IsNTT = true
+		if pk.Value[i].P != nil {
+			pk.Value[i].P.IsNTT = true
+		}
+	}
+
+	return
+}
+
+// genPublicKeyMaskFromSeed samples the uniform second polynomial of a public key from a KeyedPRNG keyed with seed.
+func genPublicKeyMaskFromSeed(params Parameters, seed []byte, mask *PolyQP) (err error) {
+	prng, err := utils.NewKeyedPRNG(seed)
+	if err != nil {
+		return fmt.Errorf("invalid seed: %w", err)
+	}
+	NewUniformSamplerQP(params, prng).Read(mask)
+	return
+}
+
+// ToEvalForm converts in place the polynomials of pk to the form expected by the Encryptor, i.e. the NTT domain
+// and the standard (non-Montgomery) form, according to their IsNTT and IsMForm flags. Polynomials flagged as
+// IsMForm are taken out of the Montgomery form and polynomials not flagged as IsNTT are mapped to the NTT domain.
+// The Encryptor rejects public keys whose polynomials are not flagged as being in this form.
+//
+// The flags of the polynomials must reflect their actual form, since ToEvalForm cannot detect it from the
+// coefficients. In particular, the public keys generated or serialized by versions of the library that did not set
+// the flags hold polynomials in the NTT domain with IsNTT=false: calling ToEvalForm on them would apply the NTT a
+// second time and silently corrupt them. Such keys must instead have their IsNTT flags set to true.
+func (pk *PublicKey) ToEvalForm(params Parameters) {
+	for i := range pk.Value {
+		for _, pol := range []struct {
+			r   *ring.Ring
+			pol *ring.Poly
+		}{{params.RingQ(), pk.Value[i].Q}, {params.RingP(), pk.Value[i].P}} {
+
+			if pol.pol == nil {
+				continue
+			}
+
+			level := pol.pol.Level()
+
+			if pol.pol.IsMForm {
+				pol.r.InvMFormLvl(level, pol.pol, pol.pol)
+				pol.pol.IsMForm = false
+			}
+
+			if !pol.pol.IsNTT {
+				pol.r.NTTLvl(level, pol.pol, pol.pol)
+				pol.pol.IsNTT = true
+			}
+		}
+	}
+}
+
 // Equals checks two PublicKey struct for equality.
 func (pk *PublicKey) Equals(other *PublicKey) bool {
 	if pk == other {
@@ -57,6 +171,58 @@ func (pk *PublicKey) Equals(other *PublicKey) bool {
 	return pk.Value[0].Equals(other.Value[0]) && pk.Value[1].Equals(other.Value[1])
 }
 
+// Fingerprint returns a SHA-256 digest of the public-key under the parameters params. The digest covers the
+// fingerprint of params (see Parameters.Fingerprint), the number of moduli of each polynomial and their coefficients,
+// hashed in the canonical order pk[0].Q, pk[0].P, pk[1].Q, pk[1].P. The coefficients are hashed in the evaluation
+// form (NTT domain, standard form): a copy of the key is normalized with ToEvalForm beforehand, such that two keys
+// that are equal as polynomials have the same fingerprint whatever their form. The flags of the polynomials must
+// therefore reflect their actual form. The receiver is not modified.
+func (pk *PublicKey) Fingerprint(params Parameters) (digest [32]byte) {
+
+	pkEval := pk.CopyNew()
+	pkEval.ToEvalForm(params)
+
+	h := sha256.New()
+
+	paramsFingerprint := params.Fingerprint()
+	h.Write(paramsFingerprint[:])
+
+	var header [8]byte
+
+	hashPoly := func(pol *ring.Poly) {
+
+		if pol == nil {
+			binary.LittleEndian.PutUint64(header[:], 0)
+			h.Write(header[:])
+			return
+		}
+
+		binary.LittleEndian.PutUint64(header[:], uint64(len(pol.Coeffs)))
+		h.Write(header[:])
+
+		for i := range pol.Coeffs {
+
+			binary.LittleEndian.PutUint64(header[:], uint64(len(pol.Coeffs[i])))
+			h.Write(header[:])
+
+			buff := make([]byte, len(pol.Coeffs[i])<<3)
+			for j, c := range pol.Coeffs[i] {
+				binary.LittleEndian.PutUint64(buff[j<<3:], c)
+			}
+			h.Write(buff)
+		}
+	}
+
+	for i := range pkEval.Value {
+		hashPoly(pkEval.Value[i].Q)
+		hashPoly(pkEval.Value[i].P)
+	}
+
+	copy(digest[:], h.Sum(nil))
+
+	return
+}
+
 // NewRotationKeySet returns a new RotationKeySet with pre-allocated switching keys for each distinct galoisElement value.
 func NewRotationKeySet(params Parameters, galoisElement []uint64) (rotKey *RotationKeySet) {
 	rotKey = new(RotationKeySet)
@@ -112,6 +278,32 @@ func (sk *SecretKey) CopyNew() *SecretKey {
 	return &SecretKey{sk.Value.CopyNew()}
 }
 
+// Zeroize overwrites with zeros all the coefficients of the receiver secret key, in Q and in P.
+// The stores cannot be elided by the compiler since they are followed by a runtime.KeepAlive on the
+// coefficients. Using the secret key after calling Zeroize is undefined. Copies of the key, such as
+// the ones created by CopyNew or by the marshaling methods, are not zeroized.
+func (sk *SecretKey) Zeroize() {
+	if sk == nil {
+		return
+	}
+	for _, pol := range []*ring.Poly{sk.Value.Q, sk.Value.P} {
+		if pol == nil {
+			continue
+		}
+		for i := range pol.Coeffs {
+			zeroize(pol.Coeffs[i])
+		}
+	}
+}
+
+// zeroize overwrites the slice s with zeros.
+func zeroize(s []uint64) {
+	for i := range s {
+		s[i] = 0
+	}
+	runtime.KeepAlive(s)
+}
+
 // CopyNew creates a deep copy of the receiver PublicKey and returns it.
 func (pk *PublicKey) CopyNew() *PublicKey {
 	if pk == nil {
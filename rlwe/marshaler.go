@@ -1,8 +1,11 @@
 package rlwe
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
+	"fmt"
+	"math/bits"
 
 	"github.com/tuneinsight/lattigo/v3/ring"
 )
@@ -22,6 +25,12 @@ func (ciphertext *Ciphertext) GetDataLen(WithMetaData bool) (dataLen int) {
 	return dataLen
 }
 
+// MarshalSize returns the exact number of bytes that MarshalBinary produces for the target Ciphertext,
+// i.e. 1 + (degree+1) * (4 + 8 * N * (level+1)), without serializing it.
+func (ciphertext *Ciphertext) MarshalSize() int {
+	return ciphertext.GetDataLen(true)
+}
+
 // MarshalBinary encodes a Ciphertext on a byte slice. The total size
 // in byte is 4 + 8* N * numberModuliQ * (degree + 1).
 func (ciphertext *Ciphertext) MarshalBinary() (data []byte, err error) {
@@ -75,11 +84,129 @@ func (ciphertext *Ciphertext) UnmarshalBinary(data []byte) (err error) {
 	return nil
 }
 
+// ciphertextsHeaderLen is the size in bytes of the header written by MarshalCiphertexts.
+const ciphertextsHeaderLen = 45
+
+// MarshalCiphertexts encodes a slice of Ciphertexts on a byte slice. The ciphertexts must share the ring degree
+// of params, the same level, degree and domain. These are written once in a shared header of 45 bytes, together
+// with the number of ciphertexts and the fingerprint of params (see Parameters.Fingerprint), followed by the
+// coefficients of each ciphertext. The total size in byte is 45 + 8 * N * numberModuliQ * (degree + 1) * len(cts).
+func MarshalCiphertexts(params Parameters, cts []*Ciphertext) (data []byte, err error) {
+
+	if len(cts) == 0 {
+		return nil, errors.New("cannot marshal an empty slice of ciphertexts")
+	}
+
+	ref := cts[0].Value[0]
+	N, numberModuli, degree := ref.Degree(), ref.LenModuli(), cts[0].Degree()
+
+	if N != params.N() || numberModuli > params.QCount() {
+		return nil, errors.New("cannot marshal ciphertexts: ciphertexts do not match the parameters")
+	}
+
+	for _, ct := range cts {
+		if ct.Degree() != degree {
+			return nil, errors.New("cannot marshal ciphertexts: degree mismatch")
+		}
+		for _, el := range ct.Value {
+			if el.Degree() != N || el.LenModuli() != numberModuli || el.IsNTT != ref.IsNTT || el.IsMForm != ref.IsMForm {
+				return nil, errors.New("cannot marshal ciphertexts: parameters mismatch")
+			}
+		}
+	}
+
+	data = make([]byte, ciphertextsHeaderLen+len(cts)*(degree+1)*numberModuli*N<<3)
+
+	data[0] = uint8(bits.Len64(uint64(N)) - 1)
+	data[1] = uint8(numberModuli)
+	data[2] = uint8(degree + 1)
+	if ref.IsNTT {
+		data[3] = 1
+	}
+	if ref.IsMForm {
+		data[4] = 1
+	}
+	binary.BigEndian.PutUint64(data[5:13], uint64(len(cts)))
+	fingerprint := params.Fingerprint()
+	copy(data[13:ciphertextsHeaderLen], fingerprint[:])
+
+	pointer := ciphertextsHeaderLen
+
+	for _, ct := range cts {
+		for _, el := range ct.Value {
+			if pointer, err = ring.WriteCoeffsTo(pointer, N, numberModuli, el.Coeffs, data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return data, nil
+}
+
+// UnmarshalCiphertexts decodes a slice of Ciphertexts previously marshaled with MarshalCiphertexts.
+// It returns an error if the header is malformed, if the length of data does not match the header,
+// or if the ciphertexts were not marshaled with the same parameters.
+func UnmarshalCiphertexts(params Parameters, data []byte) (cts []*Ciphertext, err error) {
+
+	if len(data) < ciphertextsHeaderLen {
+		return nil, errors.New("too small bytearray")
+	}
+
+	fingerprint := params.Fingerprint()
+	if !bytes.Equal(data[13:ciphertextsHeaderLen], fingerprint[:]) {
+		return nil, errors.New("cannot unmarshal ciphertexts: parameters fingerprint mismatch")
+	}
+
+	if data[0] > MaxLogN || int(data[0]) != params.LogN() {
+		return nil, fmt.Errorf("cannot unmarshal ciphertexts: invalid ring degree 2^%d", data[0])
+	}
+
+	N, numberModuli, size := 1<<data[0], int(data[1]), int(data[2])
+	count := binary.BigEndian.Uint64(data[5:13])
+
+	if size == 0 || numberModuli == 0 || numberModuli > params.QCount() || N == 0 {
+		return nil, errors.New("cannot unmarshal ciphertexts: invalid header")
+	}
+
+	if data[3] > 1 || data[4] > 1 {
+		return nil, errors.New("cannot unmarshal ciphertexts: invalid domain flags")
+	}
+
+	// size, numberModuli and N are bounded by the checks above, hence perCiphertext cannot overflow.
+	perCiphertext := uint64(size * numberModuli * N << 3)
+	if count > uint64(len(data)-ciphertextsHeaderLen)/perCiphertext || uint64(len(data)-ciphertextsHeaderLen) != count*perCiphertext {
+		return nil, errors.New("invalid bytearray length")
+	}
+
+	cts = make([]*Ciphertext, count)
+
+	pointer := ciphertextsHeaderLen
+
+	for i := range cts {
+		cts[i] = &Ciphertext{Value: make([]*ring.Poly, size)}
+		for j := range cts[i].Value {
+			el := &ring.Poly{Coeffs: make([][]uint64, numberModuli), IsNTT: data[3] == 1, IsMForm: data[4] == 1}
+			if pointer, err = ring.DecodeCoeffsNew(pointer, N, numberModuli, el.Coeffs, data); err != nil {
+				return nil, err
+			}
+			cts[i].Value[j] = el
+		}
+	}
+
+	return cts, nil
+}
+
 // GetDataLen returns the length in bytes of the target SecretKey.
 func (sk *SecretKey) GetDataLen(WithMetadata bool) (dataLen int) {
 	return sk.Value.GetDataLen(WithMetadata)
 }
 
+// MarshalSize returns the exact number of bytes that MarshalBinary produces for the target SecretKey,
+// without serializing it.
+func (sk *SecretKey) MarshalSize() int {
+	return sk.GetDataLen(true)
+}
+
 // MarshalBinary encodes a secret key in a byte slice.
 func (sk *SecretKey) MarshalBinary() (data []byte, err error) {
 	data = make([]byte, sk.GetDataLen(true))
@@ -100,6 +227,12 @@ func (pk *PublicKey) GetDataLen(WithMetadata bool) (dataLen int) {
 	return pk.Value[0].GetDataLen(WithMetadata) + pk.Value[1].GetDataLen(WithMetadata)
 }
 
+// MarshalSize returns the exact number of bytes that MarshalBinary produces for the target PublicKey,
+// without serializing it.
+func (pk *PublicKey) MarshalSize() int {
+	return pk.GetDataLen(true)
+}
+
 // MarshalBinary encodes a PublicKey in a byte slice.
 func (pk *PublicKey) MarshalBinary() (data []byte, err error) {
 	data = make([]byte, pk.GetDataLen(true))
@@ -132,6 +265,54 @@ func (pk *PublicKey) UnmarshalBinary(data []byte) (err error) {
 	return
 }
 
+// GetDataLen returns the length in bytes of the target SeededPublicKey.
+func (spk *SeededPublicKey) GetDataLen(WithMetadata bool) (dataLen int) {
+	// MetaData is :
+	// 1 byte : length of the seed
+	if WithMetadata {
+		dataLen++
+	}
+	return dataLen + len(spk.Seed) + spk.Value.GetDataLen(WithMetadata)
+}
+
+// MarshalSize returns the exact number of bytes that MarshalBinary produces for the target SeededPublicKey,
+// without serializing it.
+func (spk *SeededPublicKey) MarshalSize() int {
+	return spk.GetDataLen(true)
+}
+
+// MarshalBinary encodes a SeededPublicKey in a byte slice.
+func (spk *SeededPublicKey) MarshalBinary() (data []byte, err error) {
+
+	if len(spk.Seed) > 0xFF {
+		return nil, fmt.Errorf("seed is too long")
+	}
+
+	data = make([]byte, spk.GetDataLen(true))
+	data[0] = uint8(len(spk.Seed))
+	copy(data[1:], spk.Seed)
+
+	if _, err = spk.Value.WriteTo(data[1+len(spk.Seed):]); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// UnmarshalBinary decodes a previously marshaled SeededPublicKey in the target SeededPublicKey.
+func (spk *SeededPublicKey) UnmarshalBinary(data []byte) (err error) {
+
+	// 1 byte for the length of the seed, the seed, and the 2 bytes of the header of the PolyQP
+	if len(data) < 1 || len(data) < 1+int(data[0])+2 {
+		return errors.New("too small bytearray")
+	}
+
+	spk.Seed = append([]byte{}, data[1:1+int(data[0])]...)
+
+	_, err = spk.Value.DecodePolyNew(data[1+int(data[0]):])
+	return
+}
+
 // GetDataLen returns the length in bytes of the target EvaluationKey.
 func (rlk *RelinearizationKey) GetDataLen(WithMetadata bool) (dataLen int) {
 
@@ -334,3 +515,61 @@ func (rtks *RotationKeySet) UnmarshalBinary(data []byte) (err error) {
 
 	return nil
 }
+
+// CiphertextBuilder assembles a Ciphertext from its polynomials Value[i] marshaled independently
+// (see ring.Poly.MarshalBinary), which can be received in any order.
+type CiphertextBuilder struct {
+	value []*ring.Poly
+}
+
+// NewCiphertextBuilder creates a new CiphertextBuilder for a ciphertext of the given degree.
+func NewCiphertextBuilder(degree int) *CiphertextBuilder {
+	if degree < 0 {
+		panic("cannot NewCiphertextBuilder: degree cannot be negative")
+	}
+	return &CiphertextBuilder{value: make([]*ring.Poly, degree+1)}
+}
+
+// SetComponent decodes data as the polynomial Value[index] of the ciphertext. A component that
+// was already set is replaced.
+func (cb *CiphertextBuilder) SetComponent(index int, data []byte) (err error) {
+
+	if index < 0 || index >= len(cb.value) {
+		return fmt.Errorf("invalid component index %d for a ciphertext of degree %d", index, len(cb.value)-1)
+	}
+
+	if len(data) < 4 {
+		return errors.New("too small bytearray")
+	}
+
+	pol := new(ring.Poly)
+	if err = pol.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	cb.value[index] = pol
+
+	return nil
+}
+
+// Build returns the ciphertext whose components have been set with SetComponent. It returns an error
+// if a component is missing or if the components do not share the same ring degree, level and domain.
+// The returned ciphertext shares its polynomials with the receiver.
+func (cb *CiphertextBuilder) Build() (*Ciphertext, error) {
+
+	for i, pol := range cb.value {
+
+		if pol == nil {
+			return nil, fmt.Errorf("missing component %d", i)
+		}
+
+		if ref := cb.value[0]; pol.Degree() != ref.Degree() || pol.Level() != ref.Level() || pol.IsNTT != ref.IsNTT {
+			return nil, fmt.Errorf("component %d does not match the ring degree, level or domain of component 0", i)
+		}
+	}
+
+	value := make([]*ring.Poly, len(cb.value))
+	copy(value, cb.value)
+
+	return &Ciphertext{Value: value}, nil
+}
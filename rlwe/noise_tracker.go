@@ -0,0 +1,169 @@
+package rlwe
+
+import (
+	"fmt"
+	"math"
+)
+
+// NoiseBudgetTracker is a symbolic estimate of the noise of a ciphertext of a scheme with a plaintext modulus,
+// which the BFV scheme attaches to its ciphertexts through their NoiseTracker field and updates in its Evaluator.
+// The estimate is a worst-case bound on the infinity norm of the noise, in the coefficient domain, and is tracked
+// as its base-two logarithm. It is meant for parameter selection: it lets users check that a circuit fits the
+// parameters without running it and decrypting the result.
+//
+// The bounds are heuristic upper bounds following the Fan-Vercauteren analysis with an expansion factor of N for
+// the products of polynomials, and are therefore pessimistic, in particular for multiplications.
+type NoiseBudgetTracker struct {
+	params   Parameters
+	t        uint64
+	level    int
+	logNoise float64
+}
+
+// NewNoiseBudgetTracker creates a new NoiseBudgetTracker for a fresh encryption at the given level under the
+// parameters params, with plaintext modulus t. The noise of a fresh encryption is bounded by
+// B * (1 + N + h) / P + (1 + h) / 2, with B the bound of the error distribution and h the Hamming weight of the
+// secret, or by B * (1 + N + h) if the parameters have no modulus P.
+func NewNoiseBudgetTracker(params Parameters, t uint64, level int) *NoiseBudgetTracker {
+
+	if t < 2 {
+		panic(fmt.Errorf("cannot NewNoiseBudgetTracker: t=%d must be at least 2", t))
+	}
+
+	if level < 0 || level > params.MaxLevel() {
+		panic(fmt.Errorf("cannot NewNoiseBudgetTracker: level=%d is not in [0, %d]", level, params.MaxLevel()))
+	}
+
+	nt := &NoiseBudgetTracker{params: params, t: t, level: level}
+
+	fresh := float64(params.ErrorBound()) * (1 + float64(params.N()) + nt.h())
+	if params.PCount() > 0 {
+		var logP float64
+		for _, pi := range params.P() {
+			logP += math.Log2(float64(pi))
+		}
+		fresh = fresh/math.Exp2(logP) + nt.rounding()
+	}
+
+	nt.logNoise = math.Log2(fresh)
+
+	return nt
+}
+
+// CopyNew returns a deep copy of the target NoiseBudgetTracker.
+func (nt *NoiseBudgetTracker) CopyNew() *NoiseBudgetTracker {
+	cpy := *nt
+	return &cpy
+}
+
+// Level returns the level of the tracked ciphertext.
+func (nt *NoiseBudgetTracker) Level() int {
+	return nt.level
+}
+
+// LogNoise returns the base-two logarithm of the bound on the infinity norm of the noise.
+func (nt *NoiseBudgetTracker) LogNoise() float64 {
+	return nt.logNoise
+}
+
+// PredictedBits returns the predicted remaining noise budget in bits, i.e. log2(Q/(2t)) - LogNoise(), with Q the
+// modulus at the level of the tracked ciphertext. The decryption is expected to be correct as long as the budget
+// is positive.
+func (nt *NoiseBudgetTracker) PredictedBits() float64 {
+	var logQ float64
+	for _, qi := range nt.params.Q()[:nt.level+1] {
+		logQ += math.Log2(float64(qi))
+	}
+	return logQ - 1 - math.Log2(float64(nt.t)) - nt.logNoise
+}
+
+// Add updates the estimate for the sum (or difference) of the tracked ciphertext with the ciphertext tracked
+// by other, at their minimum level: the bounds of the noises are added. A nil other stands for a plaintext
+// operand, which does not change the noise.
+func (nt *NoiseBudgetTracker) Add(other *NoiseBudgetTracker) {
+	if other == nil {
+		return
+	}
+	if other.level < nt.level {
+		nt.level = other.level
+	}
+	nt.logNoise = logAddExp2(nt.logNoise, other.logNoise)
+}
+
+// MulScalar updates the estimate for the product of the tracked ciphertext with a scalar, whose centered
+// representative modulo t multiplies the bound of the noise.
+func (nt *NoiseBudgetTracker) MulScalar(scalar uint64) {
+	scalar %= nt.t
+	if nt.t-scalar < scalar {
+		scalar = nt.t - scalar
+	}
+	if scalar > 1 {
+		nt.logNoise += math.Log2(float64(scalar))
+	}
+}
+
+// Mul updates the estimate for the product of the tracked ciphertext with the ciphertext tracked by other, at their
+// minimum level. The bound of the noise of the tensored ciphertext is N * t * (1 + h) * (e0 + e1) + (1 + N * h)^2.
+// A nil other stands for a plaintext operand with coefficients in [-t/2, t/2], in which case the bound becomes
+// N * t/2 * e0.
+func (nt *NoiseBudgetTracker) Mul(other *NoiseBudgetTracker) {
+
+	N := float64(nt.params.N())
+	t := float64(nt.t)
+
+	if other == nil {
+		nt.logNoise += math.Log2(N * t / 2)
+		return
+	}
+
+	if other.level < nt.level {
+		nt.level = other.level
+	}
+
+	h := nt.h()
+	nt.logNoise = logAddExp2(math.Log2(N*t*(1+h))+logAddExp2(nt.logNoise, other.logNoise), 2*math.Log2(1+N*h))
+}
+
+// KeySwitch updates the estimate for a key-switching of the tracked ciphertext, e.g. a relinearization or a rotation.
+// With a modulus P, the key-switching adds a noise bounded by beta * N * B + (1 + h) / 2, with beta the number of
+// digits of the RNS decomposition at the level of the ciphertext. Without modulus P, the digits are the moduli q_i
+// themselves and the added noise is bounded by (level+1) * N * B * max(q_i).
+func (nt *NoiseBudgetTracker) KeySwitch() {
+
+	N := float64(nt.params.N())
+	B := float64(nt.params.ErrorBound())
+
+	var ks float64
+	if alpha := nt.params.PCount(); alpha > 0 {
+		beta := float64((nt.level + alpha) / alpha)
+		ks = beta*N*B + nt.rounding()
+	} else {
+		var qmax uint64
+		for _, qi := range nt.params.Q()[:nt.level+1] {
+			if qi > qmax {
+				qmax = qi
+			}
+		}
+		ks = float64(nt.level+1) * N * B * float64(qmax)
+	}
+
+	nt.logNoise = logAddExp2(nt.logNoise, math.Log2(ks))
+}
+
+// h returns the Hamming weight of the secret as a float64.
+func (nt *NoiseBudgetTracker) h() float64 {
+	return float64(nt.params.HammingWeight())
+}
+
+// rounding returns the bound (1 + h) / 2 on the error of a rounded division of a ciphertext.
+func (nt *NoiseBudgetTracker) rounding() float64 {
+	return (1 + nt.h()) / 2
+}
+
+// logAddExp2 returns log2(2^a + 2^b) without overflow.
+func logAddExp2(a, b float64) float64 {
+	if a < b {
+		a, b = b, a
+	}
+	return a + math.Log2(1+math.Exp2(b-a))
+}
@@ -1,6 +1,7 @@
 package rlwe
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -26,6 +27,35 @@ const MaxModuliSize = 60
 // DefaultSigma is the default error distribution standard deviation
 const DefaultSigma = 3.2
 
+// DefaultGaussianTailFactor is the default tail-cut of the error distribution, in multiples of the standard deviation.
+const DefaultGaussianTailFactor = 6.0
+
+// ErrorDistribution is a type for the distributions from which the encryption errors are sampled.
+type ErrorDistribution uint8
+
+const (
+	// DiscreteGaussian is the truncated discrete Gaussian distribution of standard deviation Sigma (default).
+	DiscreteGaussian ErrorDistribution = iota
+	// RoundedContinuous is the truncated continuous Gaussian distribution of standard deviation Sigma, rounded to the nearest integer.
+	RoundedContinuous
+	// CenteredBinomial is the truncated centered binomial distribution of standard deviation ~Sigma.
+	CenteredBinomial
+)
+
+// String returns the name of the error distribution.
+func (d ErrorDistribution) String() string {
+	switch d {
+	case DiscreteGaussian:
+		return "DiscreteGaussian"
+	case RoundedContinuous:
+		return "RoundedContinuous"
+	case CenteredBinomial:
+		return "CenteredBinomial"
+	default:
+		return fmt.Sprintf("ErrorDistribution(%d)", uint8(d))
+	}
+}
+
 // GaloisGen is an integer of order N=2^d modulo M=2N and that spans Z_M with the integer -1.
 // The j-th ring automorphism takes the root zeta to zeta^(5j).
 const GaloisGen uint64 = 5
@@ -39,31 +69,41 @@ const GaloisGen uint64 = 5
 // the Q and P fields to the desired moduli chain, or by setting the LogQ and LogP fields to
 // the desired moduli sizes.
 //
-// Optionally, users may specify the error variance (Sigma) and secrets' density (H) and the ring
-// type (RingType). If left unset, standard default values for these field are substituted at
-// parameter creation (see NewParametersFromLiteral).
+// Optionally, users may specify the error variance (Sigma), the error distribution (ErrorDistribution),
+// secrets' density (H) and the ring type (RingType). If left unset, standard default values for these
+// field are substituted at parameter creation (see NewParametersFromLiteral). Users may also label the
+// parameters with the bit-security they ensure (SecurityLevel), which is not checked, and require a minimum
+// relative gap between consecutive moduli of Q (MinModuliGap), which is checked (see Parameters.ModuliGap).
 type ParametersLiteral struct {
-	LogN     int
-	Q        []uint64
-	P        []uint64
-	LogQ     []int `json:",omitempty"`
-	LogP     []int `json:",omitempty"`
-	Sigma    float64
-	H        int
-	RingType ring.Type
+	LogN               int
+	Q                  []uint64
+	P                  []uint64
+	LogQ               []int `json:",omitempty"`
+	LogP               []int `json:",omitempty"`
+	Sigma              float64
+	ErrorDistribution  ErrorDistribution `json:",omitempty"`
+	GaussianTailFactor float64           `json:",omitempty"`
+	H                  int
+	RingType           ring.Type
+	SecurityLevel      int     `json:",omitempty"`
+	MinModuliGap       float64 `json:",omitempty"`
 }
 
 // Parameters represents a set of generic RLWE parameters. Its fields are private and
 // immutable. See ParametersLiteral for user-specified parameters.
 type Parameters struct {
-	logN     int
-	qi       []uint64
-	pi       []uint64
-	sigma    float64
-	h        int
-	ringQ    *ring.Ring
-	ringP    *ring.Ring
-	ringType ring.Type
+	logN       int
+	qi         []uint64
+	pi         []uint64
+	sigma      float64
+	tailFactor float64
+	errorDist  ErrorDistribution
+	h          int
+	ringQ      *ring.Ring
+	ringP      *ring.Ring
+	ringType   ring.Type
+	secLevel   int
+	pProducts  []*big.Int
 }
 
 // NewParameters returns a new set of generic RLWE parameters from the given ring degree logn, moduli q and p, and
@@ -76,12 +116,13 @@ func NewParameters(logn int, q, p []uint64, h int, sigma float64, ringType ring.
 	}
 
 	params := Parameters{
-		logN:     logn,
-		pi:       make([]uint64, len(p)),
-		qi:       make([]uint64, len(q)),
-		h:        h,
-		sigma:    sigma,
-		ringType: ringType,
+		logN:       logn,
+		pi:         make([]uint64, len(p)),
+		qi:         make([]uint64, len(q)),
+		h:          h,
+		sigma:      sigma,
+		tailFactor: DefaultGaussianTailFactor,
+		ringType:   ringType,
 	}
 
 	// pre-check that moduli chain is of valid size and that all factors are prime.
@@ -93,7 +134,19 @@ func NewParameters(logn int, q, p []uint64, h int, sigma float64, ringType ring.
 	copy(params.qi, q)
 	copy(params.pi, p)
 
-	return params, params.initRings()
+	if err = params.initRings(); err != nil {
+		return Parameters{}, err
+	}
+
+	params.pProducts = make([]*big.Int, len(p))
+	for i := range p {
+		params.pProducts[i] = new(big.Int).SetUint64(p[i])
+		if i > 0 {
+			params.pProducts[i].Mul(params.pProducts[i], params.pProducts[i-1])
+		}
+	}
+
+	return params, nil
 }
 
 // NewParametersFromLiteral instantiate a set of generic RLWE parameters from a ParametersLiteral specification.
@@ -107,8 +160,41 @@ func NewParameters(logn int, q, p []uint64, h int, sigma float64, ringType ring.
 //
 // If the error variance is left unset, its value is set to `DefaultSigma`.
 //
+// If the ErrorDistribution is left unset, the default value is DiscreteGaussian.
+//
+// If the GaussianTailFactor is left unset, its value is set to `DefaultGaussianTailFactor`.
+//
 // If the RingType is left unset, the default value is ring.Standard.
-func NewParametersFromLiteral(paramDef ParametersLiteral) (Parameters, error) {
+//
+// If the SecurityLevel is left unset, the parameters are not labeled with a security level.
+//
+// If the MinModuliGap is left unset, the gap between the moduli of Q is not checked.
+func NewParametersFromLiteral(paramDef ParametersLiteral) (params Parameters, err error) {
+
+	if params, err = newParametersFromLiteral(paramDef); err != nil {
+		return Parameters{}, err
+	}
+
+	if params, err = params.withErrorDistribution(paramDef.ErrorDistribution); err != nil {
+		return Parameters{}, err
+	}
+
+	if paramDef.GaussianTailFactor != 0 {
+		if params, err = params.withGaussianTailFactor(paramDef.GaussianTailFactor); err != nil {
+			return Parameters{}, err
+		}
+	}
+
+	if paramDef.MinModuliGap != 0 {
+		if err = params.checkModuliGap(paramDef.MinModuliGap); err != nil {
+			return Parameters{}, err
+		}
+	}
+
+	return params.withSecurityLevel(paramDef.SecurityLevel)
+}
+
+func newParametersFromLiteral(paramDef ParametersLiteral) (Parameters, error) {
 
 	if paramDef.H == 0 {
 		paramDef.H = 1 << (paramDef.LogN - 1)
@@ -193,6 +279,71 @@ func (p Parameters) HammingWeight() int {
 	return p.h
 }
 
+// ErrorDistribution returns the distribution from which the encryption errors are sampled.
+func (p Parameters) ErrorDistribution() ErrorDistribution {
+	return p.errorDist
+}
+
+// withErrorDistribution returns a copy of the receiver with the error distribution set to errorDist.
+func (p Parameters) withErrorDistribution(errorDist ErrorDistribution) (Parameters, error) {
+	switch errorDist {
+	case DiscreteGaussian, RoundedContinuous, CenteredBinomial:
+		p.errorDist = errorDist
+		return p, nil
+	default:
+		return Parameters{}, fmt.Errorf("invalid error distribution: %s", errorDist)
+	}
+}
+
+// GaussianTailFactor returns the tail-cut of the error distribution, in multiples of the standard deviation:
+// the errors are sampled with a bound of int(GaussianTailFactor() * Sigma()).
+func (p Parameters) GaussianTailFactor() float64 {
+	return p.tailFactor
+}
+
+// ErrorBound returns the bound on the absolute value of the sampled errors, int(GaussianTailFactor() * Sigma()).
+func (p Parameters) ErrorBound() int {
+	return int(p.tailFactor * p.sigma)
+}
+
+// withGaussianTailFactor returns a copy of the receiver with the tail-cut of the error distribution set to tailFactor.
+func (p Parameters) withGaussianTailFactor(tailFactor float64) (Parameters, error) {
+	if !(tailFactor >= 1) || math.IsInf(tailFactor, 0) {
+		return Parameters{}, fmt.Errorf("invalid Gaussian tail factor: %f (must be a finite value >= 1)", tailFactor)
+	}
+	p.tailFactor = tailFactor
+	return p, nil
+}
+
+// SecurityLevel returns the bit-security label of the parameters (128, 192 or 256), or 0 if
+// the parameters are not labeled. The label is informative and is not checked against the
+// moduli and the ring degree.
+func (p Parameters) SecurityLevel() int {
+	return p.secLevel
+}
+
+// withSecurityLevel returns a copy of the receiver labeled with the security level secLevel.
+func (p Parameters) withSecurityLevel(secLevel int) (Parameters, error) {
+	switch secLevel {
+	case 0, 128, 192, 256:
+		p.secLevel = secLevel
+		return p, nil
+	default:
+		return Parameters{}, fmt.Errorf("invalid security level: %d (must be 0, 128, 192 or 256)", secLevel)
+	}
+}
+
+// checkModuliGap returns an error if two consecutive moduli of Q have a relative gap smaller than minGap (see ModuliGap).
+func (p Parameters) checkModuliGap(minGap float64) error {
+	if !(minGap > 0 && minGap < 1) {
+		return fmt.Errorf("invalid minimum moduli gap: %f (must be in (0, 1))", minGap)
+	}
+	if gap, i := p.moduliGap(); gap < minGap {
+		return fmt.Errorf("moduli Q%d=%d and Q%d=%d have a relative gap of %e, smaller than the minimum %e", i, p.qi[i], i+1, p.qi[i+1], gap, minGap)
+	}
+	return nil
+}
+
 // Sigma returns standard deviation of the noise distribution
 func (p Parameters) Sigma() float64 {
 	return p.sigma
@@ -220,6 +371,30 @@ func (p Parameters) QiFloat64(level int) float64 {
 	return float64(p.qi[level])
 }
 
+// ModuliGap returns the minimum relative gap |Qi+1 - Qi| / max(Qi, Qi+1) between two consecutive moduli
+// of the ciphertext modulus Q, or +Inf if Q has a single modulus. Moduli close in value make the
+// rescaling and the basis extension less accurate.
+func (p Parameters) ModuliGap() float64 {
+	gap, _ := p.moduliGap()
+	return gap
+}
+
+// moduliGap returns ModuliGap and the index i of the pair (Qi, Qi+1) that achieves it, or -1 if Q has a single modulus.
+func (p Parameters) moduliGap() (gap float64, index int) {
+	gap, index = math.Inf(1), -1
+	for i := 0; i < len(p.qi)-1; i++ {
+		// The difference is computed exactly on uint64 before the conversion to float64.
+		lo, hi := p.qi[i], p.qi[i+1]
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		if g := float64(hi-lo) / float64(hi); g < gap {
+			gap, index = g, i
+		}
+	}
+	return
+}
+
 // QCount returns the number of factors of the ciphertext modulus Q
 func (p Parameters) QCount() int {
 	return len(p.qi)
@@ -255,6 +430,16 @@ func (p Parameters) PBigInt() *big.Int {
 	return pInt
 }
 
+// ProductOfP returns the product of the factors P[0], ..., P[level] of the ciphertext modulus extension P.
+// The partial products are computed once when the parameters are created and the returned value is
+// shared with the receiver: it must not be modified.
+func (p Parameters) ProductOfP(level int) *big.Int {
+	if level < 0 || level >= len(p.pi) {
+		panic(fmt.Errorf("cannot ProductOfP: level %d is not in [0, %d)", level, len(p.pi)))
+	}
+	return p.pProducts[level]
+}
+
 // QP return the extended ciphertext-space modulus QP in RNS representation.
 func (p Parameters) QP() []uint64 {
 	qp := make([]uint64, len(p.qi)+len(p.pi))
@@ -374,6 +559,177 @@ func (p Parameters) InverseGaloisElement(galEl uint64) uint64 {
 	return ring.ModExp(galEl, p.ringQ.NthRoot-1, p.ringQ.NthRoot)
 }
 
+// NewCiphertext returns a new Ciphertext of the given degree and level in the coefficient domain.
+// The Encryptor encrypts in the domain given by the IsNTT flag of the output ciphertext: the BFV scheme
+// uses ciphertexts in the coefficient domain.
+func (p Parameters) NewCiphertext(degree, level int) *Ciphertext {
+	return NewCiphertext(p, degree, level)
+}
+
+// NewCiphertextNTT returns a new Ciphertext of the given degree and level in the NTT domain.
+// The Encryptor encrypts in the domain given by the IsNTT flag of the output ciphertext: the CKKS scheme
+// uses ciphertexts in the NTT domain.
+func (p Parameters) NewCiphertextNTT(degree, level int) *Ciphertext {
+	return NewCiphertextNTT(p, degree, level)
+}
+
+// NewPlaintextFromPoly returns a new Plaintext wrapping pol without copying it, at the level of pol.
+// The returned plaintext aliases pol and takes ownership of it: its Value is pol itself, whose IsNTT flag is
+// set to isNTT, and any later modification of pol, including of its flags, is a modification of the plaintext.
+// Callers that need to keep pol unchanged should pass a copy. The method panics if pol does not have N
+// coefficients per modulus or if its number of moduli is not in [1, QCount()].
+func (p Parameters) NewPlaintextFromPoly(pol *ring.Poly, isNTT bool) *Plaintext {
+
+	if pol == nil {
+		panic("cannot NewPlaintextFromPoly: pol cannot be nil")
+	}
+
+	if len(pol.Coeffs) == 0 || len(pol.Coeffs) > p.QCount() {
+		panic(fmt.Errorf("cannot NewPlaintextFromPoly: number of moduli %d is not in [1, %d]", len(pol.Coeffs), p.QCount()))
+	}
+
+	for i := range pol.Coeffs {
+		if len(pol.Coeffs[i]) != p.N() {
+			panic(fmt.Errorf("cannot NewPlaintextFromPoly: pol has %d coefficients for the modulus %d but the ring degree is %d", len(pol.Coeffs[i]), i, p.N()))
+		}
+	}
+
+	pol.IsNTT = isNTT
+
+	return &Plaintext{Value: pol}
+}
+
+// AddSecretKeys returns a new SecretKey whose value is the sum of the values of sks modulo QP.
+// In threshold settings, this is the ideal secret-key under which the aggregated public key of the parties
+// with secret-keys sks decrypts. The method panics if sks is empty or if the keys do not all have the
+// ring degree and moduli of the receiver.
+func (p Parameters) AddSecretKeys(sks ...*SecretKey) *SecretKey {
+
+	if len(sks) == 0 {
+		panic("cannot AddSecretKeys: sks cannot be empty")
+	}
+
+	levelQ, levelP := p.QCount()-1, p.PCount()-1
+
+	for i, sk := range sks {
+
+		if sk == nil || sk.Value.Q == nil {
+			panic(fmt.Errorf("cannot AddSecretKeys: sks[%d] cannot be nil", i))
+		}
+
+		if sk.Value.Q.Degree() != p.N() || sk.Value.Q.Level() != levelQ {
+			panic(fmt.Errorf("cannot AddSecretKeys: sks[%d] does not match the ring degree or moduli Q of the parameters", i))
+		}
+
+		if levelP > -1 && (sk.Value.P == nil || sk.Value.P.Degree() != p.N() || sk.Value.P.Level() != levelP) {
+			panic(fmt.Errorf("cannot AddSecretKeys: sks[%d] does not match the ring degree or moduli P of the parameters", i))
+		}
+	}
+
+	ringQP := p.RingQP()
+
+	skSum := NewSecretKey(p)
+	skSum.Value.Q.IsNTT, skSum.Value.Q.IsMForm = sks[0].Value.Q.IsNTT, sks[0].Value.Q.IsMForm
+	if levelP > -1 {
+		skSum.Value.P.IsNTT, skSum.Value.P.IsMForm = sks[0].Value.P.IsNTT, sks[0].Value.P.IsMForm
+	}
+
+	for _, sk := range sks {
+		ringQP.AddLvl(levelQ, levelP, skSum.Value, sk.Value, skSum.Value)
+	}
+
+	return skSum
+}
+
+// PackShiftedLWE packs LWE samples (a[i], b[i]) modulo Q[0] that share the same mask up to a negacyclic shift
+// into a single RLWE ciphertext at level 0 in the coefficient domain, such that the i-th coefficient of its
+// decryption is b[i] + <a[i], s>, where s is the vector of the coefficients of the RLWE secret.
+// The i-th coefficient of c1*s is sum_{j<=i} c1[i-j]*s[j] - sum_{j>i} c1[N+i-j]*s[j], thus a[0] fully
+// determines c1 and each a[i] must be equal to the negacyclic shift of a[0] by i positions. This is the case
+// of the samples extracted from the first coefficients of an RLWE ciphertext, which the method maps back to it
+// without any key material. It does not pack arbitrary LWE samples, such as TFHE-style samples: these must
+// each be mapped to an RLWE ciphertext with LWEToRLWE and then repacked into a single ciphertext with
+// automorphisms and key-switching (see examples/rlwe/lwe_bridge).
+// The method panics if more than N samples are given, if the samples do not have N coefficients,
+// or if they do not follow the above layout.
+func (p Parameters) PackShiftedLWE(a [][]uint64, b []uint64) *Ciphertext {
+
+	if p.ringType != ring.Standard {
+		panic("cannot PackShiftedLWE: only supported for ring.Standard")
+	}
+
+	N := p.N()
+
+	if len(a) == 0 || len(a) != len(b) || len(a) > N {
+		panic(fmt.Sprintf("cannot PackShiftedLWE: number of samples must be in [1, %d] and match len(b)", N))
+	}
+
+	for i := range a {
+		if len(a[i]) != N {
+			panic(fmt.Sprintf("cannot PackShiftedLWE: samples must have %d coefficients", N))
+		}
+	}
+
+	q := p.ringQ.Modulus[0]
+
+	ct := NewCiphertext(p, 1, 0)
+	c0, c1 := ct.Value[0].Coeffs[0], ct.Value[1].Coeffs[0]
+
+	lweToRLWECoeffs(a[0], q, c1)
+
+	for i := range a {
+
+		for j := 0; j < N; j++ {
+
+			want := c1[(N+i-j)%N]
+			if j > i {
+				want = (q - want) % q
+			}
+
+			if a[i][j]%q != want {
+				panic(fmt.Sprintf("cannot PackShiftedLWE: sample %d is not the negacyclic shift of sample 0", i))
+			}
+		}
+
+		c0[i] = b[i] % q
+	}
+
+	return ct
+}
+
+// LWEToRLWE maps the LWE sample (a, b) modulo Q[0] to an RLWE ciphertext at level 0 in the coefficient domain,
+// such that the constant coefficient of its decryption is b + <a, s>, where s is the vector of the coefficients
+// of the RLWE secret. The other coefficients of the decryption are not meaningful. Any LWE sample of dimension N
+// is accepted. The method panics if a does not have N coefficients.
+func (p Parameters) LWEToRLWE(a []uint64, b uint64) *Ciphertext {
+
+	if p.ringType != ring.Standard {
+		panic("cannot LWEToRLWE: only supported for ring.Standard")
+	}
+
+	if len(a) != p.N() {
+		panic(fmt.Sprintf("cannot LWEToRLWE: sample must have %d coefficients", p.N()))
+	}
+
+	q := p.ringQ.Modulus[0]
+
+	ct := NewCiphertext(p, 1, 0)
+	lweToRLWECoeffs(a, q, ct.Value[1].Coeffs[0])
+	ct.Value[0].Coeffs[0][0] = b % q
+
+	return ct
+}
+
+// lweToRLWECoeffs sets c1 = a[0] - a[N-1]X - ... - a[1]X^{N-1} mod q, such that the constant
+// coefficient of c1*s is <a, s>.
+func lweToRLWECoeffs(a []uint64, q uint64, c1 []uint64) {
+	N := len(c1)
+	c1[0] = a[0] % q
+	for k := 1; k < N; k++ {
+		c1[k] = (q - a[N-k]%q) % q
+	}
+}
+
 // Equals checks two Parameter structs for equality.
 func (p Parameters) Equals(other Parameters) bool {
 	res := p.logN == other.logN
@@ -381,7 +737,10 @@ func (p Parameters) Equals(other Parameters) bool {
 	res = res && utils.EqualSliceUint64(p.pi, other.pi)
 	res = res && (p.h == other.h)
 	res = res && (p.sigma == other.sigma)
+	res = res && (p.tailFactor == other.tailFactor)
+	res = res && (p.errorDist == other.errorDist)
 	res = res && (p.ringType == other.ringType)
+	res = res && (p.secLevel == other.secLevel)
 	return res
 }
 
@@ -413,7 +772,10 @@ func (p Parameters) MarshalBinary() ([]byte, error) {
 	// 1 byte : #P
 	// 8 byte : H
 	// 8 byte : sigma
+	// 8 byte : tailFactor
+	// 1 byte : errorDist
 	// 1 byte : ringType
+	// 1 byte : securityLevel / 64
 	// 8 * (#Q) : Q
 	// 8 * (#P) : P
 	b := utils.NewBuffer(make([]byte, 0, p.MarshalBinarySize()))
@@ -422,7 +784,10 @@ func (p Parameters) MarshalBinary() ([]byte, error) {
 	b.WriteUint8(uint8(len(p.pi)))
 	b.WriteUint64(uint64(p.h))
 	b.WriteUint64(math.Float64bits(p.sigma))
+	b.WriteUint64(math.Float64bits(p.tailFactor))
+	b.WriteUint8(uint8(p.errorDist))
 	b.WriteUint8(uint8(p.ringType))
+	b.WriteUint8(uint8(p.secLevel >> 6))
 	b.WriteUint64Slice(p.qi)
 	b.WriteUint64Slice(p.pi)
 	return b.Bytes(), nil
@@ -430,7 +795,7 @@ func (p Parameters) MarshalBinary() ([]byte, error) {
 
 // UnmarshalBinary decodes a []byte into a parameter set struct.
 func (p *Parameters) UnmarshalBinary(data []byte) error {
-	if len(data) < 11 {
+	if len(data) < 30 {
 		return fmt.Errorf("invalid rlwe.Parameter serialization")
 	}
 	b := utils.NewBuffer(data)
@@ -439,7 +804,10 @@ func (p *Parameters) UnmarshalBinary(data []byte) error {
 	lenP := int(b.ReadUint8())
 	h := int(b.ReadUint64())
 	sigma := math.Float64frombits(b.ReadUint64())
+	tailFactor := math.Float64frombits(b.ReadUint64())
+	errorDist := ErrorDistribution(b.ReadUint8())
 	ringType := ring.Type(b.ReadUint8())
+	secLevel := int(b.ReadUint8()) << 6
 
 	if err := checkSizeParams(logN, lenQ, lenP); err != nil {
 		return err
@@ -450,19 +818,40 @@ func (p *Parameters) UnmarshalBinary(data []byte) error {
 	b.ReadUint64Slice(qi)
 	b.ReadUint64Slice(pi)
 
-	var err error
-	*p, err = NewParameters(logN, qi, pi, h, sigma, ringType)
+	params, err := NewParameters(logN, qi, pi, h, sigma, ringType)
+	if err != nil {
+		return err
+	}
+
+	if params, err = params.withErrorDistribution(errorDist); err != nil {
+		return err
+	}
+
+	if params, err = params.withGaussianTailFactor(tailFactor); err != nil {
+		return err
+	}
+
+	*p, err = params.withSecurityLevel(secLevel)
 	return err
 }
 
 // MarshalBinarySize returns the length of the []byte encoding of the reciever.
 func (p Parameters) MarshalBinarySize() int {
-	return 20 + (len(p.qi)+len(p.pi))<<3
+	return 30 + (len(p.qi)+len(p.pi))<<3
+}
+
+// Fingerprint returns a SHA-256 digest of the binary representation of the parameters (see MarshalBinary).
+func (p Parameters) Fingerprint() (digest [32]byte) {
+	data, err := p.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	return sha256.Sum256(data)
 }
 
 // MarshalJSON returns a JSON representation of this parameter set. See `Marshal` from the `encoding/json` package.
 func (p Parameters) MarshalJSON() ([]byte, error) {
-	return json.Marshal(&ParametersLiteral{LogN: p.logN, Q: p.qi, P: p.pi, H: p.h, Sigma: p.sigma})
+	return json.Marshal(&ParametersLiteral{LogN: p.logN, Q: p.qi, P: p.pi, H: p.h, Sigma: p.sigma, ErrorDistribution: p.errorDist, GaussianTailFactor: p.tailFactor, SecurityLevel: p.secLevel})
 }
 
 // UnmarshalJSON reads a JSON representation of a parameter set into the receiver Parameter. See `Unmarshal` from the `encoding/json` package.
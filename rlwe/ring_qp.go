@@ -130,6 +130,17 @@ func (r *RingQP) SubLvl(levelQ, levelP int, p1, p2, pOut PolyQP) {
 	}
 }
 
+// NegLvl negates p1 coefficient-wise and writes the result on p2.
+// The operation is performed at levelQ for the ringQ and levelP for the ringP.
+func (r *RingQP) NegLvl(levelQ, levelP int, p1, p2 PolyQP) {
+	if r.RingQ != nil {
+		r.RingQ.NegLvl(levelQ, p1.Q, p2.Q)
+	}
+	if r.RingP != nil {
+		r.RingP.NegLvl(levelP, p1.P, p2.P)
+	}
+}
+
 // NTTLvl computes the NTT of p1 and returns the result on p2.
 // The operation is performed at levelQ for the ringQ and levelP for the ringP.
 func (r *RingQP) NTTLvl(levelQ, levelP int, p, pOut PolyQP) {
@@ -152,6 +163,42 @@ func (r *RingQP) InvNTTLvl(levelQ, levelP int, p, pOut PolyQP) {
 	}
 }
 
+// NTTLvlParallel computes the NTT of p1 and returns the result on p2.
+// The operation is performed at levelQ for the ringQ and levelP for the ringP.
+// The limbs of p1 are distributed across nbThreads goroutines and the result
+// is identical to the one of NTTLvl.
+func (r *RingQP) NTTLvlParallel(levelQ, levelP int, p, pOut PolyQP, nbThreads int) {
+	if nbThreads < 2 {
+		r.NTTLvl(levelQ, levelP, p, pOut)
+		return
+	}
+	ring.EvaluateLimbsParallel(nbThreads, (*ring.Ring).NTTSingle, r.limbsOperands(levelQ, levelP, p, pOut)...)
+}
+
+// InvNTTLvlParallel computes the inverse-NTT of p1 and returns the result on p2.
+// The operation is performed at levelQ for the ringQ and levelP for the ringP.
+// The limbs of p1 are distributed across nbThreads goroutines and the result
+// is identical to the one of InvNTTLvl.
+func (r *RingQP) InvNTTLvlParallel(levelQ, levelP int, p, pOut PolyQP, nbThreads int) {
+	if nbThreads < 2 {
+		r.InvNTTLvl(levelQ, levelP, p, pOut)
+		return
+	}
+	ring.EvaluateLimbsParallel(nbThreads, (*ring.Ring).InvNTTSingle, r.limbsOperands(levelQ, levelP, p, pOut)...)
+}
+
+// limbsOperands returns the operands of ring.EvaluateLimbsParallel mapping the limbs of p to the limbs of pOut
+// at levelQ for the ringQ and levelP for the ringP.
+func (r *RingQP) limbsOperands(levelQ, levelP int, p, pOut PolyQP) (limbs []ring.LimbsOperand) {
+	if r.RingQ != nil {
+		limbs = append(limbs, ring.LimbsOperand{Ring: r.RingQ, Level: levelQ, P1: p.Q, P2: pOut.Q})
+	}
+	if r.RingP != nil {
+		limbs = append(limbs, ring.LimbsOperand{Ring: r.RingP, Level: levelP, P1: p.P, P2: pOut.P})
+	}
+	return
+}
+
 // NTTLazyLvl computes the NTT of p1 and returns the result on p2.
 // The operation is performed at levelQ for the ringQ and levelP for the ringP.
 // Output values are in the range [0, 2q-1].
@@ -2,8 +2,12 @@ package rlwe
 
 import (
 	"encoding/json"
+	"fmt"
 	"runtime"
+	"sync"
 	"testing"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
 )
 
 func BenchmarkRLWE(b *testing.B) {
@@ -27,6 +31,7 @@ func BenchmarkRLWE(b *testing.B) {
 		keySwitcher := NewKeySwitcher(params)
 
 		for _, testSet := range []func(kgen KeyGenerator, keySwitcher *KeySwitcher, b *testing.B){
+			benchEncryptor,
 			benchHoistedKeySwitch,
 		} {
 			testSet(kgen, keySwitcher, b)
@@ -35,6 +40,67 @@ func BenchmarkRLWE(b *testing.B) {
 	}
 }
 
+func benchEncryptor(kgen KeyGenerator, keySwitcher *KeySwitcher, b *testing.B) {
+
+	params := kgen.(*keyGenerator).params
+	pk := kgen.GenPublicKey(kgen.GenSecretKey())
+	plaintext := NewPlaintext(params, params.MaxLevel())
+	plaintext.Value.IsNTT = true
+	ciphertext := NewCiphertextNTT(params, 1, plaintext.Level())
+
+	for _, nbThreads := range []int{1, 2, 4, 8} {
+		encryptor := NewEncryptorWithOptions(params, pk, EncryptorOptions{NTTThreads: nbThreads})
+		b.Run(testString(params, fmt.Sprintf("Encrypt/Pk/NTTThreads=%d/", nbThreads)), func(b *testing.B) {
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				encryptor.Encrypt(plaintext, ciphertext)
+			}
+		})
+	}
+
+	encryptorSk := NewEncryptor(params, kgen.GenSecretKey())
+	for _, isNTT := range []bool{true, false} {
+		ciphertext := NewCiphertextNTT(params, 1, plaintext.Level())
+		ciphertext.Value[0].IsNTT = isNTT
+		ciphertext.Value[1].IsNTT = isNTT
+		b.Run(testString(params, fmt.Sprintf("Encrypt/Sk/NTT=%t/", isNTT)), func(b *testing.B) {
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				encryptorSk.Encrypt(plaintext, ciphertext)
+			}
+		})
+	}
+
+	if params.PCount() == 0 {
+		return
+	}
+
+	for _, nbWorkers := range []int{1, 2, 4, 8} {
+		pool := ring.NewBasisExtenderPool(params.RingQ(), params.RingP(), nbWorkers)
+		encryptor := NewEncryptorWithOptions(params, pk, EncryptorOptions{BasisExtenderPool: pool})
+		workers := make([]Encryptor, nbWorkers)
+		ciphertexts := make([]*Ciphertext, nbWorkers)
+		for i := range workers {
+			workers[i] = encryptor.ShallowCopy()
+			ciphertexts[i] = NewCiphertextNTT(params, 1, plaintext.Level())
+		}
+		b.Run(testString(params, fmt.Sprintf("Encrypt/Pk/BasisExtenderPool/Workers=%d/", nbWorkers)), func(b *testing.B) {
+			b.ResetTimer()
+			var wg sync.WaitGroup
+			for w := range workers {
+				wg.Add(1)
+				go func(w int) {
+					defer wg.Done()
+					for i := w; i < b.N; i += nbWorkers {
+						workers[w].Encrypt(plaintext, ciphertexts[w])
+					}
+				}(w)
+			}
+			wg.Wait()
+		})
+	}
+}
+
 func benchHoistedKeySwitch(kgen KeyGenerator, keySwitcher *KeySwitcher, b *testing.B) {
 
 	params := kgen.(*keyGenerator).params
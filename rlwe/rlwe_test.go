@@ -1,6 +1,9 @@
 package rlwe
 
 import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -8,6 +11,7 @@ import (
 	"math/big"
 	"math/bits"
 	"runtime"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -53,12 +57,15 @@ func TestRLWE(t *testing.T) {
 		kgen := NewKeyGenerator(params)
 
 		for _, testSet := range []func(kgen KeyGenerator, t *testing.T){
+			testParameters,
 			testGenKeyPair,
 			testSwitchKeyGen,
 			testEncryptor,
 			testDecryptor,
 			testKeySwitcher,
 			testKeySwitchDimension,
+			testCiphertext,
+			testPackShiftedLWE,
 			testMarshaller,
 		} {
 			testSet(kgen, t)
@@ -132,12 +139,70 @@ func log2OfInnerSum(level int, ringQ *ring.Ring, poly *ring.Poly) (logSum int) {
 	return
 }
 
+func testParameters(kgen KeyGenerator, t *testing.T) {
+
+	params := kgen.(*keyGenerator).params
+
+	t.Run(testString(params, "Parameters/ModuliGap/"), func(t *testing.T) {
+
+		// Two consecutive 60-bit NTT primes, whose relative gap is close to 2^-60 * 2N
+		q := ring.GenerateNTTPrimes(60, 2<<params.LogN(), 2)
+		p := ring.GenerateNTTPrimes(55, 2<<params.LogN(), 1)
+
+		paramsGap, err := NewParametersFromLiteral(ParametersLiteral{LogN: params.LogN(), Q: q, P: p})
+		require.NoError(t, err)
+
+		diff := new(big.Float).Sub(new(big.Float).SetUint64(q[0]), new(big.Float).SetUint64(q[1]))
+		diff.Abs(diff)
+		want, _ := diff.Quo(diff, new(big.Float).SetUint64(utils.MaxUint64(q[0], q[1]))).Float64()
+		require.Greater(t, paramsGap.ModuliGap(), 0.0)
+		require.InDelta(t, want, paramsGap.ModuliGap(), want*1e-12)
+
+		_, err = NewParametersFromLiteral(ParametersLiteral{LogN: params.LogN(), Q: q, P: p, MinModuliGap: 2 * want})
+		require.Error(t, err)
+
+		_, err = NewParametersFromLiteral(ParametersLiteral{LogN: params.LogN(), Q: q, P: p, MinModuliGap: want / 2})
+		require.NoError(t, err)
+
+		// A single modulus has no gap
+		paramsGap, err = NewParametersFromLiteral(ParametersLiteral{LogN: params.LogN(), Q: q[:1], P: p, MinModuliGap: 0.5})
+		require.NoError(t, err)
+		require.True(t, math.IsInf(paramsGap.ModuliGap(), 1))
+
+		for _, minGap := range []float64{-1, 1, math.NaN()} {
+			_, err = NewParametersFromLiteral(ParametersLiteral{LogN: params.LogN(), Q: q, P: p, MinModuliGap: minGap})
+			require.Error(t, err)
+		}
+	})
+}
+
 func testGenKeyPair(kgen KeyGenerator, t *testing.T) {
 
 	params := kgen.(*keyGenerator).params
 
 	sk, pk := kgen.GenKeyPair()
 
+	t.Run(testString(params, "SK/Zeroize"), func(t *testing.T) {
+		skZero := kgen.GenSecretKey()
+		skCopy := skZero.CopyNew()
+		skZero.Zeroize()
+		require.True(t, skZero.Value.Equals(NewSecretKey(params).Value))
+		require.False(t, skCopy.Value.Equals(skZero.Value))
+		require.NotPanics(t, func() { (*SecretKey)(nil).Zeroize() })
+	})
+
+	t.Run(testString(params, "SK/AddSecretKeys"), func(t *testing.T) {
+		sk0, sk1 := kgen.GenSecretKey(), kgen.GenSecretKey()
+		skWant := NewSecretKey(params)
+		params.RingQP().AddLvl(params.QCount()-1, params.PCount()-1, sk0.Value, sk1.Value, skWant.Value)
+		require.True(t, skWant.Value.Equals(params.AddSecretKeys(sk0, sk1).Value))
+		require.Panics(t, func() { params.AddSecretKeys() })
+		require.Panics(t, func() { params.AddSecretKeys(sk0, nil) })
+		if params.QCount() > 1 {
+			require.Panics(t, func() { params.AddSecretKeys(sk0, &SecretKey{Value: params.RingQP().NewPolyLvl(0, params.PCount()-1)}) })
+		}
+	})
+
 	// Checks that the secret-key has exactly params.h non-zero coefficients
 	t.Run(testString(params, "SK"), func(t *testing.T) {
 
@@ -169,6 +234,70 @@ func testGenKeyPair(kgen KeyGenerator, t *testing.T) {
 
 	})
 
+	t.Run(testString(params, "SK/HammingWeight"), func(t *testing.T) {
+		ringQ := params.RingQ()
+		require.Equal(t, params.HammingWeight(), sk.HammingWeight(ringQ))
+		for _, h := range []int{1, 16, params.N() >> 1} {
+			skSparse := kgen.GenSecretKeyWithHammingWeight(h)
+			require.Equal(t, h, skSparse.HammingWeight(ringQ))
+
+			skCoeffs := skSparse.CopyNew()
+			ringQ.InvNTT(skCoeffs.Value.Q, skCoeffs.Value.Q)
+			skCoeffs.Value.Q.IsNTT = false
+			require.Equal(t, h, skCoeffs.HammingWeight(ringQ))
+			require.True(t, skSparse.Value.Q.IsNTT)
+
+			// a key in the NTT domain whose flags were cleared is counted in the wrong domain
+			skUnflagged := skSparse.CopyNew()
+			skUnflagged.Value.Q.IsNTT, skUnflagged.Value.Q.IsMForm = false, false
+			require.NotEqual(t, h, skUnflagged.HammingWeight(ringQ))
+			skUnflagged.Value.Q.IsNTT = true
+			require.Equal(t, h, skUnflagged.HammingWeight(ringQ))
+		}
+	})
+
+	t.Run(testString(params, "SK/SecretKeyFromMnemonic"), func(t *testing.T) {
+		ringQ := params.RingQ()
+
+		// BIP39 test vector
+		seed, err := mnemonicSeed("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+		require.NoError(t, err)
+		require.Equal(t, "5eb00bbddcf069084889a8ab9155568165f5c453ccb85e70811aaed6f6da5fc19a5ac40b389cd370d086206dec8aa6c43daea6690f20ad3d8d48b2d2ce9e38e4", hex.EncodeToString(seed))
+
+		mnemonic := "legal winner thank year wave sausage worth useful legal winner thank yellow"
+		sk0, err := SecretKeyFromMnemonic(params, mnemonic)
+		require.NoError(t, err)
+		require.Equal(t, params.HammingWeight(), sk0.HammingWeight(ringQ))
+
+		sk1, err := SecretKeyFromMnemonic(params, " legal winner thank year wave sausage\tworth useful legal winner thank yellow\n")
+		require.NoError(t, err)
+		require.True(t, sk0.Value.Q.Equals(sk1.Value.Q))
+		if params.PCount() != 0 {
+			require.True(t, sk0.Value.P.Equals(sk1.Value.P))
+		}
+
+		sk2, err := SecretKeyFromMnemonic(params, "letter advice cage absurd amount doctor acoustic avoid letter advice cage above")
+		require.NoError(t, err)
+		require.False(t, sk0.Value.Q.Equals(sk2.Value.Q))
+
+		_, err = SecretKeyFromMnemonic(params, " \t")
+		require.Error(t, err)
+
+		// the derivation is pinned for a fixed set of parameters
+		paramsPinned, err := NewParametersFromLiteral(ParametersLiteral{LogN: 10, Q: []uint64{0x3001}, P: []uint64{}, H: 32})
+		require.NoError(t, err)
+		skPinned, err := SecretKeyFromMnemonic(paramsPinned, mnemonic)
+		require.NoError(t, err)
+		coeffs := paramsPinned.RingQ().NewPoly()
+		paramsPinned.RingQ().InvMForm(skPinned.Value.Q, coeffs)
+		paramsPinned.RingQ().InvNTT(coeffs, coeffs)
+		digest := sha256.New()
+		for _, c := range coeffs.Coeffs[0] {
+			digest.Write([]byte{byte(c), byte(c >> 8)})
+		}
+		require.Equal(t, "8ca5633a103c5b44713a84e61d88a051e3445f0d43ba89bf4b1f9804502aa8cf", hex.EncodeToString(digest.Sum(nil)))
+	})
+
 	// Checks that sum([-as + e, a] + [as])) <= N * 6 * sigma
 	t.Run(testString(params, "PK"), func(t *testing.T) {
 
@@ -190,12 +319,197 @@ func testGenKeyPair(kgen KeyGenerator, t *testing.T) {
 
 	})
 
+	t.Run(testString(params, "PK/Fingerprint"), func(t *testing.T) {
+
+		_, pk0 := kgen.GenKeyPair()
+		_, pk1 := kgen.GenKeyPair()
+
+		fingerprint := pk0.Fingerprint(params)
+		require.Equal(t, fingerprint, pk0.Fingerprint(params))
+		require.NotEqual(t, fingerprint, pk1.Fingerprint(params))
+
+		// same key with its polynomials in the coefficient domain and in the Montgomery form
+		pkCoeffs := pk0.CopyNew()
+		for i := range pkCoeffs.Value {
+			params.RingQP().InvNTTLvl(params.QCount()-1, params.PCount()-1, pkCoeffs.Value[i], pkCoeffs.Value[i])
+			params.RingQP().MFormLvl(params.QCount()-1, params.PCount()-1, pkCoeffs.Value[i], pkCoeffs.Value[i])
+			for _, pol := range []*ring.Poly{pkCoeffs.Value[i].Q, pkCoeffs.Value[i].P} {
+				if pol != nil {
+					pol.IsNTT, pol.IsMForm = false, true
+				}
+			}
+		}
+
+		require.False(t, pk0.Equals(pkCoeffs))
+		pkCoeffsCopy := pkCoeffs.CopyNew()
+		require.Equal(t, fingerprint, pkCoeffs.Fingerprint(params))
+		require.True(t, pkCoeffs.Equals(pkCoeffsCopy), "Fingerprint must not modify the receiver")
+
+		// the fingerprint of the parameters is included
+		paramsOther, err := NewParametersFromLiteral(ParametersLiteral{LogN: params.LogN(), Q: params.Q(), P: params.P(), Sigma: params.Sigma() + 1})
+		require.NoError(t, err)
+		require.NotEqual(t, fingerprint, pk0.Fingerprint(paramsOther))
+
+		// round-trip through the serialization
+		data, err := pk0.MarshalBinary()
+		require.NoError(t, err)
+		pkUnmarshaled := new(PublicKey)
+		require.NoError(t, pkUnmarshaled.UnmarshalBinary(data))
+		require.Equal(t, fingerprint, pkUnmarshaled.Fingerprint(params))
+	})
+
+	t.Run(testString(params, "PK/Seeded"), func(t *testing.T) {
+
+		seed := []byte("seeded public key")
+		spk := kgen.GenPublicKeySeeded(sk, seed)
+
+		pkSeeded, err := spk.Decompress(params)
+		require.NoError(t, err)
+
+		// the mask is regenerated from the seed as GenPublicKey samples it from its PRNG
+		prng, _ := utils.NewKeyedPRNG(seed)
+		pkWant := NewPublicKey(params)
+		ring.NewUniformSampler(prng, params.RingQ()).Read(pkWant.Value[1].Q)
+		if params.PCount() > 0 {
+			ring.NewUniformSampler(prng, params.RingP()).Read(pkWant.Value[1].P)
+		}
+		pkWant.Value[0].Copy(spk.Value)
+		for i := range pkWant.Value {
+			pkWant.Value[i].Q.IsNTT = true
+			if pkWant.Value[i].P != nil {
+				pkWant.Value[i].P.IsNTT = true
+			}
+		}
+		require.True(t, pkWant.Equals(pkSeeded))
+
+		// the encryptor accepts the seeded form directly
+		plaintext := NewPlaintext(params, params.MaxLevel())
+		plaintext.Value.IsNTT = true
+		ciphertext := NewCiphertextNTT(params, 1, plaintext.Level())
+		NewEncryptor(params, spk).Encrypt(plaintext, ciphertext)
+		ringQ := params.RingQ()
+		ringQ.MulCoeffsMontgomeryAndAddLvl(ciphertext.Level(), ciphertext.Value[1], sk.Value.Q, ciphertext.Value[0])
+		ringQ.InvNTTLvl(ciphertext.Level(), ciphertext.Value[0], ciphertext.Value[0])
+		require.GreaterOrEqual(t, 9+params.LogN(), log2OfInnerSum(ciphertext.Level(), ringQ, ciphertext.Value[0]))
+
+		data, err := spk.MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, spk.MarshalSize(), len(data))
+		require.Equal(t, 1+len(seed)+NewPublicKey(params).MarshalSize()/2, len(data))
+
+		spkTest := new(SeededPublicKey)
+		require.NoError(t, spkTest.UnmarshalBinary(data))
+		pkTest, err := spkTest.Decompress(params)
+		require.NoError(t, err)
+		require.True(t, pkSeeded.Equals(pkTest))
+
+		// inputs too short to contain the seed and the header of the PolyQP
+		for _, buff := range [][]byte{{}, {0}, {0, 1}, data[:1+len(seed)+1]} {
+			require.Error(t, new(SeededPublicKey).UnmarshalBinary(buff))
+		}
+
+		// seeds longer than 64 bytes are not valid keys for the KeyedPRNG
+		longSeed := make([]byte, 65)
+		require.Panics(t, func() { kgen.GenPublicKeySeeded(sk, longSeed) })
+		_, err = (&SeededPublicKey{Value: spk.Value, Seed: longSeed}).Decompress(params)
+		require.Error(t, err)
+		_, err = NewEncryptorErr(params, &SeededPublicKey{Value: spk.Value, Seed: longSeed})
+		require.Error(t, err)
+	})
+
+	t.Run(testString(params, "PK/ToEvalForm"), func(t *testing.T) {
+
+		_, pk := kgen.GenKeyPair()
+
+		// Generated keys are already in evaluation form
+		pkEval := pk.CopyNew()
+		pkEval.ToEvalForm(params)
+		require.True(t, pk.Equals(pkEval))
+
+		// Same key in the coefficient domain and in Montgomery form
+		pkOther := pk.CopyNew()
+		for i := range pkOther.Value {
+			params.RingQP().InvNTTLvl(params.QCount()-1, params.PCount()-1, pkOther.Value[i], pkOther.Value[i])
+			params.RingQP().MFormLvl(params.QCount()-1, params.PCount()-1, pkOther.Value[i], pkOther.Value[i])
+			pkOther.Value[i].Q.IsNTT, pkOther.Value[i].Q.IsMForm = false, true
+			if pkOther.Value[i].P != nil {
+				pkOther.Value[i].P.IsNTT, pkOther.Value[i].P.IsMForm = false, true
+			}
+		}
+
+		_, err := NewEncryptorErr(params, pkOther)
+		require.Error(t, err)
+
+		pkOther.ToEvalForm(params)
+		require.True(t, pk.Equals(pkOther))
+
+		_, err = NewEncryptorErr(params, pkOther)
+		require.NoError(t, err)
+
+		// a key in the NTT domain but not flagged as such is rejected rather than transformed a second time
+		pkUnflagged := pk.CopyNew()
+		pkUnflagged.Value[0].Q.IsNTT = false
+		_, err = NewEncryptorErr(params, pkUnflagged)
+		require.Error(t, err)
+	})
+
+	t.Run(testString(params, "PK/GenPublicKeysFromCRPs"), func(t *testing.T) {
+
+		prng, _ := utils.NewPRNG()
+		ringQ := params.RingQ()
+
+		crps := make([]*ring.Poly, 3)
+		for i := range crps {
+			crpQP := params.RingQP().NewPoly()
+			ring.NewUniformSampler(prng, ringQ).Read(crpQP.Q)
+			coeffs := crpQP.Q.Coeffs
+			if params.PCount() > 0 {
+				ring.NewUniformSampler(prng, params.RingP()).Read(crpQP.P)
+				coeffs = append(coeffs, crpQP.P.Coeffs...)
+			}
+			crps[i] = &ring.Poly{Coeffs: coeffs}
+		}
+
+		pks := kgen.GenPublicKeysFromCRPs(sk, crps)
+		require.Len(t, pks, len(crps))
+
+		for i, pk := range pks {
+			require.True(t, pk.Value[1].Q.Equals(&ring.Poly{Coeffs: crps[i].Coeffs[:params.QCount()]}))
+			if params.PCount() > 0 {
+				require.True(t, pk.Value[1].P.Equals(&ring.Poly{Coeffs: crps[i].Coeffs[params.QCount():]}))
+			}
+
+			plaintext := NewPlaintext(params, params.MaxLevel())
+			plaintext.Value.IsNTT = true
+			ciphertext := NewEncryptor(params, pk).EncryptNew(plaintext)
+			ringQ.MulCoeffsMontgomeryAndAddLvl(ciphertext.Level(), ciphertext.Value[1], sk.Value.Q, ciphertext.Value[0])
+			ringQ.InvNTTLvl(ciphertext.Level(), ciphertext.Value[0], ciphertext.Value[0])
+			require.GreaterOrEqual(t, 9+params.LogN(), log2OfInnerSum(ciphertext.Level(), ringQ, ciphertext.Value[0]))
+		}
+
+		require.Panics(t, func() { kgen.GenPublicKeysFromCRPs(sk, []*ring.Poly{ringQ.NewPolyLvl(0)}) })
+	})
 }
 
 func testSwitchKeyGen(kgen KeyGenerator, t *testing.T) {
 
 	params := kgen.(*keyGenerator).params
 
+	t.Run(testString(params, "ProductOfP/"), func(t *testing.T) {
+
+		if params.PCount() == 0 {
+			t.Skip("#Pi is empty")
+		}
+
+		pBigInt := big.NewInt(1)
+		for i, pi := range params.P() {
+			pBigInt.Mul(pBigInt, new(big.Int).SetUint64(pi))
+			require.Equal(t, 0, pBigInt.Cmp(params.ProductOfP(i)))
+		}
+		require.Equal(t, 0, params.PBigInt().Cmp(params.ProductOfP(params.PCount()-1)))
+		require.Panics(t, func() { params.ProductOfP(params.PCount()) })
+	})
+
 	// Checks that switching keys are en encryption under the output key
 	// of the RNS decomposition of the input key by
 	// 1) Decrypting the RNS decomposed input key
@@ -249,6 +563,29 @@ func testSwitchKeyGen(kgen KeyGenerator, t *testing.T) {
 		require.GreaterOrEqual(t, log2Bound, log2OfInnerSum(len(ringP.Modulus)-1, ringP, swk.Value[0][0].P))
 
 	})
+
+	t.Run(testString(params, "GenRotationKeysForSteps/"), func(t *testing.T) {
+
+		if params.PCount() == 0 {
+			t.Skip("#Pi is empty")
+		}
+
+		sk := kgen.GenSecretKey()
+		steps := []int{1, -1, 5, -5, 1, 5}
+
+		rks := kgen.GenRotationKeysForSteps(sk, steps)
+		require.Len(t, rks.Keys, 4)
+
+		for _, k := range steps {
+			galEl := params.GaloisElementForColumnRotationBy(k)
+			galElInv := params.GaloisElementForColumnRotationBy(-k)
+			_, ok := rks.GetRotationKey(galEl)
+			require.True(t, ok)
+			_, ok = rks.GetRotationKey(galElInv)
+			require.True(t, ok)
+			require.Equal(t, uint64(1), (galEl*galElInv)%params.RingQ().NthRoot)
+		}
+	})
 }
 
 func testEncryptor(kgen KeyGenerator, t *testing.T) {
@@ -283,6 +620,116 @@ func testEncryptor(kgen KeyGenerator, t *testing.T) {
 		require.GreaterOrEqual(t, 9+params.LogN(), log2OfInnerSum(ciphertext.Level(), ringQ, ciphertext.Value[0]))
 	})
 
+	t.Run(testString(params, "Encrypt/KeepFullLevel/"), func(t *testing.T) {
+		plaintext := NewPlaintext(params, 0)
+		plaintext.Value.IsNTT = true
+		for _, key := range []interface{}{sk, pk} {
+			encryptor := NewEncryptorWithOptions(params, key, EncryptorOptions{KeepFullLevel: true})
+			ciphertext := NewCiphertextNTT(params, 1, params.MaxLevel())
+			encryptor.Encrypt(plaintext, ciphertext)
+			require.Equal(t, params.MaxLevel(), ciphertext.Level())
+			require.Equal(t, params.MaxLevel(), ciphertext.Value[1].Level())
+
+			// only the limbs up to the level of the plaintext are meaningful
+			ringQ.MulCoeffsMontgomeryAndAddLvl(plaintext.Level(), ciphertext.Value[1], sk.Value.Q, ciphertext.Value[0])
+			ringQ.InvNTTLvl(plaintext.Level(), ciphertext.Value[0], ciphertext.Value[0])
+			require.GreaterOrEqual(t, 9+params.LogN(), log2OfInnerSum(plaintext.Level(), ringQ, ciphertext.Value[0]))
+		}
+	})
+
+	t.Run(testString(params, "Encrypt/Pk/NTTThreads/"), func(t *testing.T) {
+		if params.PCount() == 0 {
+			t.Skip("#Pi is empty")
+		}
+		plaintext := NewPlaintext(params, params.MaxLevel())
+		plaintext.Value.IsNTT = true
+		for _, nbThreads := range []int{4, -1} {
+			encryptor := NewEncryptorWithOptions(params, pk, EncryptorOptions{NTTThreads: nbThreads})
+			ciphertext := NewCiphertextNTT(params, 1, plaintext.Level())
+			encryptor.Encrypt(plaintext, ciphertext)
+			require.Equal(t, plaintext.Level(), ciphertext.Level())
+			ringQ.MulCoeffsMontgomeryAndAddLvl(ciphertext.Level(), ciphertext.Value[1], sk.Value.Q, ciphertext.Value[0])
+			ringQ.InvNTTLvl(ciphertext.Level(), ciphertext.Value[0], ciphertext.Value[0])
+			require.GreaterOrEqual(t, 9+params.LogN(), log2OfInnerSum(ciphertext.Level(), ringQ, ciphertext.Value[0]))
+		}
+	})
+
+	t.Run(testString(params, "RingQP/NegLvl/"), func(t *testing.T) {
+		if params.PCount() == 0 {
+			t.Skip("#Pi is empty")
+		}
+		ringQP := params.RingQP()
+		levelQ, levelP := params.QCount()-1, params.PCount()-1
+
+		prng, _ := utils.NewPRNG()
+		sampler := NewUniformSamplerQP(params, prng)
+		p := ringQP.NewPoly()
+		sampler.Read(&p)
+
+		want, have := ringQP.NewPoly(), ringQP.NewPoly()
+		params.RingQ().NegLvl(levelQ, p.Q, want.Q)
+		params.RingP().NegLvl(levelP, p.P, want.P)
+
+		ringQP.NegLvl(levelQ, levelP, p, have)
+		require.True(t, want.Equals(have))
+
+		// in place and at a lower level
+		ringQP.NegLvl(0, 0, have, have)
+		for i := range have.Q.Coeffs {
+			require.Equal(t, i == 0, utils.EqualSliceUint64(p.Q.Coeffs[i], have.Q.Coeffs[i]))
+		}
+		for i := range have.P.Coeffs {
+			require.Equal(t, i == 0, utils.EqualSliceUint64(p.P.Coeffs[i], have.P.Coeffs[i]))
+		}
+	})
+
+	t.Run(testString(params, "RingQP/NTTLvlParallel/"), func(t *testing.T) {
+		if params.PCount() == 0 {
+			t.Skip("#Pi is empty")
+		}
+		ringQP := params.RingQP()
+		levelQ, levelP := params.QCount()-1, params.PCount()-1
+
+		prng, _ := utils.NewPRNG()
+		sampler := NewUniformSamplerQP(params, prng)
+		p := ringQP.NewPoly()
+		sampler.Read(&p)
+
+		want, have := ringQP.NewPoly(), ringQP.NewPoly()
+
+		ringQP.NTTLvl(levelQ, levelP, p, want)
+		ringQP.NTTLvlParallel(levelQ, levelP, p, have, 3)
+		require.True(t, want.Equals(have))
+
+		ringQP.InvNTTLvl(levelQ, levelP, want, want)
+		ringQP.InvNTTLvlParallel(levelQ, levelP, have, have, 3)
+		require.True(t, want.Equals(have))
+		require.True(t, p.Equals(have))
+	})
+
+	t.Run(testString(params, "Encrypt/Transposed/"), func(t *testing.T) {
+		plaintext := NewPlaintext(params, params.MaxLevel())
+		plaintext.Value.IsNTT = true
+		for _, key := range []interface{}{pk, sk} {
+			encryptor := NewEncryptor(params, key)
+			ctTransposed := NewTransposedCiphertext(params, 1, plaintext.Level())
+			ctTransposed.IsNTT = true
+			encryptor.EncryptTransposed(plaintext, ctTransposed)
+
+			ciphertext := NewCiphertext(params, 1, plaintext.Level())
+			ctTransposed.Untranspose(ciphertext)
+			require.True(t, ciphertext.Value[0].IsNTT)
+
+			ctTransposed2 := NewTransposedCiphertext(params, 1, plaintext.Level())
+			ctTransposed2.Transpose(ciphertext)
+			require.Equal(t, ctTransposed, ctTransposed2)
+
+			ringQ.MulCoeffsMontgomeryAndAddLvl(ciphertext.Level(), ciphertext.Value[1], sk.Value.Q, ciphertext.Value[0])
+			ringQ.InvNTTLvl(ciphertext.Level(), ciphertext.Value[0], ciphertext.Value[0])
+			require.GreaterOrEqual(t, 9+params.LogN(), log2OfInnerSum(ciphertext.Level(), ringQ, ciphertext.Value[0]))
+		}
+	})
+
 	t.Run(testString(params, "Encrypt/Sk/MaxLevel"), func(t *testing.T) {
 		plaintext := NewPlaintext(params, params.MaxLevel())
 		plaintext.Value.IsNTT = true
@@ -307,6 +754,430 @@ func testEncryptor(kgen KeyGenerator, t *testing.T) {
 		require.GreaterOrEqual(t, 5+params.LogN(), log2OfInnerSum(ciphertext.Level(), ringQ, ciphertext.Value[0]))
 	})
 
+	t.Run(testString(params, "EncryptNew/"), func(t *testing.T) {
+		for _, key := range []interface{}{sk, pk} {
+			for _, isNTT := range []bool{true, false} {
+				plaintext := NewPlaintext(params, params.MaxLevel())
+				plaintext.Value.IsNTT = isNTT
+				encryptor := NewEncryptor(params, key)
+				ciphertext := encryptor.EncryptNew(plaintext)
+				require.Equal(t, 1, ciphertext.Degree())
+				require.Equal(t, plaintext.Level(), ciphertext.Level())
+				require.Equal(t, isNTT, ciphertext.Value[0].IsNTT)
+				require.Equal(t, isNTT, ciphertext.Value[1].IsNTT)
+				if !isNTT {
+					ringQ.NTTLvl(ciphertext.Level(), ciphertext.Value[0], ciphertext.Value[0])
+					ringQ.NTTLvl(ciphertext.Level(), ciphertext.Value[1], ciphertext.Value[1])
+				}
+				ringQ.MulCoeffsMontgomeryAndAddLvl(ciphertext.Level(), ciphertext.Value[1], sk.Value.Q, ciphertext.Value[0])
+				ringQ.InvNTTLvl(ciphertext.Level(), ciphertext.Value[0], ciphertext.Value[0])
+				require.GreaterOrEqual(t, 9+params.LogN(), log2OfInnerSum(ciphertext.Level(), ringQ, ciphertext.Value[0]))
+			}
+		}
+	})
+
+	for _, dist := range []ErrorDistribution{DiscreteGaussian, RoundedContinuous, CenteredBinomial} {
+		t.Run(testString(params, "Encrypt/ErrorDistribution="+dist.String()+"/"), func(t *testing.T) {
+			paramsDist, err := NewParametersFromLiteral(ParametersLiteral{
+				LogN:              params.LogN(),
+				Q:                 params.Q(),
+				P:                 params.P(),
+				H:                 params.HammingWeight(),
+				Sigma:             params.Sigma(),
+				RingType:          params.RingType(),
+				ErrorDistribution: dist,
+			})
+			require.NoError(t, err)
+			require.Equal(t, dist, paramsDist.ErrorDistribution())
+
+			for _, key := range []interface{}{sk, pk} {
+				plaintext := NewPlaintext(paramsDist, paramsDist.MaxLevel())
+				plaintext.Value.IsNTT = true
+				encryptor := NewEncryptor(paramsDist, key)
+				ciphertext := NewCiphertextNTT(paramsDist, 1, plaintext.Level())
+				encryptor.Encrypt(plaintext, ciphertext)
+				ringQ.MulCoeffsMontgomeryAndAddLvl(ciphertext.Level(), ciphertext.Value[1], sk.Value.Q, ciphertext.Value[0])
+				ringQ.InvNTTLvl(ciphertext.Level(), ciphertext.Value[0], ciphertext.Value[0])
+				require.GreaterOrEqual(t, 9+params.LogN(), log2OfInnerSum(ciphertext.Level(), ringQ, ciphertext.Value[0]))
+			}
+		})
+
+		t.Run(testString(params, "Encrypt/GaussianTailFactor/ErrorDistribution="+dist.String()+"/"), func(t *testing.T) {
+			for _, factor := range []float64{1, 2.5, DefaultGaussianTailFactor} {
+				paramsTail, err := NewParametersFromLiteral(ParametersLiteral{
+					LogN:               params.LogN(),
+					Q:                  params.Q(),
+					P:                  params.P(),
+					H:                  params.HammingWeight(),
+					Sigma:              params.Sigma(),
+					RingType:           params.RingType(),
+					ErrorDistribution:  dist,
+					GaussianTailFactor: factor,
+				})
+				require.NoError(t, err)
+				require.Equal(t, factor, paramsTail.GaussianTailFactor())
+
+				encryptor := NewEncryptor(paramsTail, sk).(*skEncryptor)
+				q := ringQ.Modulus[0]
+				bound := uint64(factor * paramsTail.Sigma())
+				pol := ringQ.NewPolyLvl(0)
+				exceeds := false
+				for i := 0; i < 64; i++ {
+					encryptor.errorSampler.ReadLvl(0, pol)
+					for _, c := range pol.Coeffs[0] {
+						exceeds = exceeds || (c > bound && q-c > bound)
+					}
+				}
+				require.False(t, exceeds)
+			}
+		})
+
+		t.Run(testString(params, "Encrypt/MarshalState/ErrorDistribution="+dist.String()+"/"), func(t *testing.T) {
+			paramsDist, err := NewParametersFromLiteral(ParametersLiteral{
+				LogN:              params.LogN(),
+				Q:                 params.Q(),
+				P:                 params.P(),
+				H:                 params.HammingWeight(),
+				Sigma:             params.Sigma(),
+				RingType:          params.RingType(),
+				ErrorDistribution: dist,
+			})
+			require.NoError(t, err)
+
+			for _, key := range []interface{}{sk, pk} {
+				plaintext := NewPlaintext(paramsDist, paramsDist.MaxLevel())
+				plaintext.Value.IsNTT = true
+
+				enc := NewEncryptor(paramsDist, key)
+				enc.EncryptNew(plaintext)
+
+				state, err := enc.MarshalState()
+				require.NoError(t, err)
+
+				ct0 := enc.EncryptNew(plaintext)
+				ct1 := enc.EncryptNew(plaintext)
+
+				encRestored := NewEncryptor(paramsDist, key)
+				require.NoError(t, encRestored.UnmarshalState(state))
+				for _, ct := range []*Ciphertext{ct0, ct1} {
+					ctRestored := encRestored.EncryptNew(plaintext)
+					for i := range ct.Value {
+						require.True(t, ct.Value[i].Equals(ctRestored.Value[i]))
+					}
+				}
+
+				// invalid states leave the Encryptor unchanged
+				stateBefore, err := encRestored.MarshalState()
+				require.NoError(t, err)
+
+				invalidSampler := append([]byte{}, state...)
+				invalidSampler[8+binary.BigEndian.Uint64(state[:8])] = 0xFF // position in the sampler buffer
+
+				for _, invalid := range [][]byte{state[:len(state)-1], invalidSampler} {
+					require.Error(t, encRestored.UnmarshalState(invalid))
+					stateAfter, err := encRestored.MarshalState()
+					require.NoError(t, err)
+					require.Equal(t, stateBefore, stateAfter)
+				}
+			}
+		})
+	}
+
+	t.Run(testString(params, "Encrypt/EnsureBuffers/"), func(t *testing.T) {
+		enc := NewEncryptor(params, pk).(*pkEncryptor)
+
+		require.Zero(t, testing.AllocsPerRun(10, func() { enc.ensureBuffers(1) }))
+
+		if params.PCount() == 0 {
+			require.Len(t, enc.poolP, 0)
+			return
+		}
+
+		require.Len(t, enc.poolP, 3)
+		enc.ensureBuffers(3)
+		require.Len(t, enc.poolQ, 1)
+		require.Len(t, enc.poolP, 5)
+		for _, pol := range enc.poolP {
+			require.Equal(t, params.PCount(), pol.LenModuli())
+		}
+	})
+
+	t.Run(testString(params, "Encrypt/Sk/DualErrorInjection/"), func(t *testing.T) {
+		encryptor := NewEncryptorWithOptions(params, sk, EncryptorOptions{DualErrorInjection: true})
+		for _, isNTT := range []bool{true, false} {
+			plaintext := NewPlaintext(params, params.MaxLevel())
+			plaintext.Value.IsNTT = isNTT
+			ciphertext := encryptor.EncryptNew(plaintext)
+			require.Equal(t, isNTT, ciphertext.Value[1].IsNTT)
+			if !isNTT {
+				ringQ.NTTLvl(ciphertext.Level(), ciphertext.Value[0], ciphertext.Value[0])
+				ringQ.NTTLvl(ciphertext.Level(), ciphertext.Value[1], ciphertext.Value[1])
+			}
+			ringQ.MulCoeffsMontgomeryAndAddLvl(ciphertext.Level(), ciphertext.Value[1], sk.Value.Q, ciphertext.Value[0])
+			ringQ.InvNTTLvl(ciphertext.Level(), ciphertext.Value[0], ciphertext.Value[0])
+			// the noise e0 + e1*s is larger than a fresh noise e0 but remains small
+			logNoise := log2OfInnerSum(ciphertext.Level(), ringQ, ciphertext.Value[0])
+			require.Greater(t, logNoise, 4+params.LogN())
+			require.LessOrEqual(t, logNoise, 10+params.LogN()+params.LogN()/2)
+		}
+	})
+
+	t.Run(testString(params, "Encrypt/EncryptWithWitness/"), func(t *testing.T) {
+		ringQ := params.RingQ()
+		level := params.MaxLevel()
+
+		prng, _ := utils.NewPRNG()
+		plaintext := NewPlaintext(params, level)
+		ring.NewUniformSampler(prng, ringQ).Read(plaintext.Value)
+
+		// secret-key: ct = (-a*s + m + e0, a + e1)
+		for _, dual := range []bool{false, true} {
+			ciphertext := NewCiphertext(params, 1, level)
+			w := NewEncryptorWithOptions(params, sk, EncryptorOptions{DualErrorInjection: dual}).EncryptWithWitness(plaintext, ciphertext)
+			require.Nil(t, w.U)
+			require.Equal(t, dual, w.E1 != nil)
+
+			want := ciphertext.Value[1].CopyNew()
+			if dual {
+				ringQ.SubLvl(level, want, w.E1, want)
+			}
+			ringQ.NTTLvl(level, want, want)
+			ringQ.MulCoeffsMontgomeryLvl(level, want, sk.Value.Q, want)
+			ringQ.NegLvl(level, want, want)
+			ringQ.InvNTTLvl(level, want, want)
+			ringQ.AddLvl(level, want, plaintext.Value, want)
+			ringQ.AddLvl(level, want, w.E0, want)
+			require.True(t, ringQ.EqualLvl(level, want, ciphertext.Value[0]))
+		}
+
+		// public-key: ct = ((u*pk0 + e0)/P + m, (u*pk1 + e1)/P)
+		ciphertext := NewCiphertext(params, 1, level)
+		w := NewEncryptor(params, pk).EncryptWithWitness(plaintext, ciphertext)
+		require.Equal(t, level, w.U.Level())
+
+		for i := range w.U.Coeffs {
+			for _, c := range w.U.Coeffs[i] {
+				require.True(t, c == 0 || c == 1 || c == ringQ.Modulus[i]-1)
+			}
+		}
+
+		for i, e := range []*ring.Poly{w.E0, w.E1} {
+			want := ringQ.NewPoly()
+			if params.PCount() != 0 {
+				ringQP := params.RingQP()
+				u, eQP, ct := ringQP.NewPoly(), ringQP.NewPoly(), ringQP.NewPoly()
+				ring.CopyValues(w.U, u.Q)
+				ring.CopyValues(e, eQP.Q)
+				ringQP.ExtendBasisSmallNormAndCenter(u.Q, 0, nil, u.P)
+				ringQP.ExtendBasisSmallNormAndCenter(eQP.Q, 0, nil, eQP.P)
+				ringQP.NTTLvl(level, 0, u, u)
+				ringQP.MFormLvl(level, 0, u, u)
+				ringQP.MulCoeffsMontgomeryLvl(level, 0, u, pk.Value[i], ct)
+				ringQP.InvNTTLvl(level, 0, ct, ct)
+				ringQP.AddLvl(level, 0, ct, eQP, ct)
+				ring.NewBasisExtender(ringQ, params.RingP()).ModDownQPtoQ(level, 0, ct.Q, ct.P, want)
+			} else {
+				u := w.U.CopyNew()
+				ringQ.NTTLvl(level, u, u)
+				ringQ.MFormLvl(level, u, u)
+				ringQ.MulCoeffsMontgomeryLvl(level, u, pk.Value[i].Q, want)
+				ringQ.InvNTTLvl(level, want, want)
+				ringQ.AddLvl(level, want, e, want)
+			}
+			if i == 0 {
+				ringQ.AddLvl(level, want, plaintext.Value, want)
+			}
+			require.True(t, ringQ.EqualLvl(level, want, ciphertext.Value[i]))
+		}
+
+		w.Zero()
+		require.Nil(t, w.U)
+		require.Nil(t, w.E0)
+		require.Nil(t, w.E1)
+	})
+
+	t.Run(testString(params, "Encrypt/ProfilingEncryptor/"), func(t *testing.T) {
+		plaintext := NewPlaintext(params, params.MaxLevel())
+
+		encryptor, ok := NewEncryptor(params, pk).(ProfilingEncryptor)
+		require.Equal(t, encryptProfileEnabled, ok)
+		if !ok {
+			t.Skip("requires the lattigo_profile build tag")
+		}
+		require.Equal(t, ProfileResult{}, encryptor.LastProfile())
+
+		encryptor.EncryptNew(plaintext)
+		profile := encryptor.LastProfile()
+		require.GreaterOrEqual(t, int64(profile.Sampling), int64(0))
+		require.GreaterOrEqual(t, int64(profile.NTT), int64(0))
+		require.GreaterOrEqual(t, int64(profile.Multiply), int64(0))
+		require.GreaterOrEqual(t, int64(profile.ModDown), int64(0))
+		require.Equal(t, profile.Sampling+profile.NTT+profile.Multiply+profile.ModDown, profile.Total())
+
+		// shallow copies record their own timings
+		require.Equal(t, ProfileResult{}, encryptor.ShallowCopy().(ProfilingEncryptor).LastProfile())
+	})
+
+	t.Run(testString(params, "Encrypt/EncryptIndexed/"), func(t *testing.T) {
+		key := []byte("EncryptIndexed test key")
+		for _, k := range []interface{}{sk, pk} {
+			encryptor := NewEncryptorWithOptions(params, k, EncryptorOptions{PRNGKey: key})
+			other := NewEncryptorWithOptions(params, k, EncryptorOptions{PRNGKey: key})
+
+			plaintext := NewPlaintext(params, params.MaxLevel())
+			plaintext.Value.IsNTT = true
+
+			encryptIndexed := func(enc Encryptor, index uint64) *Ciphertext {
+				ct := NewCiphertextNTT(params, 1, plaintext.Level())
+				enc.EncryptIndexed(index, plaintext, ct)
+				return ct
+			}
+
+			ct0 := encryptIndexed(encryptor, 0)
+
+			// the randomness of an index does not depend on the state of the encryptor
+			encryptor.EncryptNew(plaintext)
+			ct0Again := encryptIndexed(encryptor, 0)
+			require.True(t, ct0.Value[0].Equals(ct0Again.Value[0]))
+			require.True(t, ct0.Value[1].Equals(ct0Again.Value[1]))
+
+			for _, enc := range []Encryptor{other, encryptor.ShallowCopy()} {
+				ct := encryptIndexed(enc, 0)
+				require.True(t, ct0.Value[0].Equals(ct.Value[0]))
+				require.True(t, ct0.Value[1].Equals(ct.Value[1]))
+			}
+
+			ct1 := encryptIndexed(encryptor, 1)
+			require.False(t, ct0.Value[1].Equals(ct1.Value[1]))
+
+			ctOtherKey := NewCiphertextNTT(params, 1, plaintext.Level())
+			NewEncryptorWithOptions(params, k, EncryptorOptions{PRNGKey: []byte("another key")}).EncryptIndexed(0, plaintext, ctOtherKey)
+			require.False(t, ct0.Value[1].Equals(ctOtherKey.Value[1]))
+
+			for _, ct := range []*Ciphertext{ct0, ct1} {
+				ringQ.MulCoeffsMontgomeryAndAddLvl(ct.Level(), ct.Value[1], sk.Value.Q, ct.Value[0])
+				ringQ.InvNTTLvl(ct.Level(), ct.Value[0], ct.Value[0])
+				require.GreaterOrEqual(t, 9+params.LogN(), log2OfInnerSum(ct.Level(), ringQ, ct.Value[0]))
+			}
+		}
+	})
+
+	t.Run(testString(params, "Encrypt/ShareCiphertext/"), func(t *testing.T) {
+		prng, _ := utils.NewPRNG()
+		sampler := ring.NewUniformSampler(prng, ringQ)
+		decryptor := NewDecryptor(params, sk)
+		for _, key := range []interface{}{sk, pk} {
+			encryptor := NewEncryptor(params, key)
+			for _, isNTT := range []bool{true, false} {
+				plaintext := NewPlaintext(params, params.MaxLevel())
+				sampler.Read(plaintext.Value)
+				plaintext.Value.IsNTT = isNTT
+				ciphertext := encryptor.EncryptNew(plaintext)
+
+				share0, share1 := encryptor.ShareCiphertext(ciphertext)
+				require.Equal(t, ciphertext.Level(), share0.Level())
+				require.Equal(t, isNTT, share0.Value[0].IsNTT)
+				require.Equal(t, isNTT, share1.Value[1].IsNTT)
+				require.False(t, share0.Value[0].Equals(ciphertext.Value[0]))
+
+				sum := share0.CopyNew()
+				for i := range sum.Value {
+					ringQ.AddLvl(sum.Level(), sum.Value[i], share1.Value[i], sum.Value[i])
+				}
+				require.True(t, sum.Value[0].Equals(ciphertext.Value[0]))
+				require.True(t, sum.Value[1].Equals(ciphertext.Value[1]))
+
+				have := NewPlaintext(params, sum.Level())
+				have.Value.IsNTT = isNTT
+				decryptor.Decrypt(sum, have)
+				ringQ.SubLvl(have.Level(), have.Value, plaintext.Value, have.Value)
+				if isNTT {
+					ringQ.InvNTTLvl(have.Level(), have.Value, have.Value)
+				}
+				require.GreaterOrEqual(t, 9+params.LogN(), log2OfInnerSum(have.Level(), ringQ, have.Value))
+			}
+		}
+	})
+
+	t.Run(testString(params, "Encrypt/EntropyConsumed/"), func(t *testing.T) {
+		for _, key := range []interface{}{sk, pk} {
+			enc := NewEncryptor(params, key)
+			require.Zero(t, enc.EntropyConsumed())
+
+			plaintext := NewPlaintext(params, params.MaxLevel())
+			plaintext.Value.IsNTT = true
+			enc.EncryptNew(plaintext)
+
+			// the uniform mask uses at least 8 bytes per coefficient and the error sampler at least 1024 bytes
+			consumed := enc.EntropyConsumed()
+			require.GreaterOrEqual(t, consumed, uint64(8*params.N()*(params.MaxLevel()+1)+1024))
+
+			enc.EncryptNew(plaintext)
+			require.Greater(t, enc.EntropyConsumed(), consumed)
+
+			state, err := enc.MarshalState()
+			require.NoError(t, err)
+			encRestored := NewEncryptor(params, key)
+			require.NoError(t, encRestored.UnmarshalState(state))
+			require.Equal(t, enc.EntropyConsumed(), encRestored.EntropyConsumed())
+
+			require.Zero(t, enc.ShallowCopy().EntropyConsumed())
+		}
+	})
+
+	t.Run(testString(params, "NewEncryptorErr/"), func(t *testing.T) {
+		for _, key := range []interface{}{sk, pk} {
+			enc, err := NewEncryptorErr(params, key)
+			require.NoError(t, err)
+			require.NotNil(t, enc)
+		}
+
+		paramsOther, err := NewParametersFromLiteral(ParametersLiteral{LogN: params.LogN() - 1, Q: params.Q(), P: params.P()})
+		require.NoError(t, err)
+		skOther, pkOther := NewKeyGenerator(paramsOther).GenKeyPair()
+
+		for _, key := range []interface{}{nil, 1, (*SecretKey)(nil), (*PublicKey)(nil), skOther, pkOther} {
+			enc, err := NewEncryptorErr(params, key)
+			require.Error(t, err)
+			require.Nil(t, enc)
+		}
+
+		require.Panics(t, func() { NewEncryptor(params, skOther) })
+	})
+
+	t.Run(testString(params, "Encrypt/BasisExtenderPool/"), func(t *testing.T) {
+
+		if params.PCount() == 0 {
+			t.Skip("#P = 0")
+		}
+
+		pool := ring.NewBasisExtenderPool(params.RingQ(), params.RingP(), 2)
+		enc := NewEncryptorWithOptions(params, pk, EncryptorOptions{BasisExtenderPool: pool})
+		require.Nil(t, enc.(*pkEncryptor).basisextender)
+
+		// more workers than extenders in the pool
+		nbWorkers := 4
+		ciphertexts := make([]*Ciphertext, nbWorkers)
+		var wg sync.WaitGroup
+		for i := range ciphertexts {
+			ciphertexts[i] = NewCiphertextNTT(params, 1, params.MaxLevel())
+			wg.Add(1)
+			go func(enc Encryptor, ct *Ciphertext) {
+				defer wg.Done()
+				plaintext := NewPlaintext(params, params.MaxLevel())
+				plaintext.Value.IsNTT = true
+				enc.Encrypt(plaintext, ct)
+			}(enc.ShallowCopy(), ciphertexts[i])
+		}
+		wg.Wait()
+
+		for _, ciphertext := range ciphertexts {
+			ringQ.MulCoeffsMontgomeryAndAddLvl(ciphertext.Level(), ciphertext.Value[1], sk.Value.Q, ciphertext.Value[0])
+			ringQ.InvNTTLvl(ciphertext.Level(), ciphertext.Value[0], ciphertext.Value[0])
+			require.GreaterOrEqual(t, 9+params.LogN(), log2OfInnerSum(ciphertext.Level(), ringQ, ciphertext.Value[0]))
+		}
+	})
+
 	t.Run(testString(params, "ShallowCopy/Sk"), func(t *testing.T) {
 		enc1 := NewEncryptor(params, sk)
 		enc2 := enc1.ShallowCopy()
@@ -358,14 +1229,14 @@ func testDecryptor(kgen KeyGenerator, t *testing.T) {
 	sk := kgen.GenSecretKey()
 	ringQ := params.RingQ()
 	encryptor := NewEncryptor(params, sk)
-	decryptor := NewDecryptor(params, sk)
+	dec := NewDecryptor(params, sk)
 
 	t.Run(testString(params, "Decrypt/MaxLevel"), func(t *testing.T) {
 		plaintext := NewPlaintext(params, params.MaxLevel())
 		plaintext.Value.IsNTT = true
 		ciphertext := NewCiphertextNTT(params, 1, plaintext.Level())
 		encryptor.Encrypt(plaintext, ciphertext)
-		decryptor.Decrypt(ciphertext, plaintext)
+		dec.Decrypt(ciphertext, plaintext)
 		require.Equal(t, plaintext.Level(), ciphertext.Level())
 		ringQ.InvNTTLvl(plaintext.Level(), plaintext.Value, plaintext.Value)
 		require.GreaterOrEqual(t, 5+params.LogN(), log2OfInnerSum(ciphertext.Level(), ringQ, plaintext.Value))
@@ -376,11 +1247,28 @@ func testDecryptor(kgen KeyGenerator, t *testing.T) {
 		plaintext.Value.IsNTT = true
 		ciphertext := NewCiphertextNTT(params, 1, plaintext.Level())
 		encryptor.Encrypt(plaintext, ciphertext)
-		decryptor.Decrypt(ciphertext, plaintext)
+		dec.Decrypt(ciphertext, plaintext)
 		require.Equal(t, plaintext.Level(), ciphertext.Level())
 		ringQ.InvNTTLvl(plaintext.Level(), plaintext.Value, plaintext.Value)
 		require.GreaterOrEqual(t, 5+params.LogN(), log2OfInnerSum(ciphertext.Level(), ringQ, plaintext.Value))
 	})
+
+	t.Run(testString(params, "Decrypt/ShallowCopy"), func(t *testing.T) {
+		dec1 := NewDecryptor(params, sk).(*decryptor)
+		dec2 := dec1.ShallowCopy().(*decryptor)
+		require.True(t, dec1.ringQ == dec2.ringQ)
+		require.True(t, dec1.sk == dec2.sk)
+		require.False(t, dec1.pool == dec2.pool)
+	})
+
+	t.Run(testString(params, "Decrypt/WithKey"), func(t *testing.T) {
+		sk2 := kgen.GenSecretKey()
+		dec1 := NewDecryptor(params, sk).(*decryptor)
+		dec2 := dec1.WithKey(sk2).(*decryptor)
+		require.True(t, dec1.ringQ == dec2.ringQ)
+		require.True(t, dec2.sk == sk2)
+		require.False(t, dec1.pool == dec2.pool)
+	})
 }
 
 func testKeySwitcher(kgen KeyGenerator, t *testing.T) {
@@ -587,6 +1475,391 @@ func testKeySwitchDimension(kgen KeyGenerator, t *testing.T) {
 	})
 }
 
+func testCiphertext(kgen KeyGenerator, t *testing.T) {
+
+	params := kgen.(*keyGenerator).params
+
+	t.Run(testString(params, "Ciphertext/SetValue&GetValue"), func(t *testing.T) {
+		ringQ := params.RingQ()
+		ciphertext := NewCiphertextNTT(params, 1, params.MaxLevel())
+
+		value := []*ring.Poly{ringQ.NewPoly(), ringQ.NewPoly(), ringQ.NewPoly()}
+		ciphertext.SetValue(value)
+		require.Equal(t, 2, ciphertext.Degree())
+		require.Equal(t, value, ciphertext.GetValue())
+
+		require.Panics(t, func() { ciphertext.SetValue([]*ring.Poly{}) })
+		require.Panics(t, func() { ciphertext.SetValue([]*ring.Poly{ringQ.NewPoly(), nil}) })
+		require.Panics(t, func() { ciphertext.SetValue([]*ring.Poly{ringQ.NewPoly(), ringQ.NewPolyLvl(0)}) })
+
+		polyNTT := ringQ.NewPoly()
+		polyNTT.IsNTT = true
+		require.Panics(t, func() { ciphertext.SetValue([]*ring.Poly{ringQ.NewPoly(), polyNTT}) })
+
+		require.Equal(t, value, ciphertext.GetValue())
+	})
+
+	t.Run(testString(params, "Ciphertext/B&A"), func(t *testing.T) {
+		ciphertext := NewCiphertextNTT(params, 1, params.MaxLevel())
+		require.Equal(t, 1, ciphertext.Degree())
+		require.True(t, ciphertext.B() == ciphertext.Value[0])
+		require.True(t, ciphertext.A() == ciphertext.Value[1])
+		require.Panics(t, func() { NewCiphertextNTT(params, 0, params.MaxLevel()).A() })
+	})
+
+	t.Run(testString(params, "Ciphertext/CanonicalizeNTT"), func(t *testing.T) {
+		ringQ := params.RingQ()
+		prng, _ := utils.NewPRNG()
+		ciphertext := NewCiphertextRandom(prng, params, 1, params.MaxLevel())
+		want := ciphertext.CopyNew()
+
+		// makes the NTT flags diverge
+		ringQ.NTT(ciphertext.Value[1], ciphertext.Value[1])
+		ciphertext.Value[1].IsNTT = true
+
+		ciphertext.CanonicalizeNTT(ringQ, false)
+		for i := range ciphertext.Value {
+			require.False(t, ciphertext.Value[i].IsNTT)
+			require.True(t, ringQ.Equal(want.Value[i], ciphertext.Value[i]))
+		}
+
+		ciphertext.CanonicalizeNTT(ringQ, true)
+		for i := range ciphertext.Value {
+			require.True(t, ciphertext.Value[i].IsNTT)
+			ringQ.NTT(want.Value[i], want.Value[i])
+			require.True(t, ringQ.Equal(want.Value[i], ciphertext.Value[i]))
+		}
+	})
+
+	t.Run(testString(params, "Ciphertext/Digest"), func(t *testing.T) {
+		ringQ := params.RingQ()
+		prng, _ := utils.NewPRNG()
+		ciphertext := NewCiphertextRandom(prng, params, 1, params.MaxLevel())
+		digest := ciphertext.Digest(ringQ)
+
+		// the digest does not depend on the domain of the polynomials
+		other := ciphertext.CopyNew()
+		ringQ.NTT(other.Value[1], other.Value[1])
+		other.Value[1].IsNTT = true
+		want := other.CopyNew()
+		require.Equal(t, digest, other.Digest(ringQ))
+		for i := range other.Value {
+			require.True(t, ringQ.Equal(want.Value[i], other.Value[i]))
+		}
+
+		other.CanonicalizeNTT(ringQ, true)
+		require.Equal(t, digest, other.Digest(ringQ))
+
+		// but on the coefficients, the level and the degree
+		other = ciphertext.CopyNew()
+		other.Value[0].Coeffs[0][0]++
+		require.NotEqual(t, digest, other.Digest(ringQ))
+
+		if params.MaxLevel() > 0 {
+			other = ciphertext.CopyNew()
+			for i := range other.Value {
+				other.Value[i].Coeffs = other.Value[i].Coeffs[:params.MaxLevel()]
+			}
+			require.NotEqual(t, digest, other.Digest(ringQ))
+		}
+
+		other = ciphertext.CopyNew()
+		other.Value = append(other.Value, ringQ.NewPoly())
+		require.NotEqual(t, digest, other.Digest(ringQ))
+	})
+
+	t.Run(testString(params, "Ciphertext/AggregateCiphertexts"), func(t *testing.T) {
+		ringQ := params.RingQ()
+		prng, _ := utils.NewPRNG()
+
+		// more than the 16 ciphertexts that can be summed without reduction for 60-bit moduli
+		cts := make([]*Ciphertext, 20)
+		for i := range cts {
+			cts[i] = NewCiphertextRandom(prng, params, 1, params.MaxLevel())
+		}
+		cts[3] = NewCiphertextRandom(prng, params, 2, params.MaxLevel())
+		cts[5] = NewCiphertextRandom(prng, params, 1, 0)
+
+		want := NewCiphertext(params, 2, 0)
+		for _, ct := range cts {
+			for j := range ct.Value {
+				ringQ.AddLvl(0, want.Value[j], ct.Value[j], want.Value[j])
+			}
+		}
+
+		out := NewCiphertext(params, 2, params.MaxLevel())
+		require.NoError(t, AggregateCiphertexts(params, cts, out))
+		require.Equal(t, 0, out.Level())
+		for j := range want.Value {
+			require.True(t, ringQ.EqualLvl(0, want.Value[j], out.Value[j]))
+		}
+
+		// out as one of the summands
+		acc := cts[3].CopyNew()
+		ctsAcc := append([]*Ciphertext{}, cts...)
+		ctsAcc[3] = acc
+		require.NoError(t, AggregateCiphertexts(params, ctsAcc, acc))
+		for j := range want.Value {
+			require.True(t, ringQ.EqualLvl(0, want.Value[j], acc.Value[j]))
+		}
+
+		// invalid inputs
+		ctNTT := NewCiphertextNTT(params, 1, params.MaxLevel())
+		require.Error(t, AggregateCiphertexts(params, nil, out))
+		require.Error(t, AggregateCiphertexts(params, []*Ciphertext{cts[0], ctNTT}, out))
+		require.Error(t, AggregateCiphertexts(params, []*Ciphertext{cts[0], cts[1]}, out))
+		require.Error(t, AggregateCiphertexts(params, []*Ciphertext{acc, cts[0], acc}, acc))
+	})
+
+	t.Run(testString(params, "Plaintext/CoeffEncoder"), func(t *testing.T) {
+		ringQ := params.RingQ()
+		encoder := NewCoeffEncoder(params)
+		N := params.N()
+
+		scale := float64(1 << 10)
+		values0, values1 := make([]float64, N), make([]float64, N)
+		for i := range values0 {
+			values0[i] = 2*utils.RandFloat64(0, 1) - 1
+			values1[i] = 2*utils.RandFloat64(0, 1) - 1
+		}
+
+		pt0, pt1 := NewPlaintext(params, params.MaxLevel()), NewPlaintext(params, params.MaxLevel())
+		pt1.Value.IsNTT = true
+		encoder.Encode(values0, scale, pt0)
+		encoder.Encode(values1, scale, pt1)
+
+		for i, v := range encoder.Decode(pt0, scale) {
+			require.InDelta(t, values0[i], v, 0.5/scale)
+		}
+		for i, v := range encoder.Decode(pt1, scale) {
+			require.InDelta(t, values1[i], v, 0.5/scale)
+		}
+
+		// the product of two plaintexts encodes the negacyclic convolution of their values
+		rounded0, rounded1 := encoder.Decode(pt0, 1), encoder.Decode(pt1, 1)
+		ringQ.NTTLvl(pt0.Level(), pt0.Value, pt0.Value)
+		pt0.Value.IsNTT = true
+		ringQ.MFormLvl(pt0.Level(), pt0.Value, pt0.Value)
+		ringQ.MulCoeffsMontgomeryLvl(pt0.Level(), pt0.Value, pt1.Value, pt1.Value)
+		have := encoder.Decode(pt1, 1)
+		for _, k := range []int{0, 1, N - 1} {
+			var want float64
+			for i := 0; i < N; i++ {
+				if j := k - i; j >= 0 {
+					want += rounded0[i] * rounded1[j]
+				} else {
+					want -= rounded0[i] * rounded1[j+N]
+				}
+			}
+			require.Equal(t, want, have[k])
+		}
+
+		// scaled values that would wrap around modulo Q panic
+		require.Panics(t, func() { encoder.Encode([]float64{math.Inf(1)}, scale, pt0) })
+		require.Panics(t, func() { encoder.Encode([]float64{math.Ldexp(1, ringQ.ModulusBigint.BitLen())}, scale, pt0) })
+		require.Panics(t, func() { encoder.Encode(make([]float64, N+1), scale, pt0) })
+	})
+
+	t.Run(testString(params, "Ciphertext/NoiseBudgetTracker"), func(t *testing.T) {
+		T := uint64(65537)
+		nt := NewNoiseBudgetTracker(params, T, params.MaxLevel())
+		fresh := nt.LogNoise()
+
+		// additions of plaintexts and multiplications by +/-1 leave the noise unchanged
+		nt.Add(nil)
+		nt.MulScalar(T - 1)
+		require.Equal(t, fresh, nt.LogNoise())
+
+		nt.MulScalar(4)
+		require.InDelta(t, fresh+2, nt.LogNoise(), 1e-9)
+
+		other := NewNoiseBudgetTracker(params, T, 0)
+		nt.Add(other)
+		require.Equal(t, 0, nt.Level())
+		require.Equal(t, params.MaxLevel(), NewNoiseBudgetTracker(params, T, params.MaxLevel()).Level())
+
+		if params.MaxLevel() > 0 {
+			nt = NewNoiseBudgetTracker(params, T, params.MaxLevel())
+			budget, noise := nt.PredictedBits(), nt.LogNoise()
+			nt.Mul(nt.CopyNew())
+
+			// the multiplication increases the noise and consumes the budget by the same amount
+			require.Greater(t, nt.LogNoise(), noise)
+			require.InDelta(t, budget-(nt.LogNoise()-noise), nt.PredictedBits(), 1e-9)
+		}
+
+		require.Panics(t, func() { NewNoiseBudgetTracker(params, 1, 0) })
+	})
+
+	t.Run(testString(params, "Plaintext/NewPlaintextFromPoly"), func(t *testing.T) {
+		ringQ := params.RingQ()
+
+		for _, isNTT := range []bool{true, false} {
+			pol := ringQ.NewPolyLvl(0)
+			pt := params.NewPlaintextFromPoly(pol, isNTT)
+			require.True(t, pt.Value == pol)
+			require.Equal(t, 0, pt.Level())
+			require.Equal(t, isNTT, pt.Value.IsNTT)
+		}
+
+		require.Panics(t, func() { params.NewPlaintextFromPoly(nil, false) })
+		require.Panics(t, func() { params.NewPlaintextFromPoly(ring.NewPoly(params.N(), params.QCount()+1), false) })
+		require.Panics(t, func() { params.NewPlaintextFromPoly(ring.NewPoly(params.N()>>1, 1), false) })
+	})
+
+	t.Run(testString(params, "Plaintext/TruncateLevel"), func(t *testing.T) {
+		ringQ := params.RingQ()
+		prng, _ := utils.NewPRNG()
+		sampler := ring.NewUniformSampler(prng, ringQ)
+
+		pt := NewPlaintext(params, params.MaxLevel())
+		sampler.Read(pt.Value)
+		want := pt.Value.CopyNew()
+
+		level := params.MaxLevel() / 2
+		pt.TruncateLevel(level)
+		require.Equal(t, level, pt.Level())
+		require.Equal(t, level+1, cap(pt.Value.Coeffs))
+		require.True(t, ringQ.EqualLvl(level, want, pt.Value))
+
+		sk := kgen.GenSecretKey()
+		ciphertext := NewCiphertext(params, 1, params.MaxLevel())
+		NewEncryptor(params, sk).Encrypt(pt, ciphertext)
+		require.Equal(t, level, ciphertext.Level())
+
+		require.Panics(t, func() { pt.TruncateLevel(-1) })
+		require.Panics(t, func() { pt.TruncateLevel(level + 1) })
+	})
+}
+
+func testPackShiftedLWE(kgen KeyGenerator, t *testing.T) {
+
+	params := kgen.(*keyGenerator).params
+
+	t.Run(testString(params, "PackShiftedLWE"), func(t *testing.T) {
+
+		if params.RingType() != ring.Standard {
+			t.Skip("only supported for ring.Standard")
+		}
+
+		ringQ := params.RingQ()
+		N, q := params.N(), ringQ.Modulus[0]
+
+		sk := kgen.GenSecretKey()
+		s := ringQ.NewPolyLvl(0)
+		ringQ.InvMFormLvl(0, sk.Value.Q, s)
+		ringQ.InvNTTLvl(0, s, s)
+
+		prng, _ := utils.NewPRNG()
+		sampler := ring.NewUniformSampler(prng, ringQ)
+		c1 := sampler.ReadLvlNew(0)
+
+		// extracts the LWE samples of the first coefficients of (b, c1)
+		nbSamples := 4
+		a := make([][]uint64, nbSamples)
+		b := make([]uint64, nbSamples)
+		for i := range a {
+			a[i] = make([]uint64, N)
+			for j := 0; j < N; j++ {
+				if j <= i {
+					a[i][j] = c1.Coeffs[0][i-j]
+				} else {
+					a[i][j] = (q - c1.Coeffs[0][N+i-j]) % q
+				}
+			}
+			b[i] = ring.RandUniform(prng, q, (1<<bits.Len64(q))-1)
+		}
+
+		ct := params.PackShiftedLWE(a, b)
+
+		// dec = c0 + c1*s
+		dec := ringQ.NewPolyLvl(0)
+		ringQ.NTTLvl(0, ct.Value[1], dec)
+		ringQ.MulCoeffsMontgomeryLvl(0, dec, sk.Value.Q, dec)
+		ringQ.InvNTTLvl(0, dec, dec)
+		ringQ.AddLvl(0, dec, ct.Value[0], dec)
+
+		for i := range a {
+			want := b[i]
+			for j := 0; j < N; j++ {
+				want = ring.CRed(want+ring.BRed(a[i][j], s.Coeffs[0][j], q, ringQ.BredParams[0]), q)
+			}
+			require.Equal(t, want, dec.Coeffs[0][i])
+		}
+
+		a[1][0]++
+		require.Panics(t, func() { params.PackShiftedLWE(a, b) })
+
+		// arbitrary LWE samples are mapped one by one
+		for i := range a {
+			for j := range a[i] {
+				a[i][j] = ring.RandUniform(prng, q, (1<<bits.Len64(q))-1)
+			}
+
+			ct := params.LWEToRLWE(a[i], b[i])
+
+			ringQ.NTTLvl(0, ct.Value[1], dec)
+			ringQ.MulCoeffsMontgomeryLvl(0, dec, sk.Value.Q, dec)
+			ringQ.InvNTTLvl(0, dec, dec)
+			ringQ.AddLvl(0, dec, ct.Value[0], dec)
+
+			want := b[i]
+			for j := 0; j < N; j++ {
+				want = ring.CRed(want+ring.BRed(a[i][j], s.Coeffs[0][j], q, ringQ.BredParams[0]), q)
+			}
+			require.Equal(t, want, dec.Coeffs[0][0])
+		}
+	})
+
+	t.Run(testString(params, "ExtractLWE"), func(t *testing.T) {
+
+		if params.RingType() != ring.Standard {
+			t.Skip("only supported for ring.Standard")
+		}
+
+		ringQ := params.RingQ()
+		level := params.MaxLevel()
+
+		sk := kgen.GenSecretKey()
+		s := ringQ.NewPolyLvl(level)
+		ringQ.InvMFormLvl(level, sk.Value.Q, s)
+		ringQ.InvNTTLvl(level, s, s)
+
+		prng, _ := utils.NewPRNG()
+		plaintext := NewPlaintext(params, level)
+		ring.NewUniformSampler(prng, ringQ).Read(plaintext.Value)
+
+		for _, isNTT := range []bool{false, true} {
+
+			ct := NewCiphertext(params, 1, level)
+			if isNTT {
+				ct = NewCiphertextNTT(params, 1, level)
+			}
+			NewEncryptor(params, sk).Encrypt(plaintext, ct)
+
+			// dec = c0 + c1*s in the coefficient domain
+			dec := NewPlaintext(params, level)
+			NewDecryptor(params, sk).Decrypt(ct, dec)
+
+			for _, k := range []int{0, 1, params.N() - 1} {
+
+				lwe := ct.ExtractLWE(params, k)
+				require.Equal(t, level, lwe.Level())
+
+				for i, qi := range ringQ.Modulus[:level+1] {
+					want := lwe.B[i]
+					for j := 0; j < params.N(); j++ {
+						want = ring.CRed(want+ring.BRed(lwe.A[i][j], s.Coeffs[i][j], qi, ringQ.BredParams[i]), qi)
+					}
+					require.Equal(t, dec.Value.Coeffs[i][k], want)
+				}
+			}
+		}
+
+		require.Panics(t, func() { NewCiphertext(params, 1, level).ExtractLWE(params, params.N()) })
+	})
+}
+
 func testMarshaller(kgen KeyGenerator, t *testing.T) {
 
 	params := kgen.(*keyGenerator).params
@@ -603,6 +1876,92 @@ func testMarshaller(kgen KeyGenerator, t *testing.T) {
 		assert.Equal(t, params.RingQ(), p.RingQ())
 	})
 
+	t.Run("Marshaller/Parameters/ErrorDistribution", func(t *testing.T) {
+		paramsDist, err := NewParametersFromLiteral(ParametersLiteral{
+			LogN:              params.LogN(),
+			Q:                 params.Q(),
+			P:                 params.P(),
+			Sigma:             params.Sigma(),
+			RingType:          params.RingType(),
+			ErrorDistribution: CenteredBinomial,
+		})
+		require.NoError(t, err)
+		require.False(t, params.Equals(paramsDist))
+
+		bytes, err := paramsDist.MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, paramsDist.MarshalBinarySize(), len(bytes))
+		var p Parameters
+		require.NoError(t, p.UnmarshalBinary(bytes))
+		require.Equal(t, CenteredBinomial, p.ErrorDistribution())
+		require.True(t, paramsDist.Equals(p))
+
+		data, err := json.Marshal(paramsDist)
+		require.NoError(t, err)
+		var pJSON Parameters
+		require.NoError(t, json.Unmarshal(data, &pJSON))
+		require.True(t, paramsDist.Equals(pJSON))
+
+		_, err = NewParametersFromLiteral(ParametersLiteral{LogN: params.LogN(), Q: params.Q(), ErrorDistribution: ErrorDistribution(255)})
+		require.Error(t, err)
+	})
+
+	t.Run("Marshaller/Parameters/SecurityLevel", func(t *testing.T) {
+		for _, secLevel := range []int{0, 128, 192, 256} {
+			paramsSec, err := NewParametersFromLiteral(ParametersLiteral{
+				LogN:          params.LogN(),
+				Q:             params.Q(),
+				P:             params.P(),
+				SecurityLevel: secLevel,
+			})
+			require.NoError(t, err)
+			require.Equal(t, secLevel, paramsSec.SecurityLevel())
+
+			bytes, err := paramsSec.MarshalBinary()
+			require.NoError(t, err)
+			require.Equal(t, paramsSec.MarshalBinarySize(), len(bytes))
+			var p Parameters
+			require.NoError(t, p.UnmarshalBinary(bytes))
+			require.Equal(t, secLevel, p.SecurityLevel())
+			require.True(t, paramsSec.Equals(p))
+
+			data, err := json.Marshal(paramsSec)
+			require.NoError(t, err)
+			var pJSON Parameters
+			require.NoError(t, json.Unmarshal(data, &pJSON))
+			require.True(t, paramsSec.Equals(pJSON))
+		}
+
+		_, err := NewParametersFromLiteral(ParametersLiteral{LogN: params.LogN(), Q: params.Q(), P: params.P(), SecurityLevel: 100})
+		require.Error(t, err)
+	})
+
+	t.Run("Marshaller/Parameters/GaussianTailFactor", func(t *testing.T) {
+		paramsTail, err := NewParametersFromLiteral(ParametersLiteral{LogN: params.LogN(), Q: params.Q(), P: params.P(), GaussianTailFactor: 4.5})
+		require.NoError(t, err)
+		require.Equal(t, 4.5, paramsTail.GaussianTailFactor())
+		require.Equal(t, DefaultGaussianTailFactor, params.GaussianTailFactor())
+		require.False(t, paramsTail.Equals(params))
+
+		bytes, err := paramsTail.MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, paramsTail.MarshalBinarySize(), len(bytes))
+		var p Parameters
+		require.NoError(t, p.UnmarshalBinary(bytes))
+		require.True(t, paramsTail.Equals(p))
+
+		data, err := json.Marshal(paramsTail)
+		require.NoError(t, err)
+		var pJSON Parameters
+		require.NoError(t, json.Unmarshal(data, &pJSON))
+		require.True(t, paramsTail.Equals(pJSON))
+
+		for _, factor := range []float64{0.5, -1, math.NaN(), math.Inf(1)} {
+			_, err := NewParametersFromLiteral(ParametersLiteral{LogN: params.LogN(), Q: params.Q(), P: params.P(), GaussianTailFactor: factor})
+			require.Error(t, err)
+		}
+	})
+
 	t.Run("Marshaller/Parameters/JSON", func(t *testing.T) {
 		// checks that parameters can be marshalled without error
 		data, err := json.Marshal(params)
@@ -626,6 +1985,13 @@ func testMarshaller(kgen KeyGenerator, t *testing.T) {
 
 				marshalledCiphertext, err := ciphertextWant.MarshalBinary()
 				require.NoError(t, err)
+				require.Equal(t, ciphertextWant.MarshalSize(), len(marshalledCiphertext))
+				require.Equal(t, 1+(degree+1)*(4+8*params.N()*(params.MaxLevel()+1)), ciphertextWant.MarshalSize())
+
+				ciphertextLvl0 := NewCiphertextRandom(prng, params, degree, 0)
+				marshalledLvl0, err := ciphertextLvl0.MarshalBinary()
+				require.NoError(t, err)
+				require.Equal(t, ciphertextLvl0.MarshalSize(), len(marshalledLvl0))
 
 				ciphertextTest := new(Ciphertext)
 				require.NoError(t, ciphertextTest.UnmarshalBinary(marshalledCiphertext))
@@ -640,10 +2006,141 @@ func testMarshaller(kgen KeyGenerator, t *testing.T) {
 		}
 	})
 
+	t.Run(testString(params, "Marshaller/Ciphertexts"), func(t *testing.T) {
+
+		prng, _ := utils.NewPRNG()
+
+		ciphertextsWant := make([]*Ciphertext, 8)
+		var individualLen int
+		for i := range ciphertextsWant {
+			ciphertextsWant[i] = NewCiphertextRandom(prng, params, 1, params.MaxLevel())
+			individualLen += ciphertextsWant[i].GetDataLen(true)
+		}
+
+		data, err := MarshalCiphertexts(params, ciphertextsWant)
+		require.NoError(t, err)
+		require.Less(t, len(data), individualLen)
+
+		ciphertextsTest, err := UnmarshalCiphertexts(params, data)
+		require.NoError(t, err)
+		require.Len(t, ciphertextsTest, len(ciphertextsWant))
+
+		for i := range ciphertextsWant {
+			require.Equal(t, ciphertextsWant[i].Degree(), ciphertextsTest[i].Degree())
+			require.Equal(t, ciphertextsWant[i].Level(), ciphertextsTest[i].Level())
+			for j := range ciphertextsWant[i].Value {
+				require.True(t, params.RingQ().EqualLvl(ciphertextsWant[i].Level(), ciphertextsWant[i].Value[j], ciphertextsTest[i].Value[j]))
+			}
+		}
+
+		// truncated data and headers
+		for _, n := range []int{len(data) - 1, ciphertextsHeaderLen, ciphertextsHeaderLen - 1, 13, 1, 0} {
+			_, err = UnmarshalCiphertexts(params, data[:n])
+			require.Error(t, err)
+		}
+
+		// malformed headers
+		malformed := map[string]func(header []byte){
+			"logN overflow":   func(header []byte) { header[0] = 61 },
+			"logN":            func(header []byte) { header[0]++ },
+			"no moduli":       func(header []byte) { header[1] = 0 },
+			"too many moduli": func(header []byte) { header[1] = uint8(params.QCount() + 1) },
+			"size":            func(header []byte) { header[2] = 0 },
+			"domain":          func(header []byte) { header[3] = 2 },
+			"count":           func(header []byte) { binary.BigEndian.PutUint64(header[5:13], 1<<61) },
+			"fingerprint":     func(header []byte) { header[13] ^= 1 },
+		}
+
+		for name, corrupt := range malformed {
+			// the header alone with a count of one, so that a header overflowing the length check is caught
+			header := make([]byte, ciphertextsHeaderLen)
+			copy(header, data)
+			binary.BigEndian.PutUint64(header[5:13], 1)
+			corrupt(header)
+			_, err = UnmarshalCiphertexts(params, header)
+			require.Error(t, err, name)
+
+			corrupted := append([]byte{}, data...)
+			corrupt(corrupted)
+			_, err = UnmarshalCiphertexts(params, corrupted)
+			require.Error(t, err, name)
+		}
+
+		// different parameters
+		paramsOther, err := NewParametersFromLiteral(ParametersLiteral{LogN: params.LogN(), Q: params.Q(), P: params.P(), H: params.HammingWeight() + 1, Sigma: params.Sigma(), RingType: params.RingType()})
+		require.NoError(t, err)
+		_, err = UnmarshalCiphertexts(paramsOther, data)
+		require.Error(t, err)
+
+		_, err = MarshalCiphertexts(params, append(ciphertextsWant, NewCiphertextRandom(prng, params, 2, params.MaxLevel())))
+		require.Error(t, err)
+
+		if params.MaxLevel() > 0 {
+			_, err = MarshalCiphertexts(params, append(ciphertextsWant, NewCiphertextRandom(prng, params, 1, params.MaxLevel()-1)))
+			require.Error(t, err)
+		}
+	})
+
+	t.Run(testString(params, "Marshaller/CiphertextBuilder"), func(t *testing.T) {
+
+		prng, _ := utils.NewPRNG()
+
+		ciphertextWant := NewCiphertextRandom(prng, params, 2, params.MaxLevel())
+		for i := range ciphertextWant.Value {
+			ciphertextWant.Value[i].IsNTT = true
+		}
+
+		data := make([][]byte, len(ciphertextWant.Value))
+		for i := range ciphertextWant.Value {
+			var err error
+			data[i], err = ciphertextWant.Value[i].MarshalBinary()
+			require.NoError(t, err)
+		}
+
+		builder := NewCiphertextBuilder(ciphertextWant.Degree())
+		for _, i := range []int{2, 0} {
+			require.NoError(t, builder.SetComponent(i, data[i]))
+		}
+
+		_, err := builder.Build()
+		require.Error(t, err)
+
+		require.NoError(t, builder.SetComponent(1, data[1]))
+		ciphertextTest, err := builder.Build()
+		require.NoError(t, err)
+		require.Equal(t, ciphertextWant.Degree(), ciphertextTest.Degree())
+		require.Equal(t, ciphertextWant.Level(), ciphertextTest.Level())
+		for i := range ciphertextWant.Value {
+			require.True(t, ciphertextTest.Value[i].IsNTT)
+			require.True(t, params.RingQ().EqualLvl(ciphertextWant.Level(), ciphertextWant.Value[i], ciphertextTest.Value[i]))
+		}
+
+		require.Error(t, builder.SetComponent(3, data[0]))
+		require.Error(t, builder.SetComponent(0, data[0][:2]))
+
+		// mismatching domain
+		ciphertextWant.Value[1].IsNTT = false
+		data[1], err = ciphertextWant.Value[1].MarshalBinary()
+		require.NoError(t, err)
+		require.NoError(t, builder.SetComponent(1, data[1]))
+		_, err = builder.Build()
+		require.Error(t, err)
+
+		// mismatching level
+		if params.MaxLevel() > 0 {
+			data[1], err = NewCiphertextRandom(prng, params, 0, 0).Value[0].MarshalBinary()
+			require.NoError(t, err)
+			require.NoError(t, builder.SetComponent(1, data[1]))
+			_, err = builder.Build()
+			require.Error(t, err)
+		}
+	})
+
 	t.Run(testString(params, "Marshaller/Sk"), func(t *testing.T) {
 
 		marshalledSk, err := sk.MarshalBinary()
 		require.NoError(t, err)
+		require.Equal(t, sk.MarshalSize(), len(marshalledSk))
 
 		skTest := new(SecretKey)
 		err = skTest.UnmarshalBinary(marshalledSk)
@@ -656,6 +2153,7 @@ func testMarshaller(kgen KeyGenerator, t *testing.T) {
 
 		marshalledPk, err := pk.MarshalBinary()
 		require.NoError(t, err)
+		require.Equal(t, pk.MarshalSize(), len(marshalledPk))
 
 		pkTest := new(PublicKey)
 		err = pkTest.UnmarshalBinary(marshalledPk)
@@ -2,7 +2,9 @@ package utils
 
 import (
 	"crypto/rand"
+	"encoding"
 	"errors"
+	"fmt"
 
 	"golang.org/x/crypto/blake2b"
 )
@@ -14,12 +16,20 @@ type PRNG interface {
 	SetClock(sum []byte, n uint64) error
 }
 
+// Forker is an interface for PRNGs that can derive independent PRNGs from their key with domain separation,
+// such as KeyedPRNG. It is kept separate from PRNG so that implementations of PRNG are not required to support it.
+type Forker interface {
+	Fork(label []byte) PRNG
+}
+
 // KeyedPRNG is a structure storing the parameters used to securely and deterministically generate shared
 // sequences of random bytes among different parties using the hash function blake2b. Backward sequence
 // security (given the digest i, compute the digest i-1) is ensured by default, however forward sequence
 // security (given the digest i, compute the digest i+1) is only ensured if the KeyedPRNG is keyed.
 type KeyedPRNG struct {
 	clock uint64
+	read  uint64
+	key   []byte
 	xof   blake2b.XOF
 }
 
@@ -30,6 +40,7 @@ func NewKeyedPRNG(key []byte) (*KeyedPRNG, error) {
 	var err error
 	prng := new(KeyedPRNG)
 	prng.clock = 0
+	prng.key = append([]byte{}, key...)
 	prng.xof, err = blake2b.NewXOF(blake2b.OutputLengthUnknown, key)
 	return prng, err
 }
@@ -43,6 +54,7 @@ func NewPRNG() (*KeyedPRNG, error) {
 	if _, err := rand.Read(randomBytes); err != nil {
 		panic("crypto rand error")
 	}
+	prng.key = randomBytes
 	prng.xof, err = blake2b.NewXOF(blake2b.OutputLengthUnknown, randomBytes)
 	return prng, err
 }
@@ -58,6 +70,7 @@ func (prng *KeyedPRNG) Clock(sum []byte) {
 		panic(err)
 	}
 	prng.clock++
+	prng.read += uint64(len(sum))
 }
 
 // SetClock sets the clock cycle of the KeyedPRNG to a given number by calling Clock until
@@ -72,6 +85,250 @@ func (prng *KeyedPRNG) SetClock(sum []byte, n uint64) error {
 			panic(err)
 		}
 		prng.clock++
+		prng.read += uint64(len(sum))
 	}
 	return nil
 }
+
+// MarshalBinary encodes the full state of the KeyedPRNG (its key, its clock cycle and the number of bytes
+// read so far) on a slice of bytes. The returned state contains the key of the KeyedPRNG and must therefore
+// be kept as secret as the key itself.
+func (prng *KeyedPRNG) MarshalBinary() (data []byte, err error) {
+	buff := NewBuffer(make([]byte, 0, 24+len(prng.key)))
+	buff.WriteUint64(prng.clock)
+	buff.WriteUint64(prng.read)
+	buff.WriteUint64(uint64(len(prng.key)))
+	buff.WriteUint8Slice(prng.key)
+	return buff.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a state encoded by MarshalBinary on the target KeyedPRNG, which then resumes
+// the exact same sequence of random bytes as the KeyedPRNG the state was taken from.
+// Since the blake2b XOF does not expose its internal state, the state is recovered by re-reading and
+// discarding the bytes already read from the stream: the cost of this method is therefore linear in the
+// number of bytes read by the KeyedPRNG before MarshalBinary was called.
+// The target KeyedPRNG is left unchanged if an error is returned.
+func (prng *KeyedPRNG) UnmarshalBinary(data []byte) (err error) {
+
+	if len(data) < 24 {
+		return errors.New("cannot UnmarshalBinary: data is too short")
+	}
+
+	buff := NewBuffer(data)
+	clock := buff.ReadUint64()
+	read := buff.ReadUint64()
+
+	if keyLen := buff.ReadUint64(); keyLen != uint64(len(data)-24) {
+		return errors.New("cannot UnmarshalBinary: invalid key length")
+	}
+
+	key := make([]byte, len(data)-24)
+	buff.ReadUint8Slice(key)
+
+	xof, err := blake2b.NewXOF(blake2b.OutputLengthUnknown, key)
+	if err != nil {
+		return err
+	}
+
+	// The stream of the XOF does not depend on how it is read, so its state is
+	// recovered by discarding the bytes that were already read.
+	discard := make([]byte, 1024)
+	for n := read; n > 0; {
+		m := n
+		if m > uint64(len(discard)) {
+			m = uint64(len(discard))
+		}
+		if _, err = xof.Read(discard[:m]); err != nil {
+			return err
+		}
+		n -= m
+	}
+
+	prng.clock, prng.read, prng.key, prng.xof = clock, read, key, xof
+
+	return nil
+}
+
+// Fork returns a new KeyedPRNG keyed with blake2b-512 keyed by the key of the receiver and applied on label.
+// The returned KeyedPRNG only depends on the key of the receiver and on label (and not on its clock): forks
+// with the same label are identical and forks with different labels produce independent sequences, which are
+// also independent of the sequence of the receiver.
+func (prng *KeyedPRNG) Fork(label []byte) PRNG {
+	kdf, err := blake2b.New512(prng.key)
+	if err != nil {
+		panic(err)
+	}
+
+	kdf.Write(label)
+
+	fork, err := NewKeyedPRNG(kdf.Sum(nil))
+	if err != nil {
+		panic(err)
+	}
+
+	return fork
+}
+
+// CountingPRNG is a PRNG wrapping another PRNG and counting the number of bytes read from it.
+type CountingPRNG struct {
+	PRNG
+	read uint64
+}
+
+// NewCountingPRNG creates a new CountingPRNG wrapping prng.
+func NewCountingPRNG(prng PRNG) *CountingPRNG {
+	return &CountingPRNG{PRNG: prng}
+}
+
+// Clock reads bytes from the wrapped PRNG on sum and counts them.
+func (prng *CountingPRNG) Clock(sum []byte) {
+	prng.PRNG.Clock(sum)
+	prng.read += uint64(len(sum))
+}
+
+// SetClock sets the clock cycle of the wrapped PRNG to n and counts the bytes read to reach it.
+func (prng *CountingPRNG) SetClock(sum []byte, n uint64) (err error) {
+	clock := prng.PRNG.GetClock()
+	if err = prng.PRNG.SetClock(sum, n); err != nil {
+		return
+	}
+	prng.read += (n - clock) * uint64(len(sum))
+	return
+}
+
+// Fork returns the fork of the wrapped PRNG with the given label. The bytes read from the fork are not counted.
+// The method panics if the wrapped PRNG does not implement Forker.
+func (prng *CountingPRNG) Fork(label []byte) PRNG {
+	forker, ok := prng.PRNG.(Forker)
+	if !ok {
+		panic(fmt.Sprintf("cannot Fork: the wrapped PRNG of type %T does not implement Forker", prng.PRNG))
+	}
+	return forker.Fork(label)
+}
+
+// BytesRead returns the number of bytes read from the wrapped PRNG through the CountingPRNG.
+func (prng *CountingPRNG) BytesRead() uint64 {
+	return prng.read
+}
+
+// MarshalBinary encodes the number of bytes read and the state of the wrapped PRNG, which must
+// implement encoding.BinaryMarshaler, on a slice of bytes.
+func (prng *CountingPRNG) MarshalBinary() (data []byte, err error) {
+
+	marshaler, ok := prng.PRNG.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, errors.New("cannot MarshalBinary: the wrapped PRNG does not expose its state")
+	}
+
+	var state []byte
+	if state, err = marshaler.MarshalBinary(); err != nil {
+		return nil, err
+	}
+
+	buff := NewBuffer(make([]byte, 0, 8+len(state)))
+	buff.WriteUint64(prng.read)
+	buff.WriteUint8Slice(state)
+
+	return buff.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a state encoded by MarshalBinary on the target CountingPRNG.
+// The wrapped PRNG must implement encoding.BinaryUnmarshaler.
+func (prng *CountingPRNG) UnmarshalBinary(data []byte) (err error) {
+
+	unmarshaler, ok := prng.PRNG.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return errors.New("cannot UnmarshalBinary: the wrapped PRNG does not expose its state")
+	}
+
+	if len(data) < 8 {
+		return errors.New("cannot UnmarshalBinary: data is too short")
+	}
+
+	if err = unmarshaler.UnmarshalBinary(data[8:]); err != nil {
+		return
+	}
+
+	prng.read = NewBuffer(data).ReadUint64()
+
+	return
+}
+
+// combinedPRNG is a PRNG whose stream is the XOR of the streams of several PRNGs.
+type combinedPRNG struct {
+	prngs []PRNG
+	buff  []byte
+	clock uint64
+}
+
+// CombinePRNGs returns a PRNG whose output is the XOR of the outputs of the given PRNGs, e.g. one PRNG per
+// party keyed with a seed contributed by this party. The combined stream is uniform and unpredictable as long
+// as at least one of the PRNGs is, and independent of the others, so that no single party controls it.
+// The returned PRNG reads from the given PRNGs, which must therefore not be used elsewhere, and its forks
+// are the combinations of the forks of the given PRNGs. It panics if no PRNG is given or if one of them is nil.
+func CombinePRNGs(prngs ...PRNG) PRNG {
+
+	if len(prngs) == 0 {
+		panic("cannot CombinePRNGs: no PRNG to combine")
+	}
+
+	for i, prng := range prngs {
+		if prng == nil {
+			panic(fmt.Errorf("cannot CombinePRNGs: prngs[%d] is nil", i))
+		}
+	}
+
+	return &combinedPRNG{prngs: append([]PRNG{}, prngs...)}
+}
+
+// GetClock returns the number of calls to Clock on the combined PRNG.
+func (prng *combinedPRNG) GetClock() uint64 {
+	return prng.clock
+}
+
+// Clock reads len(sum) bytes from each combined PRNG and writes their XOR on sum.
+func (prng *combinedPRNG) Clock(sum []byte) {
+
+	if len(prng.buff) < len(sum) {
+		prng.buff = make([]byte, len(sum))
+	}
+	buff := prng.buff[:len(sum)]
+
+	prng.prngs[0].Clock(sum)
+
+	for _, p := range prng.prngs[1:] {
+		p.Clock(buff)
+		for i := range sum {
+			sum[i] ^= buff[i]
+		}
+	}
+
+	prng.clock++
+}
+
+// SetClock sets the clock cycle of the combined PRNG to a given number by calling Clock until
+// the clock cycle reaches the desired number. Returns an error if the target clock
+// cycle is smaller than the current clock cycle.
+func (prng *combinedPRNG) SetClock(sum []byte, n uint64) error {
+	if prng.clock > n {
+		return errors.New("error: cannot set combined PRNG clock to a previous state")
+	}
+	for prng.clock != n {
+		prng.Clock(sum)
+	}
+	return nil
+}
+
+// Fork returns the combination of the forks of the combined PRNGs with the given label.
+// The method panics if one of the combined PRNGs does not implement Forker.
+func (prng *combinedPRNG) Fork(label []byte) PRNG {
+	forks := make([]PRNG, len(prng.prngs))
+	for i, p := range prng.prngs {
+		forker, ok := p.(Forker)
+		if !ok {
+			panic(fmt.Sprintf("cannot Fork: the combined PRNG %d of type %T does not implement Forker", i, p))
+		}
+		forks[i] = forker.Fork(label)
+	}
+	return CombinePRNGs(forks...)
+}
@@ -32,4 +32,134 @@ func Test_PRNG(t *testing.T) {
 		require.Equal(t, sum0, sum1)
 	})
 
+	t.Run("PRNG/Fork", func(t *testing.T) {
+
+		key := []byte{0x49, 0x0a, 0x42, 0x3d, 0x97, 0x9d, 0xc1, 0x07, 0xa1, 0xd7, 0xe9, 0x7b, 0x3b, 0xce, 0xa1, 0xdb}
+
+		Ha, _ := NewKeyedPRNG(key)
+		Hb, _ := NewKeyedPRNG(key)
+
+		sumParent := make([]byte, 64)
+		Hb.Clock(sumParent)
+
+		// forks only depend on the key of the parent and on the label
+		sum0, sum1, sum2 := make([]byte, 64), make([]byte, 64), make([]byte, 64)
+		Ha.Fork([]byte("encryption")).Clock(sum0)
+		Hb.Fork([]byte("encryption")).Clock(sum1)
+		Ha.Fork([]byte("crp")).Clock(sum2)
+
+		require.Equal(t, sum0, sum1)
+		require.NotEqual(t, sum0, sum2)
+		require.NotEqual(t, sum0, sumParent)
+
+		// forking does not modify the stream of the parent
+		sumParentTest := make([]byte, 64)
+		Ha.Clock(sumParentTest)
+		require.Equal(t, sumParent, sumParentTest)
+	})
+
+	t.Run("PRNG/MarshalBinary", func(t *testing.T) {
+
+		Ha, _ := NewPRNG()
+		Ha.Clock(make([]byte, 1500))
+		Ha.Clock(make([]byte, 17))
+
+		data, err := Ha.MarshalBinary()
+		require.NoError(t, err)
+
+		Hb := new(KeyedPRNG)
+		require.NoError(t, Hb.UnmarshalBinary(data))
+		require.Equal(t, Ha.GetClock(), Hb.GetClock())
+
+		sum0, sum1 := make([]byte, 256), make([]byte, 256)
+		Ha.Clock(sum0)
+		Hb.Clock(sum1)
+		require.Equal(t, sum0, sum1)
+
+		require.Error(t, Hb.UnmarshalBinary(data[:len(data)-1]))
+	})
+
+	t.Run("PRNG/CountingPRNG", func(t *testing.T) {
+
+		key := []byte{0x49, 0x0a, 0x42, 0x3d, 0x97, 0x9d, 0xc1, 0x07, 0xa1, 0xd7, 0xe9, 0x7b, 0x3b, 0xce, 0xa1, 0xdb}
+
+		Ha, _ := NewKeyedPRNG(key)
+		Hb, _ := NewKeyedPRNG(key)
+		Hc := NewCountingPRNG(Hb)
+
+		// counting does not modify the stream
+		sum0, sum1 := make([]byte, 64), make([]byte, 64)
+		Ha.Clock(sum0)
+		Hc.Clock(sum1)
+		require.Equal(t, sum0, sum1)
+		require.Equal(t, uint64(64), Hc.BytesRead())
+
+		require.NoError(t, Hc.SetClock(make([]byte, 16), 4))
+		require.Equal(t, uint64(64+3*16), Hc.BytesRead())
+		require.Equal(t, uint64(4), Hc.GetClock())
+
+		data, err := Hc.MarshalBinary()
+		require.NoError(t, err)
+		Hd := NewCountingPRNG(new(KeyedPRNG))
+		require.NoError(t, Hd.UnmarshalBinary(data))
+		require.Equal(t, Hc.BytesRead(), Hd.BytesRead())
+		Hc.Clock(sum0)
+		Hd.Clock(sum1)
+		require.Equal(t, sum0, sum1)
+	})
+
+	t.Run("PRNG/CombinePRNGs", func(t *testing.T) {
+
+		seeds := [][]byte{[]byte("party 0"), []byte("party 1"), []byte("party 2")}
+
+		combine := func(seeds [][]byte) PRNG {
+			prngs := make([]PRNG, len(seeds))
+			for i, seed := range seeds {
+				prngs[i], _ = NewKeyedPRNG(seed)
+			}
+			return CombinePRNGs(prngs...)
+		}
+
+		// the combined stream is deterministic and is the XOR of the streams
+		sum0, sum1, want := make([]byte, 64), make([]byte, 64), make([]byte, 64)
+		combine(seeds).Clock(sum0)
+		combine(seeds).Clock(sum1)
+		require.Equal(t, sum0, sum1)
+
+		for _, seed := range seeds {
+			prng, _ := NewKeyedPRNG(seed)
+			prng.Clock(sum1)
+			for i := range want {
+				want[i] ^= sum1[i]
+			}
+		}
+		require.Equal(t, want, sum0)
+
+		// and differs if any of the inputs changes
+		for i := range seeds {
+			other := append([][]byte{}, seeds...)
+			other[i] = []byte("another party")
+			combine(other).Clock(sum1)
+			require.NotEqual(t, sum0, sum1)
+		}
+
+		// SetClock and Fork are consistent with the combined PRNGs
+		Ha, Hb := combine(seeds), combine(seeds)
+		require.NoError(t, Ha.SetClock(make([]byte, 64), 3))
+		for i := 0; i < 3; i++ {
+			Hb.Clock(sum1)
+		}
+		require.Equal(t, uint64(3), Ha.GetClock())
+		Ha.Clock(sum0)
+		Hb.Clock(sum1)
+		require.Equal(t, sum0, sum1)
+		require.Error(t, Ha.SetClock(sum0, 1))
+
+		Ha.(Forker).Fork([]byte("crp")).Clock(sum0)
+		combine(seeds).(Forker).Fork([]byte("crp")).Clock(sum1)
+		require.Equal(t, sum0, sum1)
+
+		require.Panics(t, func() { CombinePRNGs() })
+		require.Panics(t, func() { CombinePRNGs(Ha, nil) })
+	})
 }